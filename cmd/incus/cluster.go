@@ -1143,6 +1143,10 @@ func (c *cmdClusterListTokens) tokenColumnData(token *api.ClusterMemberJoinToken
 }
 
 func (c *cmdClusterListTokens) expiresAtColumnData(token *api.ClusterMemberJoinToken) string {
+	if token.ExpiresAt.IsZero() {
+		return " "
+	}
+
 	return token.ExpiresAt.Local().Format(dateLayout)
 }
 
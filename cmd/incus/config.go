@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"strings"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/lxc/incus/v6/internal/instance"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/termios"
+	"github.com/lxc/incus/v6/shared/util"
 )
 
 type cmdConfig struct {
@@ -686,6 +688,44 @@ func (c *cmdConfigSet) Run(cmd *cobra.Command, args []string) error {
 					writable.Config[k] = v
 				}
 			}
+
+			// Warn about deprecated keys.
+			if cmd.Name() != "unset" {
+				for k := range keys {
+					newKey, deprecated := instance.IsDeprecatedConfigKey(k)
+					if deprecated {
+						fmt.Fprintf(os.Stderr, i18n.G("The %q configuration key is deprecated, use %q instead")+"\n", k, newKey)
+					}
+				}
+			}
+
+			// Warn when enabling stateful migration on a VM that can't be migrated statefully.
+			if cmd.Name() != "unset" && inst.Type == string(api.InstanceTypeVM) && util.IsTrue(keys["migration.stateful"]) {
+				config := maps.Clone(inst.ExpandedConfig)
+				maps.Copy(config, keys)
+
+				// Fill in the NIC type of devices using a managed network.
+				devices := map[string]map[string]string{}
+				for name, dev := range inst.ExpandedDevices {
+					if dev["type"] == "nic" && dev["network"] != "" {
+						network, _, err := resource.server.GetNetwork(dev["network"])
+						if err == nil {
+							dev = maps.Clone(dev)
+							dev["nictype"] = network.Type
+							if network.Type == "bridge" {
+								dev["nictype"] = "bridged"
+							}
+						}
+					}
+
+					devices[name] = dev
+				}
+
+				ok, reason := instance.CanMigrateStateful(config, devices)
+				if !ok {
+					fmt.Fprintf(os.Stderr, i18n.G("Stateful migration won't be possible: %s")+"\n", reason)
+				}
+			}
 		}
 
 		op, err := resource.server.UpdateInstance(resource.name, writable, etag)
@@ -693,7 +733,22 @@ func (c *cmdConfigSet) Run(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
-		return op.Wait()
+		err = op.Wait()
+		if err != nil {
+			return err
+		}
+
+		// Warn about changes which only apply once the instance is restarted.
+		if !c.flagIsProperty && inst.StatusCode == api.Running {
+			for k := range keys {
+				restart, _ := instance.ConfigKeyRequiresRestart(k, api.InstanceType(inst.Type))
+				if restart {
+					fmt.Fprintf(os.Stderr, i18n.G("The change to %q will only apply once the instance is restarted")+"\n", k)
+				}
+			}
+		}
+
+		return nil
 	}
 
 	// Targeting
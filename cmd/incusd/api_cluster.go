@@ -1387,7 +1387,11 @@ func clusterNodesPost(d *Daemon, r *http.Request) response.Response {
 		"secret":      joinSecret,
 		"fingerprint": fingerprint,
 		"addresses":   onlineNodeAddresses,
-		"expiresAt":   expiry,
+	}
+
+	// Only add the expiry date when the token should expire.
+	if !expiry.IsZero() {
+		meta["expiresAt"] = expiry
 	}
 
 	resources := map[string][]api.URL{}
@@ -2839,8 +2843,13 @@ func clusterNodeStatePost(d *Daemon, r *http.Request) response.Response {
 					val = evacuateHostShutdownDefaultTimeout
 				}
 
+				clampedVal, clamped := internalInstance.ClampHostShutdownTimeout(int64(val))
+				if clamped {
+					l.Warn("Capping instance shutdown timeout to one hour", logger.Ctx{"requested": timeout})
+				}
+
 				// Start with a clean shutdown.
-				err = inst.Shutdown(time.Duration(val) * time.Second)
+				err = inst.Shutdown(time.Duration(clampedVal) * time.Second)
 				if err != nil {
 					l.Warn("Failed shutting down instance, forcing stop", logger.Ctx{"err": err})
 
@@ -18,6 +18,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	incus "github.com/lxc/incus/v6/client"
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
 	"github.com/lxc/incus/v6/internal/server/cluster"
 	"github.com/lxc/incus/v6/internal/server/db"
 	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
@@ -436,8 +437,13 @@ func restoreClusterMemberFunc(inst instance.Instance, op *operations.Operation,
 			val = evacuateHostShutdownDefaultTimeout
 		}
 
+		clampedVal, clamped := internalInstance.ClampHostShutdownTimeout(int64(val))
+		if clamped {
+			l.Warn("Capping instance shutdown timeout to one hour", logger.Ctx{"requested": timeout})
+		}
+
 		// Attempt a clean stop.
-		stopOp, err := source.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "stop", Force: false, Timeout: val}, "")
+		stopOp, err := source.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "stop", Force: false, Timeout: int(clampedVal)}, "")
 		if err != nil {
 			return fmt.Errorf("Failed to stop instance %q: %w", inst.Name(), err)
 		}
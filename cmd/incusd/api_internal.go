@@ -14,6 +14,7 @@ import (
 	"runtime"
 	runtimeDebug "runtime/debug"
 	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -57,6 +58,7 @@ var apiInternal = []APIEndpoint{
 	internalContainerOnStartCmd,
 	internalContainerOnStopCmd,
 	internalContainerOnStopNSCmd,
+	internalCPUPinningCmd,
 	internalVirtualMachineOnResizeCmd,
 	internalGarbageCollectorCmd,
 	internalImageOptimizeCmd,
@@ -148,6 +150,12 @@ var internalBGPStateCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: internalBGPState, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
 }
 
+var internalCPUPinningCmd = APIEndpoint{
+	Path: "cpu-pinning",
+
+	Get: APIEndpointAction{Handler: internalCPUPinning, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView)},
+}
+
 var internalRebalanceLoadCmd = APIEndpoint{
 	Path: "rebalance",
 
@@ -159,6 +167,12 @@ type internalImageOptimizePost struct {
 	Pool  string    `json:"pool"  yaml:"pool"`
 }
 
+type internalCPUPinningEntry struct {
+	Project  string   `json:"project"  yaml:"project"`
+	Instance string   `json:"instance" yaml:"instance"`
+	CPUs     []string `json:"cpus"     yaml:"cpus"`
+}
+
 type internalWarningCreatePost struct {
 	Location       string `json:"location"         yaml:"location"`
 	Project        string `json:"project"          yaml:"project"`
@@ -1082,6 +1096,33 @@ func internalBGPState(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponse(true, s.BGP.Debug())
 }
 
+// internalCPUPinning returns the CPU pinning the scheduler would apply to the running containers, without applying it.
+func internalCPUPinning(d *Daemon, r *http.Request) response.Response {
+	pinning, err := deviceTaskBalancePlan(d.State())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	result := make([]internalCPUPinningEntry, 0, len(pinning))
+	for inst, cpus := range pinning {
+		result = append(result, internalCPUPinningEntry{
+			Project:  inst.Project().Name,
+			Instance: inst.Name(),
+			CPUs:     cpus,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Project != result[j].Project {
+			return result[i].Project < result[j].Project
+		}
+
+		return result[i].Instance < result[j].Instance
+	})
+
+	return response.SyncResponse(true, result)
+}
+
 func internalRebalanceLoad(d *Daemon, r *http.Request) response.Response {
 	err := autoRebalanceCluster(context.TODO(), d)
 	if err != nil {
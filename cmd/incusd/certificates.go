@@ -667,7 +667,9 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 				return response.InternalError(err)
 			}
 
-			meta["expiresAt"] = expiresAt
+			if !expiresAt.IsZero() {
+				meta["expiresAt"] = expiresAt
+			}
 		}
 
 		op, err := operations.OperationCreate(s, api.ProjectDefaultName, operations.OperationClassToken, operationtype.CertificateAddToken, nil, meta, nil, nil, nil, r)
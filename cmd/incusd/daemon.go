@@ -1627,6 +1627,7 @@ func (d *Daemon) init() error {
 	if !d.os.MockMode {
 		// Start the scheduler
 		go deviceEventListener(d.State)
+		go deviceSchedPinnedForward(d.State)
 
 		prefixPath := os.Getenv("INCUS_DEVMONITOR_DIR")
 		if prefixPath == "" {
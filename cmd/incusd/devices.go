@@ -1,6 +1,7 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
 	"os"
 	"path"
@@ -9,6 +10,8 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unsafe"
 
 	"golang.org/x/sys/unix"
@@ -18,6 +21,8 @@ import (
 	"github.com/lxc/incus/v6/internal/server/device"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/resources"
 	"github.com/lxc/incus/v6/internal/server/state"
 	"github.com/lxc/incus/v6/shared/logger"
@@ -27,6 +32,7 @@ import (
 type deviceTaskCPU struct {
 	id    int64
 	strId string
+	core  int64
 	count *int
 }
 
@@ -36,6 +42,37 @@ func (c deviceTaskCPUs) Len() int           { return len(c) }
 func (c deviceTaskCPUs) Less(i, j int) bool { return *c[i].count < *c[j].count }
 func (c deviceTaskCPUs) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
 
+// coreUsage returns the number of containers using each CPU core.
+func (c deviceTaskCPUs) coreUsage() map[int64]int {
+	coreCount := map[int64]int{}
+	for _, cpu := range c {
+		coreCount[cpu.core] += *cpu.count
+	}
+
+	return coreCount
+}
+
+// sortForPolicy orders the CPUs from the least to the most used, breaking ties according to the CPU policy.
+// The "spread" policy prefers CPUs on the least used cores (as per coreCount) while the "pack" policy prefers
+// the lowest cores.
+func (c deviceTaskCPUs) sortForPolicy(policy string, coreCount map[int64]int) {
+	slices.SortFunc(c, func(a deviceTaskCPU, b deviceTaskCPU) int {
+		if *a.count != *b.count {
+			return cmp.Compare(*a.count, *b.count)
+		}
+
+		if policy != "pack" && coreCount[a.core] != coreCount[b.core] {
+			return cmp.Compare(coreCount[a.core], coreCount[b.core])
+		}
+
+		if a.core != b.core {
+			return cmp.Compare(a.core, b.core)
+		}
+
+		return cmp.Compare(a.id, b.id)
+	})
+}
+
 func deviceNetlinkListener() (chan []string, chan device.USBEvent, chan device.UnixHotplugEvent, error) {
 	NETLINK_KOBJECT_UEVENT := 15
 	UEVENT_BUFFER_SIZE := 2048
@@ -296,8 +333,7 @@ func deviceNetlinkListener() (chan []string, chan device.USBEvent, chan device.U
  */
 func fillFixedInstances(fixedInstances map[int64][]instance.Instance, inst instance.Instance, effectiveCpus []int64, targetCpuPool []int64, targetCpuNum int, loadBalancing bool) {
 	if len(targetCpuPool) < targetCpuNum {
-		diffCount := len(targetCpuPool) - targetCpuNum
-		logger.Warnf("%v CPUs have been required for pinning, but %v CPUs won't be allocated", len(targetCpuPool), -diffCount)
+		logger.Warn("Not enough CPUs available for pinning, capping CPU count", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "requested": targetCpuNum, "available": len(targetCpuPool)})
 		targetCpuNum = len(targetCpuPool)
 	}
 
@@ -362,8 +398,74 @@ func fillFixedInstances(fixedInstances map[int64][]instance.Instance, inst insta
 	}
 }
 
+// deviceCPUPinning is a CPU pinning applied to a container by the scheduler.
+type deviceCPUPinning struct {
+	inst   instance.Instance
+	cpuset string
+}
+
+// deviceSchedPinned channel publishing the CPU pinning applied by each balance.
+var deviceSchedPinned = make(chan deviceCPUPinning, 64)
+
 // deviceTaskBalance is used to balance the CPU load across containers running on a host.
-// It first checks if CGroup support is available and returns if it isn't.
+// It computes the pinning through deviceTaskBalancePlan, applies it through deviceTaskBalanceApply
+// and then publishes the applied pinning through deviceTaskBalancePublish.
+func deviceTaskBalance(s *state.State) {
+	pinning, err := deviceTaskBalancePlan(s)
+	if err != nil {
+		logger.Error("balance: Failed computing CPU pinning", logger.Ctx{"err": err})
+		return
+	}
+
+	deviceTaskBalancePublish(deviceTaskBalanceApply(pinning))
+}
+
+// deviceSchedLastPinned is the cpuset last published for each container, keyed by project and instance name.
+var deviceSchedLastPinned = map[string]string{}
+
+// deviceSchedLastPinnedMu protects deviceSchedLastPinned.
+var deviceSchedLastPinnedMu sync.Mutex
+
+// deviceTaskBalancePublish publishes the applied CPU pinning on deviceSchedPinned.
+// Only the pinning of containers whose cpuset changed since it was last published is sent.
+// Publishing never blocks, the pinning of a container is dropped if the channel is full and retried on the next balance.
+func deviceTaskBalancePublish(pinning map[instance.Instance][]string) {
+	deviceSchedLastPinnedMu.Lock()
+	defer deviceSchedLastPinnedMu.Unlock()
+
+	// Containers missing from the pinning are forgotten so that they get published again when restarted.
+	lastPinned := make(map[string]string, len(pinning))
+	for inst, set := range pinning {
+		key := project.Instance(inst.Project().Name, inst.Name())
+		cpuset := strings.Join(set, ",")
+
+		if deviceSchedLastPinned[key] == cpuset {
+			lastPinned[key] = cpuset
+			continue
+		}
+
+		select {
+		case deviceSchedPinned <- deviceCPUPinning{inst: inst, cpuset: cpuset}:
+			lastPinned[key] = cpuset
+		default:
+			// Channel is full, drop the event
+		}
+	}
+
+	deviceSchedLastPinned = lastPinned
+}
+
+// deviceSchedPinnedForward forwards the CPU pinning published by the scheduler as lifecycle events.
+// Accepts stateFunc which will be called each time it needs a fresh state.State.
+func deviceSchedPinnedForward(stateFunc func() *state.State) {
+	for e := range deviceSchedPinned {
+		s := stateFunc()
+		s.Events.SendLifecycle(e.inst.Project().Name, lifecycle.InstanceCPUPinned.Event(e.inst, map[string]any{"cpuset": e.cpuset}))
+	}
+}
+
+// deviceTaskBalancePlan computes the CPU pinning of the containers running on a host without applying it.
+// It first checks if CGroup support is available and returns an empty plan if it isn't.
 // It then retrieves the effective CPU list (the CPUs that are guaranteed to be online) and isolates any isolated CPUs.
 // After that, it loads all instances of containers running on the node and iterates through them.
 //
@@ -374,15 +476,8 @@ func fillFixedInstances(fixedInstances map[int64][]instance.Instance, inst insta
 // NUMA placement is enabled (`limits.cpu.nodes` is not empty), we apply a similar load-balancing logic to the `fixedInstances` map
 // with a constraint being the number of vCPUs and the CPU pool being the CPUs pinned to a set of NUMA nodes.
 //
-// Next, the function balance the CPU usage by iterating over all the CPUs and dividing the containers into those that
-// are pinned to a specific CPU and those that are load-balanced. For the pinned containers,
-// it adds them to the pinning map with the CPU number it's pinned to.
-// For the load-balanced containers, it sorts the available CPUs based on their usage count and assigns them to containers
-// in ascending order until the required number of CPUs have been assigned.
-// Finally, the pinning map is used to set the new CPU pinning for each container, updating it to the new balanced state.
-//
-// Overall, this function ensures that the CPU resources of the host are utilized effectively amongst all the containers running on it.
-func deviceTaskBalance(s *state.State) {
+// The resulting pinning is then computed by deviceTaskBalanceCompute.
+func deviceTaskBalancePlan(s *state.State) (map[instance.Instance][]string, error) {
 	min := func(x, y int) int {
 		if x < y {
 			return x
@@ -393,14 +488,13 @@ func deviceTaskBalance(s *state.State) {
 
 	// Don't bother running when CGroup support isn't there
 	if !s.OS.CGInfo.Supports(cgroup.CPUSet, nil) {
-		return
+		return map[instance.Instance][]string{}, nil
 	}
 
 	// Get effective cpus list - those are all guaranteed to be online
 	cg, err := cgroup.NewFileReadWriter(1, true)
 	if err != nil {
-		logger.Errorf("Unable to load cgroup writer: %v", err)
-		return
+		return nil, fmt.Errorf("Unable to load cgroup writer: %w", err)
 	}
 
 	effectiveCpus, err := cg.GetEffectiveCpuset()
@@ -408,15 +502,13 @@ func deviceTaskBalance(s *state.State) {
 		// Older kernel - use cpuset.cpus
 		effectiveCpus, err = cg.GetCpuset()
 		if err != nil {
-			logger.Errorf("Error reading host's cpuset.cpus")
-			return
+			return nil, fmt.Errorf("Error reading host's cpuset.cpus: %w", err)
 		}
 	}
 
 	effectiveCpusInt, err := resources.ParseCpuset(effectiveCpus)
 	if err != nil {
-		logger.Errorf("Error parsing effective CPU set")
-		return
+		return nil, fmt.Errorf("Error parsing effective CPU set: %w", err)
 	}
 
 	isolatedCpusInt := resources.GetCPUIsolated()
@@ -432,26 +524,25 @@ func deviceTaskBalance(s *state.State) {
 	effectiveCpus = strings.Join(effectiveCpusSlice, ",")
 	cpus, err := resources.ParseCpuset(effectiveCpus)
 	if err != nil {
-		logger.Error("Error parsing host's cpu set", logger.Ctx{"cpuset": effectiveCpus, "err": err})
-		return
+		return nil, fmt.Errorf("Error parsing host's cpu set %q: %w", effectiveCpus, err)
 	}
 
 	// Iterate through the instances
 	instances, err := instance.LoadNodeAll(s, instancetype.Container)
 	if err != nil {
-		logger.Error("Problem loading instances list", logger.Ctx{"err": err})
-		return
+		return nil, fmt.Errorf("Problem loading instances list: %w", err)
 	}
 
 	// Get CPU topology.
 	cpusTopology, err := resources.GetCPU()
 	if err != nil {
-		logger.Errorf("Unable to load system CPUs information: %v", err)
-		return
+		return nil, fmt.Errorf("Unable to load system CPUs information: %w", err)
 	}
 
-	// Build a map of NUMA node to CPU threads.
+	// Build a map of NUMA node to CPU threads and a map of CPU threads to their core.
 	numaNodeToCPU := make(map[int64][]int64)
+	cpuCores := make(map[int64]int64)
+	coreIndex := int64(0)
 	for _, cpu := range cpusTopology.Sockets {
 		for _, core := range cpu.Cores {
 			for _, thread := range core.Threads {
@@ -461,7 +552,10 @@ func deviceTaskBalance(s *state.State) {
 				}
 
 				numaNodeToCPU[int64(thread.NUMANode)] = append(numaNodeToCPU[int64(thread.NUMANode)], thread.ID)
+				cpuCores[thread.ID] = coreIndex
 			}
+
+			coreIndex++
 		}
 	}
 
@@ -485,7 +579,14 @@ func deviceTaskBalance(s *state.State) {
 			}
 
 			for _, numaNode := range numaNodeSet {
-				numaCpus = append(numaCpus, numaNodeToCPU[numaNode]...)
+				for _, numaCPU := range numaNodeToCPU[numaNode] {
+					// Only consider the node's CPUs which are currently online.
+					if !slices.Contains(cpus, numaCPU) {
+						continue
+					}
+
+					numaCpus = append(numaCpus, numaCPU)
+				}
 			}
 
 			for _, numaCPU := range numaCpus {
@@ -525,7 +626,7 @@ func deviceTaskBalance(s *state.State) {
 			// Pinned
 			containerCpus, err := resources.ParseCpuset(cpulimit)
 			if err != nil {
-				return
+				return nil, fmt.Errorf("Error parsing CPU limit %q of instance %q in project %q: %w", cpulimit, c.Name(), c.Project().Name, err)
 			}
 
 			if conf["limits.cpu"] != "" && len(numaCpus) > 0 {
@@ -536,7 +637,16 @@ func deviceTaskBalance(s *state.State) {
 		}
 	}
 
-	// Balance things
+	return deviceTaskBalanceCompute(cpus, cpuCores, fixedInstances, balancedInstances), nil
+}
+
+// deviceTaskBalanceCompute balances the CPU usage by iterating over all the CPUs and dividing the containers into those that
+// are pinned to a specific CPU and those that are load-balanced. For the pinned containers,
+// it adds them to the pinning map with the CPU number it's pinned to.
+// For the load-balanced containers, it sorts the available CPUs based on their usage count and the container's CPU policy
+// and assigns them to containers in ascending order until the required number of CPUs have been assigned.
+// CPUs pinned by containers with limits.cpu.isolated are left out, unless no other CPU is available.
+func deviceTaskBalanceCompute(cpus []int64, cpuCores map[int64]int64, fixedInstances map[int64][]instance.Instance, balancedInstances map[instance.Instance]int) map[instance.Instance][]string {
 	pinning := map[instance.Instance][]string{}
 	usage := map[int64]deviceTaskCPU{}
 
@@ -544,12 +654,21 @@ func deviceTaskBalance(s *state.State) {
 		cpu := deviceTaskCPU{}
 		cpu.id = id
 		cpu.strId = fmt.Sprintf("%d", id)
+
+		// CPUs with unknown topology are considered to be on a core of their own.
+		core, ok := cpuCores[id]
+		if !ok {
+			core = int64(len(cpuCores)) + id
+		}
+
+		cpu.core = core
 		count := 0
 		cpu.count = &count
 
 		usage[id] = cpu
 	}
 
+	isolated := map[int64]bool{}
 	for cpu, ctns := range fixedInstances {
 		c, ok := usage[cpu]
 		if !ok {
@@ -566,20 +685,38 @@ func deviceTaskBalance(s *state.State) {
 				pinning[ctn] = []string{id}
 			}
 			*c.count += 1
+
+			if deviceTaskIsolated(ctn) {
+				isolated[cpu] = true
+			}
 		}
 	}
 
 	sortedUsage := make(deviceTaskCPUs, 0)
 	for _, value := range usage {
+		if isolated[value.id] {
+			continue
+		}
+
 		sortedUsage = append(sortedUsage, value)
 	}
 
+	// Fall back to all CPUs rather than leaving the balanced containers without any.
+	if len(sortedUsage) == 0 {
+		for _, value := range usage {
+			sortedUsage = append(sortedUsage, value)
+		}
+	}
+
 	for ctn, count := range balancedInstances {
-		sort.Sort(sortedUsage)
-		for _, cpu := range sortedUsage {
-			if count == 0 {
-				break
-			}
+		policy := ctn.ExpandedConfig()["limits.cpu.policy"]
+
+		// Pick the CPUs one at a time as each pick changes the usage of its core.
+		candidates := slices.Clone(sortedUsage)
+		for count > 0 && len(candidates) > 0 {
+			candidates.sortForPolicy(policy, sortedUsage.coreUsage())
+			cpu := candidates[0]
+			candidates = candidates[1:]
 
 			count -= 1
 
@@ -594,14 +731,53 @@ func deviceTaskBalance(s *state.State) {
 		}
 	}
 
-	// Set the new pinning
+	for _, set := range pinning {
+		sort.Strings(set)
+	}
+
+	return pinning
+}
+
+// deviceTaskIsolated returns whether the CPUs a container is pinned to must be kept away from load-balanced containers.
+func deviceTaskIsolated(inst instance.Instance) bool {
+	conf := inst.ExpandedConfig()
+	if !util.IsTrue(conf["limits.cpu.isolated"]) || conf["limits.cpu"] == "" {
+		return false
+	}
+
+	// Only explicitly pinned CPUs are isolated, not a number of load-balanced CPUs.
+	_, err := strconv.Atoi(conf["limits.cpu"])
+	return err != nil
+}
+
+// deviceTaskBalanceApply sets the computed CPU pinning on the containers and returns the pinning successfully applied.
+// It also records whether the CPU count requested by a container had to be clamped to the CPUs it was given.
+func deviceTaskBalanceApply(pinning map[instance.Instance][]string) map[instance.Instance][]string {
+	applied := make(map[instance.Instance][]string, len(pinning))
+
 	for ctn, set := range pinning {
 		// Confirm the container didn't just stop
 		if ctn.InitPID() <= 0 {
 			continue
 		}
 
-		sort.Strings(set)
+		// Record whether the requested CPU count had to be clamped to the available CPUs.
+		// Pinned and unlimited containers are never clamped, so any previous record gets cleared.
+		count, err := strconv.Atoi(ctn.ExpandedConfig()["limits.cpu"])
+		clamped := err == nil && count > len(set)
+		if clamped != util.IsTrue(ctn.ExpandedConfig()["volatile.cpu.limit.clamped"]) {
+			value := ""
+			if clamped {
+				logger.Warn("Instance requested more CPUs than available, clamping CPU count", logger.Ctx{"project": ctn.Project().Name, "instance": ctn.Name(), "requested": count, "available": len(set)})
+				value = "true"
+			}
+
+			err = ctn.VolatileSet(map[string]string{"volatile.cpu.limit.clamped": value})
+			if err != nil {
+				logger.Error("Failed to record CPU limit clamping", logger.Ctx{"project": ctn.Project().Name, "instance": ctn.Name(), "err": err})
+			}
+		}
+
 		cg, err := ctn.CGroup()
 		if err != nil {
 			logger.Error("balance: Unable to get cgroup struct", logger.Ctx{"name": ctn.Name(), "err": err, "value": strings.Join(set, ",")})
@@ -611,8 +787,30 @@ func deviceTaskBalance(s *state.State) {
 		err = cg.SetCpuset(strings.Join(set, ","))
 		if err != nil {
 			logger.Error("balance: Unable to set cpuset", logger.Ctx{"name": ctn.Name(), "err": err, "value": strings.Join(set, ",")})
+			continue
 		}
+
+		applied[ctn] = set
+	}
+
+	return applied
+}
+
+// deviceRebalanceDelay is how long the scheduler waits after the last CPU event before re-balancing.
+// This coalesces the events of mass instance starts or stops into a single balance pass.
+const deviceRebalanceDelay = 500 * time.Millisecond
+
+// deviceRebalanceMaxDelay is the longest a continuous stream of CPU events can postpone a re-balance.
+const deviceRebalanceMaxDelay = 5 * time.Second
+
+// deviceRebalanceWait returns how long to wait before re-balancing, given when the first pending event was received.
+func deviceRebalanceWait(first time.Time, now time.Time) time.Duration {
+	remaining := deviceRebalanceMaxDelay - now.Sub(first)
+	if remaining < 0 {
+		return 0
 	}
+
+	return min(deviceRebalanceDelay, remaining)
 }
 
 // deviceEventListener starts the event listener for resource scheduling.
@@ -624,6 +822,23 @@ func deviceEventListener(stateFunc func() *state.State) {
 		return
 	}
 
+	// Pending re-balance, a nil channel blocks forever when none is scheduled.
+	var rebalanceTimer *time.Timer
+	var rebalance <-chan time.Time
+	var rebalanceFirst time.Time
+
+	scheduleRebalance := func() {
+		now := time.Now()
+		if rebalanceTimer == nil {
+			rebalanceFirst = now
+		} else {
+			rebalanceTimer.Stop()
+		}
+
+		rebalanceTimer = time.NewTimer(deviceRebalanceWait(rebalanceFirst, now))
+		rebalance = rebalanceTimer.C
+	}
+
 	for {
 		select {
 		case e := <-chNetlinkCPU:
@@ -639,7 +854,7 @@ func deviceEventListener(stateFunc func() *state.State) {
 			}
 
 			logger.Debugf("Scheduler: cpu: %s is now %s: re-balancing", e[0], e[1])
-			deviceTaskBalance(s)
+			scheduleRebalance()
 
 		case e := <-chUSB:
 			device.USBRunHandlers(stateFunc(), &e)
@@ -658,7 +873,13 @@ func deviceEventListener(stateFunc func() *state.State) {
 			}
 
 			logger.Debugf("Scheduler: %s %s %s: re-balancing", e[0], e[1], e[2])
-			deviceTaskBalance(s)
+			scheduleRebalance()
+
+		case <-rebalance:
+			rebalanceTimer = nil
+			rebalance = nil
+
+			deviceTaskBalance(stateFunc())
 		}
 	}
 }
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// balanceTestInstance is a minimal instance.Instance usable as a pinning map key.
+type balanceTestInstance struct {
+	instance.Instance
+
+	name   string
+	config map[string]string
+}
+
+func (i *balanceTestInstance) ExpandedConfig() map[string]string {
+	return i.config
+}
+
+func (i *balanceTestInstance) Name() string {
+	return i.name
+}
+
+func (i *balanceTestInstance) Project() api.Project {
+	return api.Project{Name: api.ProjectDefaultName}
+}
+
+// Test that pinned instances keep their CPUs and load-balanced instances get the least used ones.
+func TestDeviceTaskBalanceCompute(t *testing.T) {
+	pinned := &balanceTestInstance{name: "pinned"}
+	balanced := &balanceTestInstance{name: "balanced"}
+
+	fixedInstances := map[int64][]instance.Instance{
+		0: {pinned},
+		1: {pinned},
+	}
+
+	balancedInstances := map[instance.Instance]int{
+		balanced: 2,
+	}
+
+	pinning := deviceTaskBalanceCompute([]int64{0, 1, 2, 3}, nil, fixedInstances, balancedInstances)
+
+	assert.Len(t, pinning, 2)
+	assert.Equal(t, []string{"0", "1"}, pinning[pinned])
+	assert.Equal(t, []string{"2", "3"}, pinning[balanced])
+}
+
+// Test that an empty host produces an empty plan.
+func TestDeviceTaskBalanceCompute_Empty(t *testing.T) {
+	pinning := deviceTaskBalanceCompute(nil, nil, map[int64][]instance.Instance{}, map[instance.Instance]int{})
+
+	assert.Empty(t, pinning)
+}
+
+// Test the CPU policies on two cores of two threads each, with the threads of a core numbered apart (0+2 and 1+3).
+func TestDeviceTaskBalanceCompute_Policy(t *testing.T) {
+	cpus := []int64{0, 1, 2, 3}
+	cpuCores := map[int64]int64{0: 0, 2: 0, 1: 1, 3: 1}
+
+	tests := []struct {
+		policy string
+		want   []string
+	}{
+		{policy: "", want: []string{"0", "1"}},
+		{policy: "spread", want: []string{"0", "1"}},
+		{policy: "pack", want: []string{"0", "2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			balanced := &balanceTestInstance{name: "balanced", config: map[string]string{"limits.cpu.policy": tt.policy}}
+
+			pinning := deviceTaskBalanceCompute(cpus, cpuCores, map[int64][]instance.Instance{}, map[instance.Instance]int{balanced: 2})
+			assert.Equal(t, tt.want, pinning[balanced])
+		})
+	}
+}
+
+// Test that the spread policy avoids the core of a pinned instance while the pack policy fills it.
+func TestDeviceTaskBalanceCompute_PolicyWithPinned(t *testing.T) {
+	cpus := []int64{0, 1, 2, 3}
+	cpuCores := map[int64]int64{0: 0, 2: 0, 1: 1, 3: 1}
+
+	for policy, want := range map[string][]string{"spread": {"1"}, "pack": {"2"}} {
+		t.Run(policy, func(t *testing.T) {
+			pinned := &balanceTestInstance{name: "pinned"}
+			balanced := &balanceTestInstance{name: "balanced", config: map[string]string{"limits.cpu.policy": policy}}
+
+			pinning := deviceTaskBalanceCompute(cpus, cpuCores, map[int64][]instance.Instance{0: {pinned}}, map[instance.Instance]int{balanced: 1})
+			assert.Equal(t, want, pinning[balanced])
+		})
+	}
+}
+
+// Test that balanced containers avoid the CPUs of isolated pinned containers, unless no other CPU is available.
+func TestDeviceTaskBalanceCompute_Isolated(t *testing.T) {
+	cpus := []int64{0, 1, 2, 3}
+
+	isolated := &balanceTestInstance{name: "isolated", config: map[string]string{"limits.cpu": "0-1", "limits.cpu.isolated": "true"}}
+	pinned := &balanceTestInstance{name: "pinned", config: map[string]string{"limits.cpu": "2"}}
+	balanced := &balanceTestInstance{name: "balanced"}
+
+	fixedInstances := map[int64][]instance.Instance{0: {isolated}, 1: {isolated}, 2: {pinned}}
+	pinning := deviceTaskBalanceCompute(cpus, nil, fixedInstances, map[instance.Instance]int{balanced: 2})
+	assert.Equal(t, []string{"0", "1"}, pinning[isolated])
+	assert.Equal(t, []string{"2", "3"}, pinning[balanced])
+
+	// Without isolation, the least used CPUs are picked.
+	isolated.config["limits.cpu.isolated"] = "false"
+	pinning = deviceTaskBalanceCompute(cpus, nil, fixedInstances, map[instance.Instance]int{balanced: 1})
+	assert.Equal(t, []string{"3"}, pinning[balanced])
+
+	// All CPUs isolated.
+	isolated.config["limits.cpu"] = "0-3"
+	isolated.config["limits.cpu.isolated"] = "true"
+	fixedInstances = map[int64][]instance.Instance{0: {isolated}, 1: {isolated}, 2: {isolated}, 3: {isolated}}
+	pinning = deviceTaskBalanceCompute(cpus, nil, fixedInstances, map[instance.Instance]int{balanced: 1})
+	assert.Len(t, pinning[balanced], 1)
+}
+
+// Test that re-balances are delayed after each event but never postponed past the maximum delay.
+func TestDeviceRebalanceWait(t *testing.T) {
+	first := time.Now()
+
+	assert.Equal(t, deviceRebalanceDelay, deviceRebalanceWait(first, first))
+	assert.Equal(t, deviceRebalanceDelay, deviceRebalanceWait(first, first.Add(time.Second)))
+	assert.Equal(t, 200*time.Millisecond, deviceRebalanceWait(first, first.Add(deviceRebalanceMaxDelay-200*time.Millisecond)))
+	assert.Equal(t, time.Duration(0), deviceRebalanceWait(first, first.Add(deviceRebalanceMaxDelay+time.Second)))
+}
+
+// Test that the applied pinning is only published when it changed and that publishing never blocks.
+func TestDeviceTaskBalancePublish(t *testing.T) {
+	inst := &balanceTestInstance{name: "c1"}
+
+	deviceTaskBalancePublish(map[instance.Instance][]string{inst: {"0", "2"}})
+
+	select {
+	case e := <-deviceSchedPinned:
+		assert.Equal(t, instance.Instance(inst), e.inst)
+		assert.Equal(t, "0,2", e.cpuset)
+	default:
+		t.Fatal("No CPU pinning event published")
+	}
+
+	// An unchanged pinning isn't published again.
+	deviceTaskBalancePublish(map[instance.Instance][]string{inst: {"0", "2"}})
+	assert.Empty(t, deviceSchedPinned)
+
+	// A changed pinning is.
+	deviceTaskBalancePublish(map[instance.Instance][]string{inst: {"1"}})
+	assert.Len(t, deviceSchedPinned, 1)
+	<-deviceSchedPinned
+
+	// A container that was left out of a balance is published again.
+	deviceTaskBalancePublish(map[instance.Instance][]string{})
+	deviceTaskBalancePublish(map[instance.Instance][]string{inst: {"1"}})
+	assert.Len(t, deviceSchedPinned, 1)
+	<-deviceSchedPinned
+
+	// Fill the channel past its capacity without a consumer.
+	for i := range cap(deviceSchedPinned) + 1 {
+		deviceTaskBalancePublish(map[instance.Instance][]string{inst: {strconv.Itoa(i)}})
+	}
+
+	assert.Len(t, deviceSchedPinned, cap(deviceSchedPinned))
+
+	for len(deviceSchedPinned) > 0 {
+		<-deviceSchedPinned
+	}
+
+	// The dropped pinning is retried on the next balance.
+	deviceTaskBalancePublish(map[instance.Instance][]string{inst: {strconv.Itoa(cap(deviceSchedPinned))}})
+	assert.Len(t, deviceSchedPinned, 1)
+	<-deviceSchedPinned
+}
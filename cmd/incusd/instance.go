@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -558,16 +560,66 @@ func autoCreateInstanceSnapshots(ctx context.Context, s *state.State, instances
 			return err
 		}
 
-		err = inst.Snapshot(snapshotName, expiry, false)
+		err = inst.Snapshot(snapshotName, expiry, false, true)
 		if err != nil {
 			l.Error("Error creating snapshot", logger.Ctx{"snapshot": snapshotName, "err": err})
 			return err
 		}
+
+		err = pruneInstanceSnapshotsOverMaximum(ctx, s, inst)
+		if err != nil {
+			l.Error("Error pruning snapshots over snapshots.schedule.maximum", logger.Ctx{"err": err})
+		}
 	}
 
 	return nil
 }
 
+// instanceSnapshotsOverMaximum returns the oldest scheduled snapshots that exceed the maximum number of
+// scheduled snapshots. Manually created snapshots are ignored. A maximum of 0 means no limit.
+func instanceSnapshotsOverMaximum(snapshots []instance.Instance, maximum int) []instance.Instance {
+	if maximum <= 0 {
+		return nil
+	}
+
+	sorted := make([]instance.Instance, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if util.IsTrue(snapshot.LocalConfig()["volatile.snapshot.scheduled"]) {
+			sorted = append(sorted, snapshot)
+		}
+	}
+
+	if len(sorted) <= maximum {
+		return nil
+	}
+
+	slices.SortStableFunc(sorted, func(a instance.Instance, b instance.Instance) int {
+		return a.CreationDate().Compare(b.CreationDate())
+	})
+
+	return sorted[:len(sorted)-maximum]
+}
+
+// pruneInstanceSnapshotsOverMaximum deletes the oldest scheduled snapshots of an instance beyond its snapshots.schedule.maximum.
+func pruneInstanceSnapshotsOverMaximum(ctx context.Context, s *state.State, inst instance.Instance) error {
+	value := inst.ExpandedConfig()["snapshots.schedule.maximum"]
+	if value == "" {
+		return nil
+	}
+
+	maximum, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("Invalid snapshots.schedule.maximum: %w", err)
+	}
+
+	snapshots, err := inst.Snapshots()
+	if err != nil {
+		return err
+	}
+
+	return pruneExpiredInstanceSnapshots(ctx, s, instanceSnapshotsOverMaximum(snapshots, maximum))
+}
+
 var instSnapshotsPruneRunning = sync.Map{}
 
 func pruneExpiredInstanceSnapshots(ctx context.Context, s *state.State, snapshots []instance.Instance) error {
@@ -586,7 +638,7 @@ func pruneExpiredInstanceSnapshots(ctx context.Context, s *state.State, snapshot
 		err = snapshot.Delete(true)
 		instSnapshotsPruneRunning.Delete(snapshot.ID())
 		if err != nil {
-			return fmt.Errorf("Failed to delete expired instance snapshot %q in project %q: %w", snapshot.Name(), snapshot.Project().Name, err)
+			return fmt.Errorf("Failed to delete instance snapshot %q in project %q: %w", snapshot.Name(), snapshot.Project().Name, err)
 		}
 
 		logger.Debug("Deleted instance snapshot", logger.Ctx{"project": snapshot.Project().Name, "snapshot": snapshot.Name()})
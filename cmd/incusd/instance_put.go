@@ -233,6 +233,12 @@ func instanceSnapRestore(s *state.State, projectName string, name string, snap s
 	}
 	source.SetOperation(op)
 
+	// Check the UUIDs that are restored from the snapshot.
+	err = internalInstance.ValidateInstanceUUIDs(source.LocalConfig())
+	if err != nil {
+		return fmt.Errorf("Invalid snapshot %q: %w", snap, err)
+	}
+
 	// Generate a new `volatile.uuid.generation` to differentiate this instance restored from a snapshot from the original instance.
 	source.LocalConfig()["volatile.uuid.generation"] = uuid.New().String()
 
@@ -241,5 +247,11 @@ func instanceSnapRestore(s *state.State, projectName string, name string, snap s
 		return err
 	}
 
+	// Check that the restored instance can tell it was moved back in time.
+	err = internalInstance.ValidateRestoredInstanceUUIDs(inst.LocalConfig())
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
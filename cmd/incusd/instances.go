@@ -14,6 +14,7 @@ import (
 	"sync"
 	"time"
 
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
 	"github.com/lxc/incus/v6/internal/server/auth"
 	"github.com/lxc/incus/v6/internal/server/db"
 	"github.com/lxc/incus/v6/internal/server/db/cluster"
@@ -197,9 +198,29 @@ func instanceShouldAutoStart(inst instance.Instance) bool {
 	autoStart := config["boot.autostart"]
 	lastState := config["volatile.last_state.power"]
 
+	if util.IsTrue(config["security.protection.start"]) {
+		return false
+	}
+
 	return util.IsTrue(autoStart) || (autoStart == "" && lastState == instance.PowerStateRunning)
 }
 
+// instanceAutostartMissingRequires returns the instances from boot.autostart.requires that are part of the
+// supplied list but aren't running.
+func instanceAutostartMissingRequires(inst instance.Instance, instances []instance.Instance) []string {
+	missing := []string{}
+	for _, name := range instance.AutostartRequires(inst.ExpandedConfig()) {
+		for _, other := range instances {
+			if other.Project().Name == inst.Project().Name && other.Name() == name && !other.IsRunning() {
+				missing = append(missing, name)
+				break
+			}
+		}
+	}
+
+	return missing
+}
+
 func instancesStart(s *state.State, instances []instance.Instance) {
 	// Check if the cluster is currently evacuated.
 	if s.DB.Cluster.LocalNodeIsEvacuated() {
@@ -210,14 +231,20 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 	instancesStartMu.Lock()
 	defer instancesStartMu.Unlock()
 
-	// Sort based on instance boot priority.
-	sort.Sort(instanceAutostartList(instances))
+	// Sort based on instance boot priority and requirements.
+	sorted, err := instance.SortInstancesForStartup(instances)
+	if err != nil {
+		logger.Error("Failed ordering instances for auto start, ignoring boot.autostart.requires", logger.Ctx{"err": err})
+
+		sort.Sort(instanceAutostartList(instances))
+		sorted = instances
+	}
 
 	// Let's make up to 3 attempts to start instances.
 	maxAttempts := 3
 
 	// Start the instances
-	for _, inst := range instances {
+	for _, inst := range sorted {
 		if !instanceShouldAutoStart(inst) {
 			continue
 		}
@@ -234,6 +261,13 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 
 		instLogger := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
+		// Skip instances whose required instances aren't running.
+		missing := instanceAutostartMissingRequires(inst, instances)
+		if len(missing) > 0 {
+			instLogger.Warn("Skipping auto start of instance as required instances aren't running", logger.Ctx{"required": missing})
+			continue
+		}
+
 		// Try to start the instance.
 		var attempt = 0
 		for {
@@ -291,29 +325,6 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 	}
 }
 
-type instanceStopList []instance.Instance
-
-func (slice instanceStopList) Len() int {
-	return len(slice)
-}
-
-func (slice instanceStopList) Less(i, j int) bool {
-	iOrder := slice[i].ExpandedConfig()["boot.stop.priority"]
-	jOrder := slice[j].ExpandedConfig()["boot.stop.priority"]
-
-	if iOrder != jOrder {
-		iOrderInt, _ := strconv.Atoi(iOrder)
-		jOrderInt, _ := strconv.Atoi(jOrder)
-		return iOrderInt > jOrderInt // check this line (prob <)
-	}
-
-	return slice[i].Name() < slice[j].Name()
-}
-
-func (slice instanceStopList) Swap(i, j int) {
-	slice[i], slice[j] = slice[j], slice[i]
-}
-
 // Return all local instances on disk (if instance is running, it will attempt to populate the instance's local
 // and expanded config using the backup.yaml file). It will clear the instance's profiles property to avoid needing
 // to enrich them from the database.
@@ -385,7 +396,7 @@ func instancesOnDisk(s *state.State) ([]instance.Instance, error) {
 }
 
 func instancesShutdown(s *state.State, instances []instance.Instance) {
-	sort.Sort(instanceStopList(instances))
+	instances = instance.SortInstancesForShutdown(instances)
 
 	// Limit shutdown concurrency to number of instances or number of CPU cores (which ever is less).
 	var wg sync.WaitGroup
@@ -406,6 +417,11 @@ func instancesShutdown(s *state.State, instances []instance.Instance) {
 					timeoutSeconds, _ = strconv.Atoi(value)
 				}
 
+				timeout, clamped := internalInstance.ClampHostShutdownTimeout(int64(timeoutSeconds))
+				if clamped {
+					logger.Warn("Capping instance shutdown timeout to one hour", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "requested": value})
+				}
+
 				action := inst.ExpandedConfig()["boot.host_shutdown_action"]
 				if action == "stateful-stop" {
 					err := inst.Stop(true)
@@ -418,7 +434,7 @@ func instancesShutdown(s *state.State, instances []instance.Instance) {
 						logger.Warn("Failed forcefully stopping instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
 					}
 				} else {
-					err := inst.Shutdown(time.Second * time.Duration(timeoutSeconds))
+					err := inst.Shutdown(time.Second * time.Duration(timeout))
 					if err != nil {
 						logger.Warn("Failed shutting down instance, forcefully stopping", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
 						err = inst.Stop(false)
@@ -102,6 +102,11 @@ func createFromImage(s *state.State, r *http.Request, p api.Project, profiles []
 		return response.BadRequest(err)
 	}
 
+	err = instance.ValidHostConfig(db.ExpandInstanceConfig(req.Config, profiles), dbType)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid config: %w", err))
+	}
+
 	run := func(op *operations.Operation) error {
 		devices := deviceConfig.NewDevices(req.Devices)
 
@@ -165,6 +170,11 @@ func createFromNone(s *state.State, r *http.Request, projectName string, profile
 		return response.BadRequest(err)
 	}
 
+	err = instance.ValidHostConfig(db.ExpandInstanceConfig(req.Config, profiles), dbType)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid config: %w", err))
+	}
+
 	devices := deviceConfig.NewDevices(req.Devices)
 
 	args := db.InstanceArgs{
@@ -17,6 +17,7 @@ package main
 #include <string.h>
 #include <sys/mount.h>
 #include <sys/stat.h>
+#include <sys/statvfs.h>
 #include <sys/types.h>
 #include <unistd.h>
 
@@ -468,6 +469,73 @@ static void do_incus_forkumount(int pidfd, int ns_fd)
 	_exit(0);
 }
 
+static void do_incus_forkremount(int pidfd, int ns_fd)
+{
+	struct lxc_mount_attr attr = {};
+	struct statvfs sb;
+	unsigned long mntflags = MS_REMOUNT | MS_BIND;
+	bool readonly;
+	char *path = NULL;
+	int ret;
+
+	path = advance_arg(true);
+	readonly = strcmp(advance_arg(true), "true") == 0;
+
+	if (!change_namespaces(pidfd, ns_fd, CLONE_NEWNS)) {
+		fprintf(stderr, "Failed to setns to container mount namespace: %s\n", strerror(errno));
+		_exit(1);
+	}
+
+	// Only change the read-only flag of the mount.
+	if (readonly)
+		attr.attr_set = MOUNT_ATTR_RDONLY;
+	else
+		attr.attr_clr = MOUNT_ATTR_RDONLY;
+
+	ret = incus_mount_setattr(-EBADF, path, 0, &attr, sizeof(attr));
+	if (ret == 0)
+		_exit(0);
+
+	if (errno != ENOSYS) {
+		fprintf(stderr, "Error remounting %s: %s\n", path, strerror(errno));
+		_exit(1);
+	}
+
+	// Fallback to a bind remount, keeping the other flags of the mount.
+	if (statvfs(path, &sb) < 0) {
+		fprintf(stderr, "Failed to get mount flags of %s: %s\n", path, strerror(errno));
+		_exit(1);
+	}
+
+	if (sb.f_flag & ST_NOSUID)
+		mntflags |= MS_NOSUID;
+
+	if (sb.f_flag & ST_NODEV)
+		mntflags |= MS_NODEV;
+
+	if (sb.f_flag & ST_NOEXEC)
+		mntflags |= MS_NOEXEC;
+
+	if (sb.f_flag & ST_NOATIME)
+		mntflags |= MS_NOATIME;
+
+	if (sb.f_flag & ST_NODIRATIME)
+		mntflags |= MS_NODIRATIME;
+
+	if (sb.f_flag & ST_RELATIME)
+		mntflags |= MS_RELATIME;
+
+	if (readonly)
+		mntflags |= MS_RDONLY;
+
+	if (mount(NULL, path, NULL, mntflags, NULL) < 0) {
+		fprintf(stderr, "Error remounting %s: %s\n", path, strerror(errno));
+		_exit(1);
+	}
+
+	_exit(0);
+}
+
 static void do_lxc_forkmount(void)
 {
 #if VERSION_AT_LEAST(3, 1, 0)
@@ -623,6 +691,22 @@ void forkmount(void)
 		do_incus_forkumount(pidfd, ns_fd);
 	} else if (strcmp(command, "lxc-umount") == 0) {
 		do_lxc_forkumount();
+	} else if (strcmp(command, "go-remount") == 0) {
+		// Get the pid
+		cur = advance_arg(false);
+		if (cur == NULL || (strcmp(cur, "--help") == 0 || strcmp(cur, "--version") == 0 || strcmp(cur, "-h") == 0))
+			return;
+
+		pid = atoi(cur);
+		if (pid <= 0)
+			_exit(EXIT_FAILURE);
+
+		pidfd = atoi(advance_arg(true));
+		ns_fd = pidfd_nsfd(pidfd, pid);
+		if (ns_fd < 0)
+			_exit(EXIT_FAILURE);
+
+		do_incus_forkremount(pidfd, ns_fd);
 	}
 }
 */
@@ -680,6 +764,13 @@ func (c *cmdForkmount) Command() *cobra.Command {
 	cmdGoUmount.RunE = c.Run
 	cmd.AddCommand(cmdGoUmount)
 
+	// remount
+	cmdGoRemount := &cobra.Command{}
+	cmdGoRemount.Use = "go-remount <PID> <PidFd> <path> <readonly>"
+	cmdGoRemount.Args = cobra.ExactArgs(4)
+	cmdGoRemount.RunE = c.Run
+	cmd.AddCommand(cmdGoRemount)
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
@@ -630,10 +630,6 @@ func (c *cmdForkproxy) Run(cmd *cobra.Command, args []string) error {
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, unix.SIGTERM)
 
-	if lAddr.ConnType == "unix" && !lAddr.Abstract {
-		defer func() { _ = os.Remove(lAddr.Address) }()
-	}
-
 	epFd := C.epoll_create1(C.EPOLL_CLOEXEC)
 	if epFd < 0 {
 		return fmt.Errorf("Failed to create new epoll instance")
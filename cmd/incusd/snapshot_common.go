@@ -8,8 +8,8 @@ import (
 
 	"github.com/robfig/cron/v3"
 
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
-	"github.com/lxc/incus/v6/shared/util"
 )
 
 // SnapshotScheduleAliases contains the mapping of scheduling aliases to cron syntax
@@ -42,15 +42,8 @@ func snapshotIsScheduledNow(spec string, subjectID int64) bool {
 func buildCronSpecs(spec string, subjectID int64) []string {
 	var result []string
 
-	if strings.Contains(spec, ", ") {
-		for _, curSpec := range util.SplitNTrimSpace(spec, ",", -1, true) {
-			entry := getCronSyntax(curSpec, subjectID)
-			if entry != "" {
-				result = append(result, entry)
-			}
-		}
-	} else {
-		entry := getCronSyntax(spec, subjectID)
+	for _, curSpec := range internalInstance.SnapshotScheduleTriggers(spec) {
+		entry := getCronSyntax(curSpec, subjectID)
 		if entry != "" {
 			result = append(result, entry)
 		}
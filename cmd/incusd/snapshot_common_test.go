@@ -2,7 +2,9 @@ package main
 
 import (
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/lxc/incus/v6/internal/server/db"
@@ -38,3 +40,58 @@ func (suite *containerTestSuite) TestSnapshotScheduling() {
 func TestSnapshotCommon(t *testing.T) {
 	suite.Run(t, new(containerTestSuite))
 }
+
+// snapshotTestInstance is a minimal instance.Instance with a creation date.
+type snapshotTestInstance struct {
+	instance.Instance
+
+	name      string
+	created   time.Time
+	scheduled bool
+}
+
+func (i *snapshotTestInstance) CreationDate() time.Time {
+	return i.created
+}
+
+func (i *snapshotTestInstance) LocalConfig() map[string]string {
+	if !i.scheduled {
+		return map[string]string{}
+	}
+
+	return map[string]string{"volatile.snapshot.scheduled": "true"}
+}
+
+// Test that only the oldest scheduled snapshots beyond the maximum are selected for pruning.
+func TestInstanceSnapshotsOverMaximum(t *testing.T) {
+	now := time.Now()
+	snap0 := &snapshotTestInstance{name: "snap0", created: now.Add(-3 * time.Hour), scheduled: true}
+	snap1 := &snapshotTestInstance{name: "snap1", created: now.Add(-2 * time.Hour), scheduled: true}
+	snap2 := &snapshotTestInstance{name: "snap2", created: now.Add(-1 * time.Hour), scheduled: true}
+	snapshots := []instance.Instance{snap2, snap0, snap1}
+
+	assert.Empty(t, instanceSnapshotsOverMaximum(snapshots, 0))
+	assert.Empty(t, instanceSnapshotsOverMaximum(snapshots, 3))
+	assert.Empty(t, instanceSnapshotsOverMaximum(snapshots, 5))
+	assert.Equal(t, []instance.Instance{snap0}, instanceSnapshotsOverMaximum(snapshots, 2))
+	assert.Equal(t, []instance.Instance{snap0, snap1}, instanceSnapshotsOverMaximum(snapshots, 1))
+
+	// The original list is left untouched.
+	assert.Equal(t, []instance.Instance{snap2, snap0, snap1}, snapshots)
+}
+
+// Test that manual snapshots are neither counted nor pruned.
+func TestInstanceSnapshotsOverMaximumManual(t *testing.T) {
+	now := time.Now()
+	manual0 := &snapshotTestInstance{name: "before-upgrade", created: now.Add(-5 * time.Hour)}
+	snap0 := &snapshotTestInstance{name: "snap0", created: now.Add(-4 * time.Hour), scheduled: true}
+	manual1 := &snapshotTestInstance{name: "snap1", created: now.Add(-3 * time.Hour)}
+	snap2 := &snapshotTestInstance{name: "snap2", created: now.Add(-2 * time.Hour), scheduled: true}
+	snap3 := &snapshotTestInstance{name: "snap3", created: now.Add(-1 * time.Hour), scheduled: true}
+	snapshots := []instance.Instance{manual0, snap0, manual1, snap2, snap3}
+
+	assert.Empty(t, instanceSnapshotsOverMaximum(snapshots, 3))
+	assert.Equal(t, []instance.Instance{snap0}, instanceSnapshotsOverMaximum(snapshots, 2))
+	assert.Equal(t, []instance.Instance{snap0, snap2}, instanceSnapshotsOverMaximum(snapshots, 1))
+	assert.Empty(t, instanceSnapshotsOverMaximum([]instance.Instance{manual0, manual1}, 1))
+}
@@ -290,6 +290,11 @@ func (c *cmdMigrate) validate(source source, target target) error {
 		"security.devlxd.images",
 	}
 
+	// Hints on how to replace some of the deprecated configuration keys.
+	deprecatedInstanceConfigHints := map[string]string{
+		"limits.network.priority": " (set limits.priority on the NIC devices instead)",
+	}
+
 	deprecatedInstanceDeviceConfigs := []string{
 		"maas.subnet.ipv4",
 		"maas.subnet.ipv6",
@@ -312,7 +317,7 @@ func (c *cmdMigrate) validate(source source, target target) error {
 			for _, key := range deprecatedInstanceConfigs {
 				_, ok := inst.Config[key]
 				if ok {
-					errors = append(errors, fmt.Errorf("Source server has instance %q in project %q using deprecated configuration key %q", inst.Name, project.Name, key))
+					errors = append(errors, fmt.Errorf("Source server has instance %q in project %q using deprecated configuration key %q%s", inst.Name, project.Name, key, deprecatedInstanceConfigHints[key]))
 				}
 			}
 
@@ -335,7 +340,7 @@ func (c *cmdMigrate) validate(source source, target target) error {
 			for _, key := range deprecatedInstanceConfigs {
 				_, ok := profile.Config[key]
 				if ok {
-					errors = append(errors, fmt.Errorf("Source server has profile %q in project %q using deprecated configuration key %q", profile.Name, project.Name, key))
+					errors = append(errors, fmt.Errorf("Source server has profile %q in project %q using deprecated configuration key %q%s", profile.Name, project.Name, key, deprecatedInstanceConfigHints[key]))
 				}
 			}
 
@@ -0,0 +1,283 @@
+package instance
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/yaml.v2"
+)
+
+// cloudInitEmptyConfig is the cloud-config used when no user-data or vendor-data is supplied.
+const cloudInitEmptyConfig = "#cloud-config\n{}"
+
+// validateSSHKeys validates a list of OpenSSH public keys, one per line.
+func validateSSHKeys(value string) error {
+	for i, line := range strings.Split(value, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		_, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			return fmt.Errorf("Invalid SSH public key on line %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateNetworkConfig checks that a cloud-init network configuration declares a version supported by cloud-init.
+// The configuration may either be the bare network configuration or be wrapped in a top-level network key.
+// This only checks the format version, not the configuration itself.
+func ValidateNetworkConfig(value string) error {
+	config := map[string]any{}
+	err := yaml.Unmarshal([]byte(value), &config)
+	if err != nil {
+		return fmt.Errorf("Network configuration isn't a YAML mapping: %w", err)
+	}
+
+	network, ok := config["network"].(map[any]any)
+	if ok {
+		config = map[string]any{"version": network["version"]}
+	}
+
+	version, ok := config["version"]
+	if !ok || version == nil {
+		return fmt.Errorf("Network configuration is missing its version field")
+	}
+
+	if version != 1 && version != 2 {
+		return fmt.Errorf("Unsupported network configuration version %v (must be 1 or 2)", version)
+	}
+
+	return nil
+}
+
+// cloudInitYAMLString returns a string as a single line YAML scalar, only quoting it when needed.
+func cloudInitYAMLString(value string) string {
+	var parsed any
+	err := yaml.Unmarshal([]byte(value), &parsed)
+	if err == nil && parsed == value {
+		return value
+	}
+
+	return strconv.Quote(value)
+}
+
+// cloudInitInsertSSHKeys adds SSH public keys to the ssh_authorized_keys list of a cloud-config document body by
+// editing its text, so that the comments, ordering and formatting of the rest of the document are kept.
+// The result must be checked as it may not be valid for documents not using the block style.
+func cloudInitInsertSSHKeys(body string, keys []string, hasKeys bool) (string, error) {
+	entries := make([]string, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, "- "+cloudInitYAMLString(key)+"\n")
+	}
+
+	if !hasKeys {
+		// Replace the empty document used by default.
+		if strings.TrimSpace(body) == "{}" {
+			body = "\n"
+		}
+
+		if body != "" && !strings.HasSuffix(body, "\n") {
+			body += "\n"
+		}
+
+		return body + "ssh_authorized_keys:\n" + strings.Join(entries, ""), nil
+	}
+
+	lines := strings.SplitAfter(body, "\n")
+
+	// Find the existing list and its last entry.
+	start := -1
+	end := -1
+	indent := ""
+	for i, line := range lines {
+		if start == -1 {
+			rest, found := strings.CutPrefix(line, "ssh_authorized_keys:")
+			if !found {
+				continue
+			}
+
+			// Only lists on their own lines can be extended.
+			rest = strings.TrimSpace(rest)
+			if rest != "" && !strings.HasPrefix(rest, "#") {
+				return "", fmt.Errorf("Unsupported ssh_authorized_keys format")
+			}
+
+			start = i
+			end = i
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// Stop at the next top-level key.
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-") {
+			break
+		}
+
+		if end == start {
+			indent = line[:len(line)-len(strings.TrimLeft(line, " "))]
+		}
+
+		end = i
+	}
+
+	if start == -1 {
+		return "", fmt.Errorf("Unsupported ssh_authorized_keys format")
+	}
+
+	for i := range entries {
+		entries[i] = indent + entries[i]
+	}
+
+	if !strings.HasSuffix(lines[end], "\n") {
+		lines[end] += "\n"
+	}
+
+	lines = slices.Insert(lines, end+1, entries...)
+
+	return strings.Join(lines, ""), nil
+}
+
+// cloudInitAppendSSHKeys appends SSH public keys to the ssh_authorized_keys list of a cloud-config document.
+// The document is edited in place when possible and only re-generated (losing its comments and formatting) when
+// that isn't possible, such as for documents using the flow style.
+// Returns false if the supplied data isn't a cloud-config document and so couldn't be modified.
+func cloudInitAppendSSHKeys(data string, keys []string) (string, bool, error) {
+	body, isCloudConfig := strings.CutPrefix(data, "#cloud-config")
+	if !isCloudConfig || (body != "" && !strings.HasPrefix(body, "\n")) {
+		return data, false, nil
+	}
+
+	cloudConfig := yaml.MapSlice{}
+	err := yaml.Unmarshal([]byte(body), &cloudConfig)
+	if err != nil {
+		return "", false, fmt.Errorf("Failed parsing cloud-config: %w", err)
+	}
+
+	// Build the expected result.
+	found := false
+	for i, item := range cloudConfig {
+		if item.Key != "ssh_authorized_keys" {
+			continue
+		}
+
+		existing, ok := item.Value.([]any)
+		if !ok && item.Value != nil {
+			return "", false, fmt.Errorf("Invalid ssh_authorized_keys in cloud-config")
+		}
+
+		existing = slices.Clone(existing)
+		for _, key := range keys {
+			existing = append(existing, key)
+		}
+
+		cloudConfig[i].Value = existing
+		found = true
+		break
+	}
+
+	if !found {
+		newKeys := make([]any, 0, len(keys))
+		for _, key := range keys {
+			newKeys = append(newKeys, key)
+		}
+
+		cloudConfig = append(cloudConfig, yaml.MapItem{Key: "ssh_authorized_keys", Value: newKeys})
+	}
+
+	// Try editing the document, keeping the result only if it matches the expected content.
+	newBody, err := cloudInitInsertSSHKeys(body, keys, found)
+	if err == nil {
+		newConfig := yaml.MapSlice{}
+		err = yaml.Unmarshal([]byte(newBody), &newConfig)
+		if err == nil && reflect.DeepEqual(newConfig, cloudConfig) {
+			return "#cloud-config" + newBody, true, nil
+		}
+	}
+
+	out, err := yaml.Marshal(cloudConfig)
+	if err != nil {
+		return "", false, err
+	}
+
+	return "#cloud-config\n" + string(out), true, nil
+}
+
+// CloudInitConfigKey returns the config key holding the cloud-init data of the given name ("user-data",
+// "vendor-data" or "network-config"). The legacy user.* key is returned when it's set and the cloud-init.* key isn't.
+func CloudInitConfigKey(config map[string]string, name string) string {
+	_, ok := config["cloud-init."+name]
+	if !ok {
+		_, ok = config["user."+name]
+		if ok {
+			return "user." + name
+		}
+	}
+
+	return "cloud-init." + name
+}
+
+// CloudInitConfig returns the effective cloud-init user-data and vendor-data of an instance.
+// The legacy user.* keys and an empty cloud-config are used when the cloud-init.* keys aren't set.
+// Any SSH public keys from cloud-init.ssh-keys are appended to the ssh_authorized_keys of the user-data,
+// or of the vendor-data when the user-data isn't a cloud-config document.
+func CloudInitConfig(config map[string]string) (string, string, error) {
+	userData, ok := config["cloud-init.user-data"]
+	if !ok {
+		userData = config["user.user-data"]
+		if userData == "" {
+			userData = cloudInitEmptyConfig
+		}
+	}
+
+	vendorData, ok := config["cloud-init.vendor-data"]
+	if !ok {
+		vendorData = config["user.vendor-data"]
+		if vendorData == "" {
+			vendorData = cloudInitEmptyConfig
+		}
+	}
+
+	keys := []string{}
+	for _, line := range strings.Split(config["cloud-init.ssh-keys"], "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			keys = append(keys, line)
+		}
+	}
+
+	if len(keys) == 0 {
+		return userData, vendorData, nil
+	}
+
+	newUserData, merged, err := cloudInitAppendSSHKeys(userData, keys)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed adding SSH keys to user-data: %w", err)
+	}
+
+	if merged {
+		return newUserData, vendorData, nil
+	}
+
+	newVendorData, merged, err := cloudInitAppendSSHKeys(vendorData, keys)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed adding SSH keys to vendor-data: %w", err)
+	}
+
+	if !merged {
+		return "", "", fmt.Errorf("Cannot add SSH keys as neither user-data nor vendor-data are cloud-config documents")
+	}
+
+	return userData, newVendorData, nil
+}
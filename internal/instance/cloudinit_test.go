@@ -0,0 +1,234 @@
+package instance
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+const testSSHKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGcuKUmwYu8tmhzZehsvSMFlE7cm3rB4IeRpWbPRRC3v user@host"
+
+func TestValidateSSHKeys(t *testing.T) {
+	checker, err := ConfigKeyChecker("cloud-init.ssh-keys", api.InstanceTypeAny)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker(testSSHKey))
+	assert.NoError(t, checker(testSSHKey+"\n\n"+testSSHKey+"\n"))
+	assert.Error(t, checker(testSSHKey[:40]))
+	assert.Error(t, checker("not a key"))
+}
+
+// assertCloudConfigEqual compares the header and parsed content of two cloud-init documents.
+func assertCloudConfigEqual(t *testing.T, expected string, actual string) {
+	t.Helper()
+
+	expectedHeader, expectedBody, _ := strings.Cut(expected, "\n")
+	actualHeader, actualBody, _ := strings.Cut(actual, "\n")
+	assert.Equal(t, expectedHeader, actualHeader)
+
+	if expectedHeader != "#cloud-config" {
+		assert.Equal(t, expectedBody, actualBody)
+		return
+	}
+
+	var expectedContent, actualContent any
+	assert.NoError(t, yaml.Unmarshal([]byte(expectedBody), &expectedContent))
+	assert.NoError(t, yaml.Unmarshal([]byte(actualBody), &actualContent))
+	assert.Equal(t, expectedContent, actualContent)
+}
+
+func TestCloudInitConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     map[string]string
+		userData   string
+		vendorData string
+		wantErr    bool
+	}{
+		{
+			name:       "No SSH keys",
+			config:     map[string]string{},
+			userData:   cloudInitEmptyConfig,
+			vendorData: cloudInitEmptyConfig,
+		},
+		{
+			name:       "SSH keys without user-data",
+			config:     map[string]string{"cloud-init.ssh-keys": testSSHKey},
+			userData:   "#cloud-config\nssh_authorized_keys:\n- " + testSSHKey + "\n",
+			vendorData: cloudInitEmptyConfig,
+		},
+		{
+			name: "SSH keys appended to existing keys",
+			config: map[string]string{
+				"cloud-init.ssh-keys":  testSSHKey,
+				"cloud-init.user-data": "#cloud-config\npackages:\n- vim\nssh_authorized_keys:\n- ssh-rsa existing\n",
+			},
+			userData:   "#cloud-config\npackages:\n- vim\nssh_authorized_keys:\n- ssh-rsa existing\n- " + testSSHKey + "\n",
+			vendorData: cloudInitEmptyConfig,
+		},
+		{
+			name: "SSH keys with script user-data",
+			config: map[string]string{
+				"cloud-init.ssh-keys":  testSSHKey,
+				"cloud-init.user-data": "#!/bin/sh\ntrue\n",
+			},
+			userData:   "#!/bin/sh\ntrue\n",
+			vendorData: "#cloud-config\nssh_authorized_keys:\n- " + testSSHKey + "\n",
+		},
+		{
+			name: "SSH keys without any cloud-config",
+			config: map[string]string{
+				"cloud-init.ssh-keys":    testSSHKey,
+				"cloud-init.user-data":   "#!/bin/sh\ntrue\n",
+				"cloud-init.vendor-data": "#!/bin/sh\ntrue\n",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userData, vendorData, err := CloudInitConfig(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assertCloudConfigEqual(t, tt.userData, userData)
+			assertCloudConfigEqual(t, tt.vendorData, vendorData)
+		})
+	}
+}
+
+func TestCloudInitAppendSSHKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		keys        []string
+		want        string
+		regenerated bool
+	}{
+		{
+			name: "New list keeps comments and ordering",
+			data: "#cloud-config\n# Packages\npackages:\n  - vim\nhostname: foo # Name\n",
+			want: "#cloud-config\n# Packages\npackages:\n  - vim\nhostname: foo # Name\nssh_authorized_keys:\n- " + testSSHKey + "\n",
+		},
+		{
+			name: "Existing list keeps comments and ordering",
+			data: "#cloud-config\nssh_authorized_keys: # Admins\n  - ssh-rsa existing\n  # Old key\n\nusers:\n  - default\n",
+			want: "#cloud-config\nssh_authorized_keys: # Admins\n  - ssh-rsa existing\n  - " + testSSHKey + "\n  # Old key\n\nusers:\n  - default\n",
+		},
+		{
+			name: "Existing empty list",
+			data: "#cloud-config\nssh_authorized_keys:\nusers:\n- default\n",
+			want: "#cloud-config\nssh_authorized_keys:\n- " + testSSHKey + "\nusers:\n- default\n",
+		},
+		{
+			name: "Empty document",
+			data: cloudInitEmptyConfig,
+			want: "#cloud-config\nssh_authorized_keys:\n- " + testSSHKey + "\n",
+		},
+		{
+			name: "Keys needing quoting",
+			data: "#cloud-config\nssh_authorized_keys:\n- ssh-rsa existing\n",
+			keys: []string{"ssh-rsa AAAA user: #1"},
+			want: "#cloud-config\nssh_authorized_keys:\n- ssh-rsa existing\n- \"ssh-rsa AAAA user: #1\"\n",
+		},
+		{
+			name:        "Flow style list",
+			data:        "#cloud-config\nssh_authorized_keys: [ssh-rsa existing]\n",
+			want:        "#cloud-config\nssh_authorized_keys:\n- ssh-rsa existing\n- " + testSSHKey + "\n",
+			regenerated: true,
+		},
+		{
+			name:        "Quoted key",
+			data:        "#cloud-config\n\"ssh_authorized_keys\":\n- ssh-rsa existing\n",
+			want:        "#cloud-config\nssh_authorized_keys:\n- ssh-rsa existing\n- " + testSSHKey + "\n",
+			regenerated: true,
+		},
+		{
+			name:        "Flow style document",
+			data:        "#cloud-config\n{hostname: foo}\n",
+			want:        "#cloud-config\nhostname: foo\nssh_authorized_keys:\n- " + testSSHKey + "\n",
+			regenerated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := tt.keys
+			if keys == nil {
+				keys = []string{testSSHKey}
+			}
+
+			data, merged, err := cloudInitAppendSSHKeys(tt.data, keys)
+			assert.NoError(t, err)
+			assert.True(t, merged)
+
+			if tt.regenerated {
+				assertCloudConfigEqual(t, tt.want, data)
+			} else {
+				assert.Equal(t, tt.want, data)
+			}
+		})
+	}
+}
+
+func TestCloudInitConfigKey(t *testing.T) {
+	assert.Equal(t, "cloud-init.user-data", CloudInitConfigKey(map[string]string{}, "user-data"))
+	assert.Equal(t, "user.user-data", CloudInitConfigKey(map[string]string{"user.user-data": "#cloud-config"}, "user-data"))
+	assert.Equal(t, "cloud-init.user-data", CloudInitConfigKey(map[string]string{"user.user-data": "#cloud-config", "cloud-init.user-data": ""}, "user-data"))
+	assert.Equal(t, "cloud-init.vendor-data", CloudInitConfigKey(map[string]string{"user.user-data": "#cloud-config"}, "vendor-data"))
+}
+
+func TestValidateNetworkConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{
+			name:  "Version 2",
+			value: "version: 2\nethernets:\n  eth0:\n    dhcp4: true\n",
+		},
+		{
+			name:  "Version 1",
+			value: "version: 1\nconfig:\n- type: physical\n  name: eth0\n  subnets:\n  - type: dhcp\n",
+		},
+		{
+			name:  "Wrapped version 2",
+			value: "network:\n  version: 2\n  ethernets:\n    eth0:\n      dhcp4: true\n",
+		},
+		{
+			name:    "Unknown version",
+			value:   "version: 3\n",
+			wantErr: true,
+		},
+		{
+			name:    "Not a network configuration",
+			value:   "packages:\n- vim\n",
+			wantErr: true,
+		},
+		{
+			name:    "Not a mapping",
+			value:   "- eth0\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNetworkConfig(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -1,15 +1,26 @@
 package instance
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/kballard/go-shellquote"
+
+	"github.com/lxc/incus/v6/internal/server/instance/drivers/qemudefault"
 	scriptletLoad "github.com/lxc/incus/v6/internal/server/scriptlet/load"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/units"
+	"github.com/lxc/incus/v6/shared/util"
 	"github.com/lxc/incus/v6/shared/validate"
 )
 
@@ -18,9 +29,507 @@ func IsUserConfig(key string) bool {
 	return strings.HasPrefix(key, "user.")
 }
 
+// IsImageConfig returns true if the config key is an image property.
+func IsImageConfig(key string) bool {
+	return strings.HasPrefix(key, "image.")
+}
+
+// IsEnvironmentConfig returns true if the config key is an environment variable.
+func IsEnvironmentConfig(key string) bool {
+	return strings.HasPrefix(key, "environment.")
+}
+
 // ConfigVolatilePrefix indicates the prefix used for volatile config keys.
 const ConfigVolatilePrefix = "volatile."
 
+// IsVolatileConfig returns true if the config key is a volatile configuration.
+func IsVolatileConfig(key string) bool {
+	return strings.HasPrefix(key, ConfigVolatilePrefix)
+}
+
+// SyscallsFilePrefix is the prefix used by security.syscalls.allow and security.syscalls.deny to reference
+// a file holding the syscall list rather than specifying it inline.
+const SyscallsFilePrefix = "file://"
+
+// validateSyscallList validates an inline syscall list or a reference to a readable local file holding one.
+// The file is read again when the instance starts, on the server running it.
+func validateSyscallList(value string) error {
+	path, isFile := strings.CutPrefix(value, SyscallsFilePrefix)
+	if !isFile {
+		return nil
+	}
+
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("Syscall list file path %q must be absolute", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Failed opening syscall list file: %w", err)
+	}
+
+	_ = f.Close()
+
+	return nil
+}
+
+// validatePrlimit validates a process resource limit, either a single value applying to both the soft and hard
+// limits or a "soft:hard" pair, where each value is a non-negative integer or "unlimited".
+func validatePrlimit(value string) error {
+	limits := strings.Split(value, ":")
+	if len(limits) > 2 {
+		return fmt.Errorf("Invalid resource limit %q, expected a value or a soft:hard pair", value)
+	}
+
+	for _, limit := range limits {
+		if limit == "unlimited" {
+			continue
+		}
+
+		_, err := strconv.ParseUint(limit, 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid resource limit %q, expected an integer or \"unlimited\"", limit)
+		}
+	}
+
+	return nil
+}
+
+// ProcessesLimitMax is the largest value accepted by the kernel for the pids cgroup limit (PID_MAX_LIMIT).
+const ProcessesLimitMax = 4 * 1024 * 1024
+
+// validateProcessesLimit validates that a processes limit is a positive integer.
+func validateProcessesLimit(value string) error {
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid processes limit %q: %w", value, err)
+	}
+
+	if limit < 1 {
+		return fmt.Errorf("Processes limit must be at least 1 (leave empty for no limit)")
+	}
+
+	return nil
+}
+
+// ClampProcessesLimit caps a processes limit to ProcessesLimitMax.
+// Returns the limit to apply and whether it was clamped.
+func ClampProcessesLimit(limit int64) (int64, bool) {
+	if limit > ProcessesLimitMax {
+		return ProcessesLimitMax, true
+	}
+
+	return limit, false
+}
+
+// HostShutdownTimeoutMax is the longest time in seconds that the host waits for an instance to shut down.
+const HostShutdownTimeoutMax = 3600
+
+// validateHostShutdownTimeout validates that a host shutdown timeout is a non-negative integer.
+// Larger values than HostShutdownTimeoutMax are accepted so that existing configurations remain valid.
+func validateHostShutdownTimeout(value string) error {
+	timeout, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid host shutdown timeout %q: %w", value, err)
+	}
+
+	if timeout < 0 {
+		return fmt.Errorf("Host shutdown timeout must be at least 0")
+	}
+
+	return nil
+}
+
+// ClampHostShutdownTimeout caps a host shutdown timeout to HostShutdownTimeoutMax.
+// Returns the timeout to apply and whether it was clamped.
+func ClampHostShutdownTimeout(timeout int64) (int64, bool) {
+	if timeout > HostShutdownTimeoutMax {
+		return HostShutdownTimeoutMax, true
+	}
+
+	return timeout, false
+}
+
+// MknodDevice is a character device number that a container may create through mknod interception.
+type MknodDevice struct {
+	Major uint32
+	Minor uint32
+}
+
+// mknodDeviceNames maps the device names accepted by security.syscalls.intercept.mknod.allowed to their numbers.
+var mknodDeviceNames = map[string]MknodDevice{
+	"console": {Major: 5, Minor: 1},
+	"full":    {Major: 1, Minor: 7},
+	"null":    {Major: 1, Minor: 3},
+	"random":  {Major: 1, Minor: 8},
+	"tty":     {Major: 5, Minor: 0},
+	"urandom": {Major: 1, Minor: 9},
+	"zero":    {Major: 1, Minor: 5},
+}
+
+// ParseMknodAllowed parses a comma separated list of character devices, each either as "<major>:<minor>" or as
+// the name of a standard device (console, full, null, random, tty, urandom or zero).
+func ParseMknodAllowed(value string) ([]MknodDevice, error) {
+	devices := []MknodDevice{}
+	for _, entry := range util.SplitNTrimSpace(value, ",", -1, true) {
+		device, ok := mknodDeviceNames[entry]
+		if ok {
+			devices = append(devices, device)
+			continue
+		}
+
+		majorStr, minorStr, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("Invalid device %q, must be a device name or \"<major>:<minor>\"", entry)
+		}
+
+		major, err := strconv.ParseUint(majorStr, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid major number in device %q: %w", entry, err)
+		}
+
+		minor, err := strconv.ParseUint(minorStr, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid minor number in device %q: %w", entry, err)
+		}
+
+		devices = append(devices, MknodDevice{Major: uint32(major), Minor: uint32(minor)})
+	}
+
+	return devices, nil
+}
+
+// validateMknodAllowed validates a security.syscalls.intercept.mknod.allowed device list.
+func validateMknodAllowed(value string) error {
+	_, err := ParseMknodAllowed(value)
+
+	return err
+}
+
+// nvidiaDriverCapabilities is the list of known NVIDIA driver capabilities, other than "all".
+var nvidiaDriverCapabilities = []string{"compute", "compat32", "graphics", "utility", "video", "display", "ngx"}
+
+// validateNvidiaDriverCapabilities validates a comma-separated list of NVIDIA driver capabilities.
+// The special "all" value must be used on its own.
+func validateNvidiaDriverCapabilities(value string) error {
+	if value == "" || value == "all" {
+		return nil
+	}
+
+	return validate.IsListOf(validate.IsOneOf(nvidiaDriverCapabilities...))(value)
+}
+
+// validateRawIdmapRange validates a single ID or an inclusive "first-last" ID range.
+// Returns the first ID and the number of IDs covered.
+func validateRawIdmapRange(value string) (uint64, uint64, error) {
+	first, last, isRange := strings.Cut(value, "-")
+
+	base, err := strconv.ParseUint(first, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Invalid ID %q", first)
+	}
+
+	if !isRange {
+		return base, 1, nil
+	}
+
+	end, err := strconv.ParseUint(last, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Invalid ID %q", last)
+	}
+
+	if end < base {
+		return 0, 0, fmt.Errorf("Invalid ID range %q", value)
+	}
+
+	return base, end - base + 1, nil
+}
+
+// rawIdmapEntry is a single parsed line of a raw.idmap value.
+type rawIdmapEntry struct {
+	line     int
+	isUID    bool
+	isGID    bool
+	hostBase uint64
+	nsBase   uint64
+	size     uint64
+}
+
+// rawIdmapRangesOverlap returns whether the ranges [a, a+aSize) and [b, b+bSize) overlap.
+func rawIdmapRangesOverlap(a uint64, aSize uint64, b uint64, bSize uint64) bool {
+	return a < b+bSize && b < a+aSize
+}
+
+// validateRawIdmap validates a raw.idmap value.
+// Each non-empty line must be of the form "(uid|gid|both) <hostid> <nsid> [count]", where the IDs may
+// alternatively be given as inclusive "first-last" ranges of equal size.
+// Lines applying to the same kind of ID must not overlap, either on the host or in the namespace.
+func validateRawIdmap(value string) error {
+	var entries []rawIdmapEntry
+
+	for i, line := range strings.Split(value, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) != 3 && len(fields) != 4 {
+			return fmt.Errorf("Invalid ID map on line %d: Expected \"(uid|gid|both) <hostid> <nsid> [count]\"", i+1)
+		}
+
+		if !slices.Contains([]string{"uid", "gid", "both"}, fields[0]) {
+			return fmt.Errorf("Invalid ID map type %q on line %d", fields[0], i+1)
+		}
+
+		hostBase, hostSize, err := validateRawIdmapRange(fields[1])
+		if err != nil {
+			return fmt.Errorf("Invalid host ID on line %d: %w", i+1, err)
+		}
+
+		nsBase, nsSize, err := validateRawIdmapRange(fields[2])
+		if err != nil {
+			return fmt.Errorf("Invalid namespace ID on line %d: %w", i+1, err)
+		}
+
+		if hostSize != nsSize {
+			return fmt.Errorf("Invalid ID map on line %d: The ID ranges are of different sizes", i+1)
+		}
+
+		if len(fields) == 4 {
+			if hostSize != 1 {
+				return fmt.Errorf("Invalid ID map on line %d: A count can't be combined with ID ranges", i+1)
+			}
+
+			hostSize, err = strconv.ParseUint(fields[3], 10, 32)
+			if err != nil || hostSize == 0 {
+				return fmt.Errorf("Invalid ID count %q on line %d", fields[3], i+1)
+			}
+		}
+
+		if max(hostBase, nsBase)+hostSize-1 > math.MaxUint32 {
+			return fmt.Errorf("Invalid ID map on line %d: IDs out of range", i+1)
+		}
+
+		entry := rawIdmapEntry{
+			line:     i + 1,
+			isUID:    fields[0] != "gid",
+			isGID:    fields[0] != "uid",
+			hostBase: hostBase,
+			nsBase:   nsBase,
+			size:     hostSize,
+		}
+
+		for _, other := range entries {
+			if !(entry.isUID && other.isUID) && !(entry.isGID && other.isGID) {
+				continue
+			}
+
+			if rawIdmapRangesOverlap(entry.hostBase, entry.size, other.hostBase, other.size) {
+				return fmt.Errorf("Invalid ID map: Host IDs on lines %d and %d overlap", other.line, entry.line)
+			}
+
+			if rawIdmapRangesOverlap(entry.nsBase, entry.size, other.nsBase, other.size) {
+				return fmt.Errorf("Invalid ID map: Namespace IDs on lines %d and %d overlap", other.line, entry.line)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return nil
+}
+
+// MemoryLimitMin is the smallest value a percentage based memory limit may resolve to.
+const MemoryLimitMin = 1024 * 1024
+
+// validateMemoryLimit validates a memory limit, either a percentage of the host's memory or a size in bytes.
+func validateMemoryLimit(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	if strings.HasSuffix(value, "%") {
+		num, err := strconv.ParseInt(strings.TrimSuffix(value, "%"), 10, 64)
+		if err != nil {
+			return err
+		}
+
+		if num == 0 {
+			return errors.New("Memory limit can't be 0%")
+		}
+
+		return nil
+	}
+
+	num, err := units.ParseByteSizeString(value)
+	if err != nil {
+		return err
+	}
+
+	if num == 0 {
+		return fmt.Errorf("Memory limit can't be 0")
+	}
+
+	return nil
+}
+
+// ValidateMemoryLimit validates a memory limit against the host's total memory.
+// On top of the checks done for the limits.memory key, percentages must resolve to at least MemoryLimitMin.
+func ValidateMemoryLimit(value string, hostTotal int64) error {
+	err := validateMemoryLimit(value)
+	if err != nil {
+		return err
+	}
+
+	percent, isPercent := strings.CutSuffix(value, "%")
+	if !isPercent {
+		return nil
+	}
+
+	num, err := strconv.ParseInt(percent, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	limit := (hostTotal / 100) * num
+	if limit < MemoryLimitMin {
+		return fmt.Errorf("Memory limit of %s resolves to %s which is below the minimum of %s", value, units.GetByteSizeStringIEC(limit, 2), units.GetByteSizeStringIEC(MemoryLimitMin, 2))
+	}
+
+	return nil
+}
+
+// validateHugePageLimit returns a validator checking that a huge page limit is a whole number of pages of pageSize
+// bytes. Huge page sizes are binary sizes, so a 2MB page is 2MiB.
+func validateHugePageLimit(pageSize int64) func(value string) error {
+	return func(value string) error {
+		limit, err := units.ParseByteSizeString(value)
+		if err != nil {
+			return err
+		}
+
+		if limit%pageSize != 0 {
+			return fmt.Errorf("Huge page limit must be a multiple of the %s page size", units.GetByteSizeStringIEC(pageSize, 0))
+		}
+
+		return nil
+	}
+}
+
+// validateRawApparmor performs a light check of raw.apparmor entries, without parsing the AppArmor grammar.
+// It catches unbalanced braces, which would otherwise only be reported by the AppArmor parser when the
+// instance starts. Includes are checked by the server when the profile is generated.
+func validateRawApparmor(value string) error {
+	depth := 0
+	for i, line := range strings.Split(value, "\n") {
+		// Strip comments.
+		line, _, _ = strings.Cut(line, "#")
+
+		for _, c := range line {
+			switch c {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth < 0 {
+					return fmt.Errorf("Unexpected closing brace on line %d", i+1)
+				}
+			}
+		}
+	}
+
+	if depth > 0 {
+		return fmt.Errorf("Unbalanced braces, %d block(s) not closed", depth)
+	}
+
+	return nil
+}
+
+// ParseCommand splits a shell-quoted command line into its arguments.
+// The command must have at least one argument and can't contain NUL bytes, which can't be passed to exec.
+func ParseCommand(value string) ([]string, error) {
+	if strings.ContainsRune(value, 0) {
+		return nil, fmt.Errorf("Command can't contain NUL bytes")
+	}
+
+	args, err := shellquote.Split(value)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid command %q: %w", value, err)
+	}
+
+	if len(args) == 0 {
+		return nil, fmt.Errorf("Command can't be empty")
+	}
+
+	return args, nil
+}
+
+// validateEnvironmentKey validates that an environment.* config key names an environment variable which can be set.
+// Only names which can't be represented in the environment are rejected, as existing configs may use names which
+// aren't valid shell identifiers (like "MY-VAR").
+func validateEnvironmentKey(key string) error {
+	name := strings.TrimPrefix(key, "environment.")
+	if name == "" {
+		return fmt.Errorf("Environment variable name can't be empty")
+	}
+
+	if strings.ContainsAny(name, "=\x00") || strings.ContainsFunc(name, unicode.IsSpace) {
+		return fmt.Errorf("Invalid environment variable name %q, must not contain \"=\", NUL or whitespace", name)
+	}
+
+	return nil
+}
+
+// rawQemuConfSection matches a raw.qemu.conf section header, optionally followed by an index.
+var rawQemuConfSection = regexp.MustCompile(`^\[[^\]]+\](?:\[(\d+)\])?$`)
+
+// rawQemuConfEntry matches a raw.qemu.conf "key = value" entry with an optionally quoted value.
+var rawQemuConfEntry = regexp.MustCompile(`^[^=\s"][^="]*=\s*("[^"]*"|[^"]*)$`)
+
+// validateRawQemuConf validates the structure of a raw.qemu.conf override.
+// It must be made of "[section]" headers, each followed by "key = value" entries. The keys themselves aren't checked.
+// Comments aren't supported.
+func validateRawQemuConf(value string) error {
+	inSection := false
+	for i, line := range strings.Split(value, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			return fmt.Errorf("Comments aren't supported, found one on line %d", i+1)
+		}
+
+		if strings.HasPrefix(line, "[") {
+			match := rawQemuConfSection.FindStringSubmatch(line)
+			if match == nil {
+				return fmt.Errorf("Invalid section header on line %d: %q", i+1, line)
+			}
+
+			if match[1] != "" {
+				_, err := strconv.ParseInt(match[1], 10, 32)
+				if err != nil {
+					return fmt.Errorf("Invalid section index on line %d: %q (must be at most %d)", i+1, match[1], math.MaxInt32)
+				}
+			}
+
+			inSection = true
+			continue
+		}
+
+		if !rawQemuConfEntry.MatchString(line) {
+			return fmt.Errorf("Invalid entry on line %d: %q (expected \"key = value\")", i+1, line)
+		}
+
+		if !inSection {
+			return fmt.Errorf("Entry on line %d isn't part of a section", i+1)
+		}
+	}
+
+	return nil
+}
+
 // HugePageSizeKeys is a list of known hugepage size configuration keys.
 var HugePageSizeKeys = [...]string{"limits.hugepages.64KB", "limits.hugepages.1MB", "limits.hugepages.2MB", "limits.hugepages.1GB"}
 
@@ -63,6 +572,16 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What order to start the instances in
 	"boot.autostart.priority": validate.Optional(validate.IsInt64),
 
+	// gendoc:generate(entity=instance, group=boot, key=boot.autostart.requires)
+	// Comma-separated list of instances of the same project that must be started before this instance.
+	// Required instances are started first regardless of `boot.autostart.priority`, and the instance isn't
+	// automatically started if one of them isn't running. Dependency cycles are reported as errors.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  shortdesc: Instances to start before this instance
+	"boot.autostart.requires": validate.Optional(validate.IsListOf(validate.IsHostname)),
+
 	// gendoc:generate(entity=instance, group=boot, key=boot.stop.priority)
 	// The instance with the highest value is shut down first.
 	// ---
@@ -85,15 +604,17 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 
 	// gendoc:generate(entity=instance, group=boot, key=boot.host_shutdown_timeout)
 	// Number of seconds to wait for the instance to shut down before it is force-stopped.
+	// Values above 3600 (one hour) are capped to one hour.
 	// ---
 	//  type: integer
 	//  defaultdesc: 30
 	//  liveupdate: yes
 	//  shortdesc: How long to wait for the instance to shut down
-	"boot.host_shutdown_timeout": validate.Optional(validate.IsInt64),
+	"boot.host_shutdown_timeout": validate.Optional(validateHostShutdownTimeout),
 
 	// gendoc:generate(entity=instance, group=cloud-init, key=cloud-init.network-config)
 	// The content is used as seed value for `cloud-init`.
+	// It must be a version 1 or version 2 network configuration, a warning is logged when the version can't be recognized.
 	// ---
 	//  type: string
 	//  defaultdesc: `DHCP on eth0`
@@ -102,6 +623,16 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Network configuration for `cloud-init`
 	"cloud-init.network-config": validate.Optional(validate.IsYAML),
 
+	// gendoc:generate(entity=instance, group=cloud-init, key=cloud-init.ssh-keys)
+	// Specify one OpenSSH public key per line.
+	// The keys are appended to the `ssh_authorized_keys` of the user data (or of the vendor data if the user data isn't a `#cloud-config` document).
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: If supported by image
+	//  shortdesc: SSH public keys for `cloud-init`
+	"cloud-init.ssh-keys": validate.Optional(validateSSHKeys),
+
 	// gendoc:generate(entity=instance, group=cloud-init, key=cloud-init.user-data)
 	// The content is used as seed value for `cloud-init`.
 	// ---
@@ -191,6 +722,7 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu.nodes)
 	// A comma-separated list of NUMA node IDs or ranges to place the instance CPUs on.
 	// Alternatively, the value `balanced` may be used to have Incus pick the least busy NUMA node on startup.
+	// The NUMA nodes must exist on the host the instance runs on.
 	//
 	// See {ref}`instance-options-limits-cpu-container` for more information.
 	// ---
@@ -203,6 +735,7 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	// Controls how much priority to give to the instance's I/O requests when under load.
 	//
 	// Specify an integer between 0 and 10.
+	// Disk devices can override it for their own block devices through their `limits.priority` option.
 	// ---
 	//  type: integer
 	//  defaultdesc: `5` (medium)
@@ -220,35 +753,7 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  defaultdesc: `1GiB` (VMs)
 	//  liveupdate: yes
 	//  shortdesc: Usage limit for the host's memory
-	"limits.memory": func(value string) error {
-		if value == "" {
-			return nil
-		}
-
-		if strings.HasSuffix(value, "%") {
-			num, err := strconv.ParseInt(strings.TrimSuffix(value, "%"), 10, 64)
-			if err != nil {
-				return err
-			}
-
-			if num == 0 {
-				return errors.New("Memory limit can't be 0%")
-			}
-
-			return nil
-		}
-
-		num, err := units.ParseByteSizeString(value)
-		if err != nil {
-			return err
-		}
-
-		if num == 0 {
-			return fmt.Errorf("Memory limit can't be 0")
-		}
-
-		return nil
-	},
+	"limits.memory": validateMemoryLimit,
 
 	// gendoc:generate(entity=instance, group=migration, key=migration.stateful)
 	// Enabling this option prevents the use of some features that are incompatible with it.
@@ -263,20 +768,22 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 
 	// gendoc:generate(entity=instance, group=raw, key=raw.apparmor)
 	// The specified entries are appended to the generated profile.
+	// The entries are checked for unbalanced braces and, when the profile is generated, for includes of files that don't exist.
 	// ---
 	//  type: blob
 	//  liveupdate: yes
 	//  shortdesc: AppArmor profile entries
-	"raw.apparmor": validate.IsAny,
+	"raw.apparmor": validateRawApparmor,
 
 	// gendoc:generate(entity=instance, group=raw, key=raw.idmap)
-	// For example: `both 1000 1000`
+	// Each line is of the form `(uid|gid|both) <hostid> <nsid> [count]`, for example `both 1000 1000` or `uid 100000 0 65536`.
+	// Lines mapping the same kind of ID (`uid` or `gid`, with `both` counting as either) must not overlap on the host or in the container.
 	// ---
 	//  type: blob
 	//  liveupdate: no
 	//  condition: unprivileged container
 	//  shortdesc: Raw idmap configuration
-	"raw.idmap": validate.IsAny,
+	"raw.idmap": validateRawIdmap,
 
 	// gendoc:generate(entity=instance, group=security, key=security.guestapi)
 	// See {ref}`dev-incus` for more information.
@@ -296,10 +803,21 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Prevents the instance from being deleted
 	"security.protection.delete": validate.Optional(validate.IsBool),
 
+	// gendoc:generate(entity=instance, group=security, key=security.protection.start)
+	// When set, any attempt to start the instance fails, including automatic starts through `boot.autostart` or when restoring the last power state on daemon startup.
+	// Restarting the instance is refused too, before the instance is stopped.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  shortdesc: Prevents the instance from being started
+	"security.protection.start": validate.Optional(validate.IsBool),
+
 	// gendoc:generate(entity=instance, group=snapshots, key=snapshots.schedule)
 	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-and-space-separated list of schedule aliases (`@startup`, `@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable automatic snapshots.
 	//
-	// Note that unlike most other configuration keys, this one must be comma-and-space-separated and not just comma-separated as cron expression can themselves contain commas.
+	// Note that unlike most other configuration keys, multiple cron expressions must be comma-and-space-separated and not just comma-separated as cron expression can themselves contain commas.
+	// Aliases and cron expressions can't be mixed, except for `@startup` and `@never` which can be combined with cron expressions.
 	//
 	// ---
 	//  type: string
@@ -317,6 +835,19 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Whether to automatically snapshot stopped instances
 	"snapshots.schedule.stopped": validate.Optional(validate.IsBool),
 
+	// gendoc:generate(entity=instance, group=snapshots, key=snapshots.schedule.maximum)
+	// Specify the maximum number of snapshots to keep, or leave empty for no limit.
+	// After each scheduled snapshot, the oldest scheduled snapshots of the instance beyond this number are deleted.
+	// Manually created snapshots are neither counted nor deleted, and neither are snapshots created before this key was
+	// introduced, as they can't be told apart from manual ones.
+	// This applies on top of `snapshots.expiry`, snapshots are deleted by whichever limit is reached first.
+	// ---
+	//  type: integer
+	//  defaultdesc: empty
+	//  liveupdate: no
+	//  shortdesc: Maximum number of snapshots to keep
+	"snapshots.schedule.maximum": validate.Optional(validate.IsInRange(1, math.MaxInt32)),
+
 	// gendoc:generate(entity=instance, group=snapshots, key=snapshots.pattern)
 	// Specify a Pongo2 template string that represents the snapshot name.
 	// This template is used for scheduled snapshots and for unnamed snapshots.
@@ -331,6 +862,7 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 
 	// gendoc:generate(entity=instance, group=snapshots, key=snapshots.expiry)
 	// Specify an expression like `1M 2H 3d 4w 5m 6y`.
+	// Leave empty or set to `0` or `never` for snapshots to never expire.
 	// ---
 	//  type: string
 	//  liveupdate: no
@@ -372,6 +904,15 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: The original cluster group for the instance
 	"volatile.cluster.group": validate.IsAny,
 
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.cpu.limit.clamped)
+	// Set to `true` when the CPU count requested through `limits.cpu` exceeds the number of available host CPUs
+	// and had to be reduced.
+	// It is cleared when the instance stops or when its CPU count no longer needs to be reduced.
+	// ---
+	//  type: bool
+	//  shortdesc: Whether the instance CPU count was clamped
+	"volatile.cpu.limit.clamped": validate.Optional(validate.IsBool),
+
 	// gendoc:generate(entity=instance, group=volatile, key=volatile.cpu.nodes)
 	// The NUMA node that was selected for the instance.
 	// ---
@@ -407,6 +948,14 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Timestamp of last move by automatic live-migration
 	"volatile.rebalance.last_move": validate.Optional(validate.IsInt64),
 
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.snapshot.scheduled)
+	// Set to `true` on snapshots created by `snapshots.schedule`, including `@startup` snapshots.
+	// Only those snapshots are subject to `snapshots.schedule.maximum`, so scheduled snapshots created by older versions aren't.
+	// ---
+	//  type: bool
+	//  shortdesc: Whether the snapshot was created by the schedule
+	"volatile.snapshot.scheduled": validate.Optional(validate.IsBool),
+
 	// gendoc:generate(entity=instance, group=volatile, key=volatile.uuid)
 	// The instance UUID is globally unique across all servers and projects.
 	// ---
@@ -470,6 +1019,31 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 		return nil
 	},
 
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu.isolated)
+	// When `limits.cpu` pins the container to specific CPUs, prevent the load-balancer from assigning those CPUs
+	// to containers that set `limits.cpu` to a number of CPUs.
+	// Balanced containers only fall back to isolated CPUs when no other CPU is available.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  condition: container
+	//  shortdesc: Whether to keep the pinned CPUs away from load-balanced containers
+	"limits.cpu.isolated": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu.policy)
+	// How the load-balancer picks CPUs when `limits.cpu` is set to a number of CPUs.
+	// `spread` prefers CPU threads on the least busy cores, while `pack` fills the threads of a core before moving on to the next one.
+	//
+	// See {ref}`instance-options-limits-cpu` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `spread`
+	//  liveupdate: yes
+	//  condition: container
+	//  shortdesc: CPU load-balancing policy
+	"limits.cpu.policy": validate.Optional(validate.IsOneOf("spread", "pack")),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu.priority)
 	// When overcommitting resources, specify the CPU scheduling priority compared to other instances that share the same CPUs.
 	// Specify an integer between 0 and 10.
@@ -483,6 +1057,18 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: CPU scheduling priority compared to other instances
 	"limits.cpu.priority": validate.Optional(validate.IsPriority),
 
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu.weight)
+	// Specify the CPU weight of the instance directly, as an integer between 1 and 10000 (the cgroup2 `cpu.weight` range, where 100 is the default).
+	// When set, it takes precedence over both `limits.cpu.priority` and a percentage `limits.cpu.allowance`.
+	//
+	// See {ref}`instance-options-limits-cpu-container` for more information.
+	// ---
+	//  type: integer
+	//  liveupdate: yes
+	//  condition: container
+	//  shortdesc: CPU scheduling weight of the instance
+	"limits.cpu.weight": validate.Optional(validate.IsInRange(1, 10000)),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.hugepages.64KB)
 	// Fixed value (in bytes) to limit the number of 64 KB huge pages.
 	// Various suffixes are supported (see {ref}`instances-limit-units`).
@@ -493,7 +1079,7 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  liveupdate: yes
 	//  condition: container
 	//  shortdesc: Limit for the number of 64 KB huge pages
-	"limits.hugepages.64KB": validate.Optional(validate.IsSize),
+	"limits.hugepages.64KB": validate.Optional(validateHugePageLimit(64 * 1024)),
 
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.hugepages.1MB)
 	// Fixed value (in bytes) to limit the number of 1 MB huge pages.
@@ -505,7 +1091,7 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  liveupdate: yes
 	//  condition: container
 	//  shortdesc: Limit for the number of 1 MB huge pages
-	"limits.hugepages.1MB": validate.Optional(validate.IsSize),
+	"limits.hugepages.1MB": validate.Optional(validateHugePageLimit(1024 * 1024)),
 
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.hugepages.2MB)
 	// Fixed value (in bytes) to limit the number of 2 MB huge pages.
@@ -517,7 +1103,7 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  liveupdate: yes
 	//  condition: container
 	//  shortdesc: Limit for the number of 2 MB huge pages
-	"limits.hugepages.2MB": validate.Optional(validate.IsSize),
+	"limits.hugepages.2MB": validate.Optional(validateHugePageLimit(2 * 1024 * 1024)),
 
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.hugepages.1GB)
 	// Fixed value (in bytes) to limit the number of 1 GB huge pages.
@@ -529,7 +1115,7 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  liveupdate: yes
 	//  condition: container
 	//  shortdesc: Limit for the number of 1 GB huge pages
-	"limits.hugepages.1GB": validate.Optional(validate.IsSize),
+	"limits.hugepages.1GB": validate.Optional(validateHugePageLimit(1024 * 1024 * 1024)),
 
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.enforce)
 	// If the instance's memory limit is `hard`, the instance cannot exceed its limit.
@@ -542,10 +1128,23 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: Whether the memory limit is `hard` or `soft`
 	"limits.memory.enforce": validate.Optional(validate.IsOneOf("soft", "hard")),
 
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.oom_score_adj)
+	// Specify an integer between -1000 and 1000.
+	// The value is applied to the container's init process when it starts and is inherited by its children.
+	// The higher the value, the more likely the container's processes are to be killed when the host runs out of memory.
+	// Negative values are forbidden in projects restricting low-level container options.
+	// ---
+	//  type: integer
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: OOM killer score adjustment of the container's processes
+	"limits.memory.oom_score_adj": validate.Optional(validate.IsInRange(-1000, 1000)),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.swap)
 	// When set to `true` or `false`, it controls whether the container is likely to get some of
 	// its memory swapped by the kernel. Alternatively, it can be set to a bytes value which will
 	// then allow the container to make use of additional memory through swap.
+	// A bytes value is refused when creating or updating the container on a host without any swap.
 	// ---
 	//  type: string
 	//  defaultdesc: `true`
@@ -562,18 +1161,31 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  defaultdesc: `10` (maximum)
 	//  liveupdate: yes
 	//  condition: container
-	//  shortdesc: Prevents the instance from being swapped to disk
-	"limits.memory.swap.priority": validate.Optional(validate.IsPriority),
+	//  shortdesc: Prevents the instance from being swapped to disk
+	"limits.memory.swap.priority": validate.Optional(validate.IsPriority),
+
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.nofile)
+	// This is a shortcut for setting both the soft and hard `RLIMIT_NOFILE` of the container processes.
+	// It can't be combined with `limits.kernel.nofile` in the same instance or profile, the latter should be used for
+	// distinct soft and hard limits. When one is set on the instance and the other in a profile, the instance one applies.
+	// ---
+	//  type: integer
+	//  defaultdesc: empty
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Maximum number of open files for the instance processes
+	"limits.nofile": validate.Optional(validate.IsInRange(1, math.MaxInt32)),
 
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.processes)
 	// If left empty, no limit is set.
+	// The value must be at least `1`. Values above the kernel's maximum PID count (4194304) are capped to it.
 	// ---
 	//  type: integer
 	//  defaultdesc: empty
 	//  liveupdate: yes
 	//  condition: container
 	//  shortdesc: Maximum number of processes that can run in the instance
-	"limits.processes": validate.Optional(validate.IsInt64),
+	"limits.processes": validate.Optional(validateProcessesLimit),
 
 	// gendoc:generate(entity=instance, group=miscellaneous, key=linux.kernel_modules)
 	// Specify the kernel modules as a comma-separated list.
@@ -582,7 +1194,21 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  liveupdate: yes
 	//  condition: container
 	//  shortdesc: Kernel modules to load before starting the instance
-	"linux.kernel_modules": validate.IsAny,
+	"linux.kernel_modules": validate.Optional(validate.IsListOf(validate.IsKernelModuleName)),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=oci.command)
+	// Specify a shell-quoted command line (for example, `/usr/bin/myapp --verbose`) to run instead of the
+	// OCI image the container was created from. It replaces both the `ENTRYPOINT` and the `CMD` of the image.
+	// Starting a container which wasn't created from an OCI image fails when this is set.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: OCI container
+	//  shortdesc: Override of the OCI container command line
+	"oci.command": validate.Optional(func(value string) error {
+		_, err := ParseCommand(value)
+		return err
+	}),
 
 	// gendoc:generate(entity=instance, group=migration, key=migration.incremental.memory)
 	// Using incremental memory transfer of the instance's memory can reduce downtime.
@@ -595,24 +1221,24 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	"migration.incremental.memory": validate.Optional(validate.IsBool),
 
 	// gendoc:generate(entity=instance, group=migration, key=migration.incremental.memory.iterations)
-	//
+	// The value must be between `1` and `100`.
 	// ---
 	//  type: integer
 	//  defaultdesc: `10`
 	//  liveupdate: yes
 	//  condition: container
 	//  shortdesc: Maximum number of transfer operations to go through before stopping the instance
-	"migration.incremental.memory.iterations": validate.Optional(validate.IsUint32),
+	"migration.incremental.memory.iterations": validate.Optional(validate.IsInRange(1, 100)),
 
 	// gendoc:generate(entity=instance, group=migration, key=migration.incremental.memory.goal)
-	//
+	// The value must be between `0` and `100`.
 	// ---
 	//  type: integer
 	//  defaultdesc: `70`
 	//  liveupdate: yes
 	//  condition: container
 	//  shortdesc: Percentage of memory to have in sync before stopping the instance
-	"migration.incremental.memory.goal": validate.Optional(validate.IsUint32),
+	"migration.incremental.memory.goal": validate.Optional(validate.IsInRange(0, 100)),
 
 	// gendoc:generate(entity=instance, group=nvidia, key=nvidia.runtime)
 	//
@@ -626,13 +1252,14 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 
 	// gendoc:generate(entity=instance, group=nvidia, key=nvidia.driver.capabilities)
 	// The specified driver capabilities are used to set `libnvidia-container NVIDIA_DRIVER_CAPABILITIES`.
+	// Specify a comma-separated list of `compute`, `compat32`, `graphics`, `utility`, `video`, `display` and `ngx`, or `all` on its own.
 	// ---
 	//  type: string
 	//  defaultdesc: `compute,utility`
 	//  liveupdate: no
 	//  condition: container
 	//  shortdesc: What driver capabilities the instance needs
-	"nvidia.driver.capabilities": validate.IsAny,
+	"nvidia.driver.capabilities": validateNvidiaDriverCapabilities,
 
 	// gendoc:generate(entity=instance, group=nvidia, key=nvidia.require.cuda)
 	// The specified version expression is used to set `libnvidia-container NVIDIA_REQUIRE_CUDA`.
@@ -702,13 +1329,15 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	"security.idmap.isolated": validate.Optional(validate.IsBool),
 
 	// gendoc:generate(entity=instance, group=security, key=security.idmap.size)
-	//
+	// Set to `auto` to size the idmap to cover the highest user and group IDs defined in the container's
+	// `/etc/passwd` and `/etc/group` (with a minimum of 65536, up to the size of the host idmap).
+	// The size is then computed on first start and recorded in `volatile.idmap.size`.
 	// ---
 	//  type: integer
 	//  liveupdate: no
 	//  condition: unprivileged container
 	//  shortdesc: The size of the idmap to use
-	"security.idmap.size": validate.Optional(validate.IsUint32),
+	"security.idmap.size": validate.Optional(validate.Or(validate.IsOneOf("auto"), validate.IsUint32)),
 
 	// gendoc:generate(entity=instance, group=security, key=security.nesting)
 	//
@@ -743,12 +1372,15 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	// gendoc:generate(entity=instance, group=security, key=security.syscalls.allow)
 	// A `\n`-separated list of syscalls to allow.
 	// This list must be mutually exclusive with `security.syscalls.deny*`.
+	// Alternatively, a `file:///path/to/list` reference to a file holding the list can be used.
+	// The file must be readable when the key is set and is read again from the server running the instance when it starts.
+	// File references are forbidden in projects restricting low-level container options.
 	// ---
 	//  type: string
 	//  liveupdate: no
 	//  condition: container
 	//  shortdesc: List of syscalls to allow
-	"security.syscalls.allow": validate.IsAny,
+	"security.syscalls.allow": validateSyscallList,
 
 	// Legacy configuration keys (old names).
 	"security.syscalls.blacklist_default": validate.Optional(validate.IsBool),
@@ -779,12 +1411,15 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	// gendoc:generate(entity=instance, group=security, key=security.syscalls.deny)
 	// A `\n`-separated list of syscalls to deny.
 	// This list must be mutually exclusive with `security.syscalls.allow`.
+	// Alternatively, a `file:///path/to/list` reference to a file holding the list can be used.
+	// The file must be readable when the key is set and is read again from the server running the instance when it starts.
+	// File references are forbidden in projects restricting low-level container options.
 	// ---
 	//  type: string
 	//  liveupdate: no
 	//  condition: container
 	//  shortdesc: List of syscalls to deny
-	"security.syscalls.deny": validate.IsAny,
+	"security.syscalls.deny": validateSyscallList,
 
 	// gendoc:generate(entity=instance, group=security, key=security.syscalls.intercept.bpf)
 	//
@@ -816,6 +1451,17 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: Whether to handle the `mknod` and `mknodat` system calls
 	"security.syscalls.intercept.mknod": validate.Optional(validate.IsBool),
 
+	// gendoc:generate(entity=instance, group=security, key=security.syscalls.intercept.mknod.allowed)
+	// Comma-separated list of character devices, either as `<major>:<minor>` or as one of the names `console`, `full`, `null`, `random`, `tty`, `urandom` or `zero`.
+	// When set, only those devices (and overlay whiteouts) can be created, instead of the default set of standard devices.
+	// As any device can be listed, this is a low-level option which restricted projects only allow when {config:option}`project-restricted:restricted.containers.lowlevel` is set to `allow`.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  condition: container
+	//  shortdesc: Character devices that can be created through `mknod` interception
+	"security.syscalls.intercept.mknod.allowed": validate.Optional(validateMknodAllowed),
+
 	// gendoc:generate(entity=instance, group=security, key=security.syscalls.intercept.mount)
 	//
 	// ---
@@ -854,7 +1500,7 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: Whether to use idmapped mounts for syscall interception
 	"security.syscalls.intercept.mount.shift": validate.Optional(validate.IsBool),
 
-	// gendoc:generate(entity=instance, group=security, key=security.syscalls.intercept.sched_setcheduler)
+	// gendoc:generate(entity=instance, group=security, key=security.syscalls.intercept.sched_setscheduler)
 	// This system call allows increasing process priority.
 	// ---
 	//  type: bool
@@ -921,12 +1567,46 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  type: string
 	//  shortdesc: The idmap to use the next time the instance starts
 	"volatile.idmap.next": validate.IsAny,
+
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.idmap.size)
+	//
+	// ---
+	//  type: integer
+	//  shortdesc: The idmap size computed from the root filesystem
+	"volatile.idmap.size": validate.IsAny,
 }
 
 // InstanceConfigKeysVM is a map of config key to validator. (keys applying to VM only).
 var InstanceConfigKeysVM = map[string]func(value string) error{
+	// gendoc:generate(entity=instance, group=boot, key=boot.debug.edk2)
+	// When enabled, the debug build of the UEFI firmware (`OVMF_CODE.4MB.debug.fd`, next to the regular firmware) is used and its output is written to the `edk2.log` file of the instance.
+	// This makes the boot much more verbose and slower, so only enable it to investigate boot issues.
+	// It is only supported on x86_64 and can't be combined with `security.csm`.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Whether to use the debug build of the UEFI firmware and log its output
+	"boot.debug.edk2": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu.emulator)
+	// A comma-separated list of host CPU IDs or ranges to pin the QEMU emulator and I/O threads to.
+	// Unlike with `limits.cpu`, a single number is a CPU ID rather than a number of CPUs.
+	// This only applies to the threads that aren't running vCPUs, which are placed according to `limits.cpu`.
+	// To keep the emulator from competing with pinned vCPUs, pick CPUs that aren't part of `limits.cpu`.
+	// When unset, the emulator threads can run on any host CPU.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Which host CPUs to run the QEMU emulator threads on
+	"limits.cpu.emulator": validate.Optional(validate.Or(validate.IsValidCPUSet, validate.IsOneOf("0"))),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.hugepages)
 	// If this option is set to `false`, regular system memory is used.
+	// The instance fails to start if its memory limit can't be allocated from the free huge pages of the host.
+	// When `limits.cpu.nodes` is set, the huge pages are allocated from those NUMA nodes only.
 	// ---
 	//  type: bool
 	//  defaultdesc: `false`
@@ -953,7 +1633,7 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  liveupdate: no
 	//  condition: virtual machine
 	//  shortdesc: Addition/override to the generated `qemu.conf` file
-	"raw.qemu.conf": validate.IsAny,
+	"raw.qemu.conf": validateRawQemuConf,
 
 	// gendoc:generate(entity=instance, group=raw, key=raw.qemu.qmp.early)
 	//
@@ -1045,21 +1725,19 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//
 	// ---
 	//  type: string
-	//  defaultdesc: `true`
 	//  liveupdate: no
 	//  condition: virtual machine
 	//  shortdesc: The guest owner's `base64`-encoded Diffie-Hellman key
-	"security.sev.session.dh": validate.Optional(validate.IsAny),
+	"security.sev.session.dh": validate.Optional(validate.IsBase64),
 
 	// gendoc:generate(entity=instance, group=security, key=security.sev.session.data)
 	//
 	// ---
 	//  type: string
-	//  defaultdesc: `true`
 	//  liveupdate: no
 	//  condition: virtual machine
 	//  shortdesc: The guest owner's `base64`-encoded session blob
-	"security.sev.session.data": validate.Optional(validate.IsAny),
+	"security.sev.session.data": validate.Optional(validate.IsBase64),
 
 	// gendoc:generate(entity=instance, group=miscellaneous, key=user.*)
 	// User keys can be used in search.
@@ -1071,6 +1749,8 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	// gendoc:generate(entity=instance, group=miscellaneous, key=agent.nic_config)
 	// For containers, the name and MTU of the default network interfaces is used for the instance devices.
 	// For virtual machines, set this option to `true` to set the name and MTU of the default network interfaces to be the same as the instance devices.
+	// This is applied by the `incus-agent` when the virtual machine boots, so it requires the agent to be installed in the guest.
+	// It doesn't depend on `security.agent.metrics`, which only controls whether Incus queries the agent for state information.
 	// ---
 	//  type: bool
 	//  defaultdesc: `false`
@@ -1094,6 +1774,25 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	"volatile.vsock_id": validate.Optional(validate.IsInt64),
 }
 
+// ErrUnknownConfigKey is returned by ConfigKeyChecker when the configuration key isn't known.
+var ErrUnknownConfigKey = errors.New("Unknown configuration key")
+
+// UnknownConfigKeyError is the error returned by ConfigKeyChecker for a configuration key that isn't known.
+// It matches ErrUnknownConfigKey with errors.Is and carries the offending key for use with errors.As.
+type UnknownConfigKeyError struct {
+	Key string
+}
+
+// Error returns the error message including the unknown key.
+func (e UnknownConfigKeyError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrUnknownConfigKey, e.Key)
+}
+
+// Unwrap returns ErrUnknownConfigKey.
+func (e UnknownConfigKeyError) Unwrap() error {
+	return ErrUnknownConfigKey
+}
+
 // ConfigKeyChecker returns a function that will check whether or not
 // a provide value is valid for the associate config key.  Returns an
 // error if the key is not known.  The checker function only performs
@@ -1120,7 +1819,7 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		}
 	}
 
-	if strings.HasPrefix(key, ConfigVolatilePrefix) {
+	if IsVolatileConfig(key) {
 		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.apply_quota)
 		// The disk quota is applied the next time the instance starts.
 		// ---
@@ -1130,6 +1829,24 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 			return validate.IsAny, nil
 		}
 
+		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.source_quota)
+		// The size quota (in bytes) currently applied to the source of a bind-mounted disk device.
+		// ---
+		//  type: integer
+		//  shortdesc: Disk source quota
+		if strings.HasSuffix(key, ".source_quota") {
+			return validate.Optional(validate.IsInt64), nil
+		}
+
+		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.source_quota_id)
+		// The filesystem project quota ID allocated to the source of a bind-mounted disk device.
+		// ---
+		//  type: integer
+		//  shortdesc: Disk source quota project ID
+		if strings.HasSuffix(key, ".source_quota_id") {
+			return validate.Optional(validate.IsUint32), nil
+		}
+
 		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.ceph_rbd)
 		//
 		// ---
@@ -1184,6 +1901,15 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 			return validate.IsAny, nil
 		}
 
+		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.last_state.bond.slaves)
+		// The comma-separated list of parent devices added to the bond created for a `physical` NIC with multiple parents.
+		// ---
+		//  type: string
+		//  shortdesc: Network device bond slaves
+		if strings.HasSuffix(key, ".last_state.bond.slaves") {
+			return validate.IsAny, nil
+		}
+
 		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.last_state.created)
 		// Possible values are `true` or `false`.
 		// ---
@@ -1301,6 +2027,15 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 			return validate.IsAny, nil
 		}
 
+		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.last_state.vf.pci)
+		// The original PCI address of a VF moved into an instance.
+		// ---
+		//  type: string
+		//  shortdesc: SR-IOV virtual function original PCI address
+		if strings.HasSuffix(key, ".last_state.vf.pci") {
+			return validate.Optional(validate.IsPCIAddress), nil
+		}
+
 		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.last_state.vf.spoofcheck)
 		// The original spoof check setting used when moving a VF into an instance.
 		// ---
@@ -1318,17 +2053,40 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		if strings.HasSuffix(key, ".last_state.vf.vlan") {
 			return validate.IsAny, nil
 		}
+
+		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.mtu)
+		// The effective MTU of a `macvlan`, `ipvlan` or `sriov` NIC, either configured or inherited from its parent.
+		// ---
+		//  type: string
+		//  shortdesc: Network device effective MTU
+		if strings.HasSuffix(key, ".mtu") {
+			return validate.Optional(validate.IsUint32), nil
+		}
+
+		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.vlan)
+		// The effective VLAN of a `macvlan`, `ipvlan` or `sriov` NIC, either configured or inherited from its network.
+		// ---
+		//  type: string
+		//  shortdesc: Network device effective VLAN
+		if strings.HasSuffix(key, ".vlan") {
+			return validate.Optional(validate.IsNetworkVLAN), nil
+		}
 	}
 
-	if strings.HasPrefix(key, "environment.") {
+	if IsEnvironmentConfig(key) {
+		err := validateEnvironmentKey(key)
+		if err != nil {
+			return nil, err
+		}
+
 		return validate.IsAny, nil
 	}
 
-	if strings.HasPrefix(key, "user.") {
+	if IsUserConfig(key) {
 		return validate.IsAny, nil
 	}
 
-	if strings.HasPrefix(key, "image.") {
+	if IsImageConfig(key) {
 		return validate.IsAny, nil
 	}
 
@@ -1339,8 +2097,8 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		//  type: string
 		//  resource: `RLIMIT_AS`
 		//  shortdesc: Maximum size of the process's virtual memory
-		if strings.HasSuffix(key, ".as") {
-			return validate.IsAny, nil
+		if key == "limits.kernel.as" {
+			return validate.Optional(validatePrlimit), nil
 		}
 
 		// gendoc:generate(entity=kernel, group=limits, key=limits.kernel.core)
@@ -1349,8 +2107,8 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		//  type: string
 		//  resource: `RLIMIT_CORE`
 		//  shortdesc: Maximum size of the process's core dump file
-		if strings.HasSuffix(key, ".core") {
-			return validate.IsAny, nil
+		if key == "limits.kernel.core" {
+			return validate.Optional(validatePrlimit), nil
 		}
 
 		// gendoc:generate(entity=kernel, group=limits, key=limits.kernel.cpu)
@@ -1359,8 +2117,8 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		//  type: string
 		//  resource: `RLIMIT_CPU`
 		//  shortdesc: Limit in seconds on the amount of CPU time the process can consume
-		if strings.HasSuffix(key, ".cpu") {
-			return validate.IsAny, nil
+		if key == "limits.kernel.cpu" {
+			return validate.Optional(validatePrlimit), nil
 		}
 
 		// gendoc:generate(entity=kernel, group=limits, key=limits.kernel.data)
@@ -1369,8 +2127,8 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		//  type: string
 		//  resource: `RLIMIT_DATA`
 		//  shortdesc: Maximum size of the process's data segment
-		if strings.HasSuffix(key, ".data") {
-			return validate.IsAny, nil
+		if key == "limits.kernel.data" {
+			return validate.Optional(validatePrlimit), nil
 		}
 
 		// gendoc:generate(entity=kernel, group=limits, key=limits.kernel.fsize)
@@ -1379,8 +2137,8 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		//  type: string
 		//  resource: `RLIMIT_FSIZE`
 		//  shortdesc: Maximum size of files the process may create
-		if strings.HasSuffix(key, ".fsize") {
-			return validate.IsAny, nil
+		if key == "limits.kernel.fsize" {
+			return validate.Optional(validatePrlimit), nil
 		}
 
 		// gendoc:generate(entity=kernel, group=limits, key=limits.kernel.locks)
@@ -1389,8 +2147,8 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		//  type: string
 		//  resource: `RLIMIT_LOCKS`
 		//  shortdesc: Limit on the number of file locks that this process may establish
-		if strings.HasSuffix(key, ".locks") {
-			return validate.IsAny, nil
+		if key == "limits.kernel.locks" {
+			return validate.Optional(validatePrlimit), nil
 		}
 
 		// gendoc:generate(entity=kernel, group=limits, key=limits.kernel.memlock)
@@ -1399,8 +2157,18 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		//  type: string
 		//  resource: `RLIMIT_MEMLOCK`
 		//  shortdesc: Limit on the number of bytes of memory that the process may lock in RAM
-		if strings.HasSuffix(key, ".memlock") {
-			return validate.IsAny, nil
+		if key == "limits.kernel.memlock" {
+			return validate.Optional(validatePrlimit), nil
+		}
+
+		// gendoc:generate(entity=kernel, group=limits, key=limits.kernel.msgqueue)
+		//
+		// ---
+		//  type: string
+		//  resource: `RLIMIT_MSGQUEUE`
+		//  shortdesc: Maximum number of bytes that can be allocated for POSIX message queues
+		if key == "limits.kernel.msgqueue" {
+			return validate.Optional(validatePrlimit), nil
 		}
 
 		// gendoc:generate(entity=kernel, group=limits, key=limits.kernel.nice)
@@ -1409,8 +2177,8 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		//  type: string
 		//  resource: `RLIMIT_NICE`
 		//  shortdesc: Maximum value to which the process's nice value can be raised
-		if strings.HasSuffix(key, ".nice") {
-			return validate.IsAny, nil
+		if key == "limits.kernel.nice" {
+			return validate.Optional(validatePrlimit), nil
 		}
 
 		// gendoc:generate(entity=kernel, group=limits, key=limits.kernel.nofile)
@@ -1419,8 +2187,8 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		//  type: string
 		//  resource: `RLIMIT_NOFILE`
 		//  shortdesc: Maximum number of open files for the process
-		if strings.HasSuffix(key, ".nofile") {
-			return validate.IsAny, nil
+		if key == "limits.kernel.nofile" {
+			return validate.Optional(validatePrlimit), nil
 		}
 
 		// gendoc:generate(entity=kernel, group=limits, key=limits.kernel.nproc)
@@ -1429,8 +2197,18 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		//  type: string
 		//  resource: `RLIMIT_NPROC`
 		//  shortdesc: Maximum number of processes that can be created for the user of the calling process
-		if strings.HasSuffix(key, ".nproc") {
-			return validate.IsAny, nil
+		if key == "limits.kernel.nproc" {
+			return validate.Optional(validatePrlimit), nil
+		}
+
+		// gendoc:generate(entity=kernel, group=limits, key=limits.kernel.rss)
+		//
+		// ---
+		//  type: string
+		//  resource: `RLIMIT_RSS`
+		//  shortdesc: Maximum size of the process's resident set
+		if key == "limits.kernel.rss" {
+			return validate.Optional(validatePrlimit), nil
 		}
 
 		// gendoc:generate(entity=kernel, group=limits, key=limits.kernel.rtprio)
@@ -1439,8 +2217,18 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		//  type: string
 		//  resource: `RLIMIT_RTPRIO`
 		//  shortdesc: Maximum value on the real-time-priority that may be set for this process
-		if strings.HasSuffix(key, ".rtprio") {
-			return validate.IsAny, nil
+		if key == "limits.kernel.rtprio" {
+			return validate.Optional(validatePrlimit), nil
+		}
+
+		// gendoc:generate(entity=kernel, group=limits, key=limits.kernel.rttime)
+		//
+		// ---
+		//  type: string
+		//  resource: `RLIMIT_RTTIME`
+		//  shortdesc: Limit in microseconds on the amount of CPU time that a process scheduled under a real-time scheduling policy may consume without making a blocking system call
+		if key == "limits.kernel.rttime" {
+			return validate.Optional(validatePrlimit), nil
 		}
 
 		// gendoc:generate(entity=kernel, group=limits, key=limits.kernel.sigpending)
@@ -1448,13 +2236,19 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		// ---
 		//  type: string
 		//  resource: `RLIMIT_SIGPENDING`
-		//  shortdesc: Limit on the number of bytes of memory that the process may lock in RAM
-		if strings.HasSuffix(key, ".sigpending") {
-			return validate.IsAny, nil
+		//  shortdesc: Limit on the number of signals that may be queued for the real user ID of the calling process
+		if key == "limits.kernel.sigpending" {
+			return validate.Optional(validatePrlimit), nil
 		}
 
-		if len(key) > len("limits.kernel.") {
-			return validate.IsAny, nil
+		// gendoc:generate(entity=kernel, group=limits, key=limits.kernel.stack)
+		//
+		// ---
+		//  type: string
+		//  resource: `RLIMIT_STACK`
+		//  shortdesc: Maximum size of the process stack
+		if key == "limits.kernel.stack" {
+			return validate.Optional(validatePrlimit), nil
 		}
 	}
 
@@ -1463,12 +2257,208 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		return validate.IsAny, nil
 	}
 
-	return nil, fmt.Errorf("Unknown configuration key: %s", key)
+	return nil, UnknownConfigKeyError{Key: key}
+}
+
+// deprecatedConfigKeys maps the deprecated instance config keys to the keys replacing them.
+var deprecatedConfigKeys = map[string]string{
+	"security.syscalls.blacklist":         "security.syscalls.deny",
+	"security.syscalls.blacklist_compat":  "security.syscalls.deny_compat",
+	"security.syscalls.blacklist_default": "security.syscalls.deny_default",
+	"security.syscalls.whitelist":         "security.syscalls.allow",
+}
+
+// IsDeprecatedConfigKey returns whether the instance config key is deprecated, along with the key replacing it.
+func IsDeprecatedConfigKey(key string) (string, bool) {
+	newKey, ok := deprecatedConfigKeys[key]
+
+	return newKey, ok
+}
+
+// ConfigKeyCheckerLenient is the same as ConfigKeyChecker except that unknown keys aren't treated as an error.
+// Instead, a checker accepting any value is returned along with true to indicate the key is unknown, leaving it
+// to the caller to decide what to do with it (e.g. warn and keep keys coming from a newer server).
+func ConfigKeyCheckerLenient(key string, instanceType api.InstanceType) (func(value string) error, bool, error) {
+	checker, err := ConfigKeyChecker(key, instanceType)
+	if err != nil {
+		if errors.Is(err, ErrUnknownConfigKey) {
+			return validate.IsAny, true, nil
+		}
+
+		return nil, false, err
+	}
+
+	return checker, false, nil
+}
+
+// RegisterInstanceConfigKey adds a config key and its validator to the keys known for the given instance type.
+// Keys registered with api.InstanceTypeAny apply to all instance types.
+// This is meant to be called during initialization, before any config is validated, and fails if the key is
+// already handled by ConfigKeyChecker, whether as a built-in key or as part of a built-in namespace.
+func RegisterInstanceConfigKey(key string, instanceType api.InstanceType, validator func(value string) error) error {
+	if key == "" {
+		return fmt.Errorf("Config key name is required")
+	}
+
+	if validator == nil {
+		return fmt.Errorf("Validator for config key %q is required", key)
+	}
+
+	var keys map[string]func(value string) error
+	switch instanceType {
+	case api.InstanceTypeAny:
+		keys = InstanceConfigKeysAny
+	case api.InstanceTypeContainer:
+		keys = InstanceConfigKeysContainer
+	case api.InstanceTypeVM:
+		keys = InstanceConfigKeysVM
+	default:
+		return fmt.Errorf("Unknown instance type %q", instanceType)
+	}
+
+	_, err := ConfigKeyChecker(key, api.InstanceTypeAny)
+	if err == nil {
+		return fmt.Errorf("Config key %q already exists", key)
+	}
+
+	keys[key] = validator
+
+	return nil
+}
+
+// KeyInfo describes a known instance config key.
+type KeyInfo struct {
+	Key          string           `json:"key"`
+	InstanceType api.InstanceType `json:"instance_type"`
+}
+
+// ConfigKeyMetadata returns the known instance config keys sorted by name, along with the instance type they apply
+// to (api.InstanceTypeAny for keys valid on all instance types).
+// Keys validated by prefix, such as user.*, volatile.* or limits.kernel.*, aren't included.
+func ConfigKeyMetadata() []KeyInfo {
+	keys := make([]KeyInfo, 0, len(InstanceConfigKeysAny)+len(InstanceConfigKeysContainer)+len(InstanceConfigKeysVM))
+
+	for instanceType, instanceKeys := range map[api.InstanceType]map[string]func(value string) error{
+		api.InstanceTypeAny:       InstanceConfigKeysAny,
+		api.InstanceTypeContainer: InstanceConfigKeysContainer,
+		api.InstanceTypeVM:        InstanceConfigKeysVM,
+	} {
+		for key := range instanceKeys {
+			keys = append(keys, KeyInfo{Key: key, InstanceType: instanceType})
+		}
+	}
+
+	slices.SortFunc(keys, func(a KeyInfo, b KeyInfo) int {
+		return cmp.Or(strings.Compare(a.Key, b.Key), strings.Compare(string(a.InstanceType), string(b.InstanceType)))
+	})
+
+	return keys
+}
+
+// instanceConfigKeysRestart is the list of config keys whose changes only take effect when the instance restarts.
+// This must be kept in sync with the keys documented as not supporting live updates, other than the ones only used
+// when the related event occurs (like boot.autostart or snapshots.schedule). Keys ending with "*" cover all the keys
+// with that prefix.
+var instanceConfigKeysRestart = []string{
+	"agent.nic_config",
+	"boot.debug.edk2",
+	"cloud-init.network-config",
+	"cloud-init.ssh-keys",
+	"cloud-init.user-data",
+	"cloud-init.vendor-data",
+	"limits.cpu.emulator",
+	"limits.memory.hugepages",
+	"limits.memory.oom_score_adj",
+	"limits.nofile",
+	"linux.sysctl.*",
+	"migration.stateful",
+	"nvidia.driver.capabilities",
+	"nvidia.require.cuda",
+	"nvidia.require.driver",
+	"nvidia.runtime",
+	"oci.command",
+	"raw.idmap",
+	"raw.lxc",
+	"raw.qemu",
+	"raw.qemu.conf",
+	"raw.qemu.qmp.early",
+	"raw.qemu.qmp.post-start",
+	"raw.qemu.qmp.pre-start",
+	"raw.qemu.scriptlet",
+	"raw.seccomp",
+	"security.agent.metrics",
+	"security.csm",
+	"security.guestapi",
+	"security.guestapi.images",
+	"security.idmap.base",
+	"security.idmap.isolated",
+	"security.idmap.size",
+	"security.privileged",
+	"security.secureboot",
+	"security.sev",
+	"security.sev.policy.es",
+	"security.sev.session.data",
+	"security.sev.session.dh",
+	"security.syscalls.allow",
+	"security.syscalls.deny",
+	"security.syscalls.deny_compat",
+	"security.syscalls.deny_default",
+	"security.syscalls.intercept.bpf",
+	"security.syscalls.intercept.bpf.devices",
+	"security.syscalls.intercept.mknod",
+	"security.syscalls.intercept.mount",
+	"security.syscalls.intercept.sched_setscheduler",
+	"security.syscalls.intercept.setxattr",
+	"security.syscalls.intercept.sysinfo",
+	"user.network-config",
+	"user.user-data",
+	"user.vendor-data",
+}
+
+// ConfigKeyRequiresRestart returns whether changing the config key of a running instance only takes effect once the
+// instance is restarted. An error is returned if the key isn't valid for the instance type.
+func ConfigKeyRequiresRestart(key string, instanceType api.InstanceType) (bool, error) {
+	_, err := ConfigKeyChecker(key, instanceType)
+	if err != nil {
+		return false, err
+	}
+
+	for _, restartKey := range instanceConfigKeysRestart {
+		prefix, isPrefix := strings.CutSuffix(restartKey, "*")
+		if key == restartKey || (isPrefix && strings.HasPrefix(key, prefix)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// CopyOptions controls which volatile keys are kept when copying an instance.
+type CopyOptions struct {
+	// PreserveMACs keeps the volatile.<name>.hwaddr keys so the copy's NICs keep the same MAC addresses.
+	PreserveMACs bool
+
+	// StripVolatile excludes all volatile keys, including those normally kept to optimize copies.
+	StripVolatile bool
 }
 
 // InstanceIncludeWhenCopying is used to decide whether to include a config item or not when copying an instance.
 // The remoteCopy argument indicates if the copy is remote (i.e between servers) as this affects the keys kept.
 func InstanceIncludeWhenCopying(configKey string, remoteCopy bool) bool {
+	return InstanceIncludeWhenCopyingWithOptions(configKey, remoteCopy, CopyOptions{})
+}
+
+// InstanceIncludeWhenCopyingWithOptions is the same as InstanceIncludeWhenCopying but with the volatile keys
+// kept being controlled by opts.
+func InstanceIncludeWhenCopyingWithOptions(configKey string, remoteCopy bool, opts CopyOptions) bool {
+	if !IsVolatileConfig(configKey) {
+		return true // Keep all non-volatile keys.
+	}
+
+	if opts.StripVolatile {
+		return false
+	}
+
 	if configKey == "volatile.base_image" {
 		return true // Include volatile.base_image always as it can help optimize copies.
 	}
@@ -1477,9 +2467,271 @@ func InstanceIncludeWhenCopying(configKey string, remoteCopy bool) bool {
 		return true // Include volatile.last_state.idmap when doing local copy to avoid needless remapping.
 	}
 
-	if strings.HasPrefix(configKey, ConfigVolatilePrefix) {
-		return false // Exclude all other volatile keys.
+	if opts.PreserveMACs && isNICHwaddrKey(configKey) {
+		return true // Include the NIC MAC addresses when requested.
+	}
+
+	return false // Exclude all other volatile keys.
+}
+
+// isNICHwaddrKey returns true if the key is a volatile.<name>.hwaddr key. The MAC addresses recorded in the
+// volatile.<name>.last_state keys belong to host interfaces and so aren't matched.
+func isNICHwaddrKey(configKey string) bool {
+	if !strings.HasPrefix(configKey, "volatile.") || !strings.HasSuffix(configKey, ".hwaddr") {
+		return false
+	}
+
+	return !strings.HasSuffix(configKey, ".last_state.hwaddr") && !strings.HasSuffix(configKey, ".last_state.vf.hwaddr")
+}
+
+// DiffInstanceConfig returns the keys that differ between two instance configs, mapped to their old and new values.
+// Keys that wouldn't be included when copying the instance (see InstanceIncludeWhenCopying) are ignored.
+// A key missing from one of the configs is reported with an empty value.
+func DiffInstanceConfig(a map[string]string, b map[string]string, remoteCopy bool) map[string][2]string {
+	diff := map[string][2]string{}
+
+	for key, oldValue := range a {
+		if !InstanceIncludeWhenCopying(key, remoteCopy) {
+			continue
+		}
+
+		newValue, ok := b[key]
+		if !ok || newValue != oldValue {
+			diff[key] = [2]string{oldValue, newValue}
+		}
+	}
+
+	for key, newValue := range b {
+		if !InstanceIncludeWhenCopying(key, remoteCopy) {
+			continue
+		}
+
+		_, ok := a[key]
+		if !ok {
+			diff[key] = [2]string{"", newValue}
+		}
+	}
+
+	return diff
+}
+
+// MemoryLimitLowThreshold is the memory limit under which a hard limit without swap is considered risky.
+const MemoryLimitLowThreshold = 512 * 1024 * 1024
+
+// MemoryConfigAdvisory returns an informational message when the memory configuration of a container makes it
+// prone to being OOM-killed on memory spikes. An empty string is returned when no advisory applies.
+func MemoryConfigAdvisory(config map[string]string) string {
+	if config["limits.memory.enforce"] == "soft" || !util.IsFalse(config["limits.memory.swap"]) {
+		return ""
+	}
+
+	// Percentage based limits depend on the host and aren't considered.
+	limit := config["limits.memory"]
+	if limit == "" || strings.HasSuffix(limit, "%") {
+		return ""
+	}
+
+	num, err := units.ParseByteSizeString(limit)
+	if err != nil || num >= MemoryLimitLowThreshold {
+		return ""
+	}
+
+	return fmt.Sprintf("Hard memory limit of %s with swap disabled may lead to OOM kills, consider enabling limits.memory.swap or setting limits.memory.enforce to soft", limit)
+}
+
+// ValidateSwapConfig checks the swap related keys of a container against the availability of swap on the host.
+// A swap size can't be honored without swap and is rejected, while explicitly enabling swap or setting its
+// priority merely has no effect, in which case an informational message is returned.
+func ValidateSwapConfig(config map[string]string, hostHasSwap bool) (string, error) {
+	if hostHasSwap {
+		return "", nil
+	}
+
+	swap := config["limits.memory.swap"]
+	if swap != "" && !util.IsTrue(swap) && !util.IsFalse(swap) {
+		return "", fmt.Errorf("limits.memory.swap can't be set to %q as the host has no swap", swap)
+	}
+
+	if util.IsTrue(swap) {
+		return "limits.memory.swap has no effect as the host has no swap", nil
+	}
+
+	if config["limits.memory.swap.priority"] != "" && !util.IsFalse(swap) {
+		return "limits.memory.swap.priority has no effect as the host has no swap", nil
+	}
+
+	return "", nil
+}
+
+// hugepagesFreeOnNodes returns the free huge pages memory, in bytes, across the given host NUMA nodes.
+// It fails if any of the nodes is unknown or doesn't have any huge pages allocated.
+func hugepagesFreeOnNodes(hostStats api.ResourcesMemory, numaNodes []int64) (int64, error) {
+	var free int64
+	for _, numaNode := range numaNodes {
+		idx := slices.IndexFunc(hostStats.Nodes, func(node api.ResourcesMemoryNode) bool { return int64(node.NUMANode) == numaNode })
+		if idx < 0 {
+			return -1, fmt.Errorf("Unknown NUMA node %d", numaNode)
+		}
+
+		node := hostStats.Nodes[idx]
+		if node.HugepagesTotal == 0 {
+			return -1, fmt.Errorf("NUMA node %d doesn't have any huge pages", numaNode)
+		}
+
+		free += int64(node.HugepagesTotal - min(node.HugepagesUsed, node.HugepagesTotal))
+	}
+
+	return free, nil
+}
+
+// ValidateVMHugepages checks that the memory of a virtual machine backed by huge pages can be allocated from the
+// free huge pages of the host described by hostStats. Percentage memory limits are relative to hostStats.Total.
+// When the memory is restricted to some host NUMA nodes, only the huge pages of those nodes are considered.
+func ValidateVMHugepages(config map[string]string, hostStats api.ResourcesMemory, numaNodes []int64) error {
+	if !util.IsTrue(config["limits.memory.hugepages"]) {
+		return nil
+	}
+
+	memory := config["limits.memory"]
+	if memory == "" {
+		memory = qemudefault.MemSize
+	}
+
+	var memoryBytes int64
+	if strings.HasSuffix(memory, "%") {
+		percent, err := strconv.ParseInt(strings.TrimSuffix(memory, "%"), 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid limits.memory %q: %w", memory, err)
+		}
+
+		memoryBytes = (int64(hostStats.Total) / 100) * percent
+	} else {
+		var err error
+		memoryBytes, err = units.ParseByteSizeString(memory)
+		if err != nil {
+			return fmt.Errorf("Invalid limits.memory %q: %w", memory, err)
+		}
+	}
+
+	// The memory is passed to QEMU in MiB.
+	memoryBytes = memoryBytes / (1024 * 1024) * (1024 * 1024)
+
+	if hostStats.HugepagesTotal == 0 {
+		return fmt.Errorf("limits.memory.hugepages is enabled but the host has no huge pages")
+	}
+
+	if hostStats.HugepagesSize > 0 && memoryBytes%int64(hostStats.HugepagesSize) != 0 {
+		return fmt.Errorf("Memory limit of %s isn't a multiple of the host huge page size of %s", units.GetByteSizeStringIEC(memoryBytes, 2), units.GetByteSizeStringIEC(int64(hostStats.HugepagesSize), 2))
+	}
+
+	free := int64(hostStats.HugepagesTotal - min(hostStats.HugepagesUsed, hostStats.HugepagesTotal))
+	if len(numaNodes) > 0 {
+		var err error
+		free, err = hugepagesFreeOnNodes(hostStats, numaNodes)
+		if err != nil {
+			return fmt.Errorf("Memory can't be backed by huge pages: %w", err)
+		}
+	}
+
+	if memoryBytes > free {
+		return fmt.Errorf("Memory limit of %s can't be backed by huge pages, only %s of huge pages are free", units.GetByteSizeStringIEC(memoryBytes, 2), units.GetByteSizeStringIEC(free, 2))
+	}
+
+	return nil
+}
+
+// ValidateSecurityIdmapConsistency checks that the config doesn't combine a privileged container with
+// idmap settings, as privileged containers don't use an idmap and those settings would be silently ignored.
+func ValidateSecurityIdmapConsistency(config map[string]string) error {
+	if !util.IsTrue(config["security.privileged"]) {
+		return nil
+	}
+
+	if util.IsTrue(config["security.idmap.isolated"]) {
+		return fmt.Errorf("security.idmap.isolated is incompatible with privileged containers")
+	}
+
+	for _, key := range []string{"security.idmap.base", "security.idmap.size"} {
+		if config[key] != "" {
+			return fmt.Errorf("%s is incompatible with privileged containers", key)
+		}
+	}
+
+	return nil
+}
+
+// SnapshotScheduleTriggers splits a snapshots.schedule value into its aliases or cron patterns.
+// Aliases can be separated by commas with or without spaces, while cron patterns, which can themselves
+// contain commas, must be separated by a comma and a space, including from the @startup and @never aliases.
+func SnapshotScheduleTriggers(schedule string) []string {
+	triggers := util.SplitNTrimSpace(schedule, ",", -1, true)
+	for _, trigger := range triggers {
+		if !strings.HasPrefix(trigger, "@") {
+			return util.SplitNTrimSpace(schedule, ", ", -1, true)
+		}
+	}
+
+	return triggers
+}
+
+// ValidateNofileLimit checks that a single config layer (an instance's local config or a profile) doesn't set both
+// limits.nofile and limits.kernel.nofile, as both control the same resource limit. Expanded configs may have both,
+// see NofileLimit for which one applies.
+func ValidateNofileLimit(config map[string]string) error {
+	if config["limits.nofile"] != "" && config["limits.kernel.nofile"] != "" {
+		return fmt.Errorf("limits.nofile and limits.kernel.nofile cannot be used at the same time")
+	}
+
+	return nil
+}
+
+// NofileLimit returns the RLIMIT_NOFILE value applying to an instance, either from limits.nofile or from
+// limits.kernel.nofile. When the expanded config has both, the one set in the instance's local config takes
+// precedence over the one coming from a profile, otherwise limits.nofile is used.
+func NofileLimit(localConfig map[string]string, expandedConfig map[string]string) string {
+	if expandedConfig["limits.nofile"] == "" {
+		return expandedConfig["limits.kernel.nofile"]
+	}
+
+	if expandedConfig["limits.kernel.nofile"] != "" && localConfig["limits.kernel.nofile"] != "" && localConfig["limits.nofile"] == "" {
+		return expandedConfig["limits.kernel.nofile"]
+	}
+
+	return expandedConfig["limits.nofile"]
+}
+
+// ValidateInstanceUUIDs checks that the volatile.uuid and volatile.uuid.generation keys, when set, are valid UUIDs.
+func ValidateInstanceUUIDs(config map[string]string) error {
+	for _, key := range []string{"volatile.uuid", "volatile.uuid.generation"} {
+		if config[key] == "" {
+			continue
+		}
+
+		err := validate.IsUUID(config[key])
+		if err != nil {
+			return fmt.Errorf("Invalid %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateRestoredInstanceUUIDs checks the UUIDs of an instance being moved back in time, such as on a snapshot restore.
+// On top of the checks of ValidateInstanceUUIDs, the generation UUID must be set and differ from the instance UUID
+// so that the guest can tell it was rolled back.
+func ValidateRestoredInstanceUUIDs(config map[string]string) error {
+	err := ValidateInstanceUUIDs(config)
+	if err != nil {
+		return err
+	}
+
+	if config["volatile.uuid.generation"] == "" {
+		return fmt.Errorf("Missing volatile.uuid.generation on a restored instance")
+	}
+
+	if config["volatile.uuid.generation"] == config["volatile.uuid"] {
+		return fmt.Errorf("volatile.uuid.generation must differ from volatile.uuid on a restored instance")
 	}
 
-	return true // Keep all other keys.
+	return nil
 }
@@ -0,0 +1,1142 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/v6/internal/server/metadata"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/validate"
+)
+
+func TestMemoryConfigAdvisory(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   map[string]string
+		advisory bool
+	}{
+		{
+			name:     "Hard limit without swap on low memory",
+			config:   map[string]string{"limits.memory": "256MiB", "limits.memory.swap": "false"},
+			advisory: true,
+		},
+		{
+			name:     "Explicit hard limit without swap on low memory",
+			config:   map[string]string{"limits.memory": "256MiB", "limits.memory.swap": "false", "limits.memory.enforce": "hard"},
+			advisory: true,
+		},
+		{
+			name:     "Soft limit without swap on low memory",
+			config:   map[string]string{"limits.memory": "256MiB", "limits.memory.swap": "false", "limits.memory.enforce": "soft"},
+			advisory: false,
+		},
+		{
+			name:     "Hard limit with swap on low memory",
+			config:   map[string]string{"limits.memory": "256MiB", "limits.memory.swap": "true"},
+			advisory: false,
+		},
+		{
+			name:     "Hard limit without swap on large memory",
+			config:   map[string]string{"limits.memory": "4GiB", "limits.memory.swap": "false"},
+			advisory: false,
+		},
+		{
+			name:     "Percentage limit without swap",
+			config:   map[string]string{"limits.memory": "5%", "limits.memory.swap": "false"},
+			advisory: false,
+		},
+		{
+			name:     "No memory limit",
+			config:   map[string]string{"limits.memory.swap": "false"},
+			advisory: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.advisory, MemoryConfigAdvisory(tt.config) != "")
+		})
+	}
+}
+
+func TestValidateSwapConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]string
+		hostHasSwap bool
+		advisory    bool
+		wantErr     bool
+	}{
+		{name: "Default with swap", config: map[string]string{}, hostHasSwap: true},
+		{name: "Default without swap", config: map[string]string{}},
+		{name: "Enabled with swap", config: map[string]string{"limits.memory.swap": "true"}, hostHasSwap: true},
+		{name: "Enabled without swap", config: map[string]string{"limits.memory.swap": "true"}, advisory: true},
+		{name: "Disabled without swap", config: map[string]string{"limits.memory.swap": "false"}},
+		{name: "Size with swap", config: map[string]string{"limits.memory.swap": "1GiB"}, hostHasSwap: true},
+		{name: "Size without swap", config: map[string]string{"limits.memory.swap": "1GiB"}, wantErr: true},
+		{name: "Priority with swap", config: map[string]string{"limits.memory.swap.priority": "5"}, hostHasSwap: true},
+		{name: "Priority without swap", config: map[string]string{"limits.memory.swap.priority": "5"}, advisory: true},
+		{name: "Priority with swap disabled", config: map[string]string{"limits.memory.swap": "false", "limits.memory.swap.priority": "5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			advisory, err := ValidateSwapConfig(tt.config, tt.hostHasSwap)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.advisory, advisory != "")
+		})
+	}
+}
+
+func TestConfigKeyCheckerIdmapSize(t *testing.T) {
+	checker, err := ConfigKeyChecker("security.idmap.size", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker(""))
+	assert.NoError(t, checker("auto"))
+	assert.NoError(t, checker("65536"))
+	assert.Error(t, checker("-1"))
+	assert.Error(t, checker("automatic"))
+}
+
+func TestValidateVMHugepages(t *testing.T) {
+	hostStats := api.ResourcesMemory{
+		Total:          16 * 1024 * 1024 * 1024,
+		HugepagesTotal: 4 * 1024 * 1024 * 1024,
+		HugepagesUsed:  1024 * 1024 * 1024,
+		HugepagesSize:  2 * 1024 * 1024,
+	}
+
+	tests := []struct {
+		name      string
+		config    map[string]string
+		hostStats api.ResourcesMemory
+		wantErr   bool
+	}{
+		{name: "Hugepages disabled", config: map[string]string{"limits.memory": "32GiB"}, hostStats: hostStats},
+		{name: "Fits in free hugepages", config: map[string]string{"limits.memory.hugepages": "true", "limits.memory": "3GiB"}, hostStats: hostStats},
+		{name: "Default memory", config: map[string]string{"limits.memory.hugepages": "true"}, hostStats: hostStats},
+		{name: "Insufficient free hugepages", config: map[string]string{"limits.memory.hugepages": "true", "limits.memory": "4GiB"}, hostStats: hostStats, wantErr: true},
+		{name: "Percentage fits", config: map[string]string{"limits.memory.hugepages": "true", "limits.memory": "10%"}, hostStats: api.ResourcesMemory{Total: 10 * 1024 * 1024 * 1024, HugepagesTotal: 2 * 1024 * 1024 * 1024, HugepagesSize: 4096}},
+		{name: "Insufficient pool for percentage", config: map[string]string{"limits.memory.hugepages": "true", "limits.memory": "50%"}, hostStats: hostStats, wantErr: true},
+		{name: "No hugepages on host", config: map[string]string{"limits.memory.hugepages": "true", "limits.memory": "1GiB"}, hostStats: api.ResourcesMemory{Total: hostStats.Total}, wantErr: true},
+		{name: "Not a multiple of the page size", config: map[string]string{"limits.memory.hugepages": "true", "limits.memory": "3MiB"}, hostStats: hostStats, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateVMHugepages(tt.config, tt.hostStats, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateVMHugepagesNUMA(t *testing.T) {
+	hostStats := api.ResourcesMemory{
+		Total:          16 * 1024 * 1024 * 1024,
+		HugepagesTotal: 4 * 1024 * 1024 * 1024,
+		HugepagesSize:  2 * 1024 * 1024,
+		Nodes: []api.ResourcesMemoryNode{
+			{NUMANode: 0, HugepagesTotal: 3 * 1024 * 1024 * 1024, HugepagesUsed: 1024 * 1024 * 1024},
+			{NUMANode: 1, HugepagesTotal: 1024 * 1024 * 1024},
+			{NUMANode: 2},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		memory    string
+		numaNodes []int64
+		wantErr   bool
+	}{
+		{name: "Fits on node", memory: "2GiB", numaNodes: []int64{0}},
+		{name: "Fits across nodes", memory: "3GiB", numaNodes: []int64{0, 1}},
+		{name: "Doesn't fit on node", memory: "2GiB", numaNodes: []int64{1}, wantErr: true},
+		{name: "Node without huge pages", memory: "1GiB", numaNodes: []int64{0, 2}, wantErr: true},
+		{name: "Unknown node", memory: "1GiB", numaNodes: []int64{3}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateVMHugepages(map[string]string{"limits.memory.hugepages": "true", "limits.memory": tt.memory}, hostStats, tt.numaNodes)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateSyscallList(t *testing.T) {
+	listPath := filepath.Join(t.TempDir(), "syscalls")
+	err := os.WriteFile(listPath, []byte("mount\numount2\n"), 0600)
+	assert.NoError(t, err)
+
+	for _, key := range []string{"security.syscalls.allow", "security.syscalls.deny"} {
+		checker, err := ConfigKeyChecker(key, api.InstanceTypeContainer)
+		assert.NoError(t, err)
+
+		assert.NoError(t, checker("mount\numount2"))
+
+		assert.NoError(t, checker("file://"+listPath))
+		assert.Error(t, checker("file://"+filepath.Join(filepath.Dir(listPath), "missing")))
+		assert.Error(t, checker("file://relative/path"))
+	}
+}
+
+func TestValidateProcessesLimit(t *testing.T) {
+	checker, err := ConfigKeyChecker("limits.processes", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+
+	assert.Error(t, checker("0"))
+	assert.Error(t, checker("-1"))
+	assert.Error(t, checker("abc"))
+	assert.NoError(t, checker(""))
+	assert.NoError(t, checker("4096"))
+}
+
+func TestClampProcessesLimit(t *testing.T) {
+	limit, clamped := ClampProcessesLimit(4096)
+	assert.Equal(t, int64(4096), limit)
+	assert.False(t, clamped)
+
+	limit, clamped = ClampProcessesLimit(ProcessesLimitMax + 1)
+	assert.Equal(t, int64(ProcessesLimitMax), limit)
+	assert.True(t, clamped)
+}
+
+func TestConfigKeyCheckerVFPCI(t *testing.T) {
+	checker, err := ConfigKeyChecker("volatile.eth0.last_state.vf.pci", api.InstanceTypeAny)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker("0000:01:10.3"))
+	assert.NoError(t, checker(""))
+	assert.Error(t, checker("garbage"))
+	assert.Error(t, checker("0000:01:10"))
+}
+
+func TestConfigKeyCheckerNICEffectiveMTUAndVLAN(t *testing.T) {
+	checker, err := ConfigKeyChecker("volatile.eth0.mtu", api.InstanceTypeAny)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker("1500"))
+	assert.NoError(t, checker(""))
+	assert.Error(t, checker("abc"))
+	assert.Error(t, checker("-1"))
+
+	checker, err = ConfigKeyChecker("volatile.eth0.vlan", api.InstanceTypeAny)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker("100"))
+	assert.NoError(t, checker(""))
+	assert.Error(t, checker("4095"))
+
+	// The original MTU of physical NICs isn't affected.
+	checker, err = ConfigKeyChecker("volatile.eth0.last_state.mtu", api.InstanceTypeAny)
+	assert.NoError(t, err)
+	assert.NoError(t, checker(""))
+}
+
+func TestConfigKeyCheckerSourceQuota(t *testing.T) {
+	checker, err := ConfigKeyChecker("volatile.data.source_quota", api.InstanceTypeAny)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker("1073741824"))
+	assert.NoError(t, checker(""))
+	assert.Error(t, checker("1GiB"))
+
+	checker, err = ConfigKeyChecker("volatile.data.source_quota_id", api.InstanceTypeAny)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker("2147483649"))
+	assert.NoError(t, checker(""))
+	assert.Error(t, checker("4294967296"))
+	assert.Error(t, checker("-1"))
+}
+
+func TestValidateRawIdmap(t *testing.T) {
+	checker, err := ConfigKeyChecker("raw.idmap", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker(""))
+	assert.NoError(t, checker("both 1000 1000"))
+	assert.NoError(t, checker("uid 100000 0 65536"))
+	assert.NoError(t, checker("uid 1000-1999 0-999"))
+	assert.NoError(t, checker("\nuid 1000 1000\n\ngid 1000 1000\n"))
+	assert.Error(t, checker("both 1000"))
+	assert.Error(t, checker("user 1000 1000"))
+	assert.Error(t, checker("both 1000 abc"))
+	assert.Error(t, checker("both -1 1000"))
+	assert.Error(t, checker("both 4294967296 0"))
+	assert.Error(t, checker("uid 4294967295 0 2"))
+	assert.Error(t, checker("uid 1000 0 0"))
+	assert.Error(t, checker("uid 1000-1999 0-99"))
+	assert.Error(t, checker("uid 1000-1999 0-999 1000"))
+	assert.Error(t, checker("both 1000 1000 1 1"))
+}
+
+func TestValidateRawIdmapOverlap(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{name: "Disjoint uid ranges", value: "uid 1000-1999 0-999\nuid 5000 1000 100"},
+		{name: "Same IDs for uid and gid", value: "uid 1000 1000\ngid 1000 1000"},
+		{name: "Overlapping uid host ranges", value: "uid 1000-1999 0-999\nuid 1500 5000 10", wantErr: "Host IDs on lines 1 and 2 overlap"},
+		{name: "Overlapping uid namespace ranges", value: "uid 1000 0 100\n\nuid 5000 99 10", wantErr: "Namespace IDs on lines 1 and 3 overlap"},
+		{name: "Overlapping both and gid", value: "both 1000 1000\ngid 1000 2000", wantErr: "Host IDs on lines 1 and 2 overlap"},
+	}
+
+	checker, err := ConfigKeyChecker("raw.idmap", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checker(tt.value)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateSecurityIdmapConsistency(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "Privileged alone",
+			config:  map[string]string{"security.privileged": "true"},
+			wantErr: false,
+		},
+		{
+			name:    "Isolated alone",
+			config:  map[string]string{"security.idmap.isolated": "true"},
+			wantErr: false,
+		},
+		{
+			name:    "Base alone",
+			config:  map[string]string{"security.idmap.base": "100000"},
+			wantErr: false,
+		},
+		{
+			name:    "Size alone",
+			config:  map[string]string{"security.idmap.size": "65536"},
+			wantErr: false,
+		},
+		{
+			name:    "Privileged with isolated",
+			config:  map[string]string{"security.privileged": "true", "security.idmap.isolated": "true"},
+			wantErr: true,
+		},
+		{
+			name:    "Privileged with isolated disabled",
+			config:  map[string]string{"security.privileged": "true", "security.idmap.isolated": "false"},
+			wantErr: false,
+		},
+		{
+			name:    "Privileged with base",
+			config:  map[string]string{"security.privileged": "true", "security.idmap.base": "100000"},
+			wantErr: true,
+		},
+		{
+			name:    "Privileged with size",
+			config:  map[string]string{"security.privileged": "true", "security.idmap.size": "65536"},
+			wantErr: true,
+		},
+		{
+			name:    "Unprivileged with isolated",
+			config:  map[string]string{"security.privileged": "false", "security.idmap.isolated": "true"},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSecurityIdmapConsistency(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfigKeyCheckerKernelLimits(t *testing.T) {
+	checker, err := ConfigKeyChecker("limits.kernel.nofile", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker("65536"))
+	assert.NoError(t, checker("unlimited"))
+	assert.NoError(t, checker("1024:65536"))
+	assert.NoError(t, checker("1024:unlimited"))
+	assert.NoError(t, checker(""))
+	assert.Error(t, checker("1024:"))
+	assert.Error(t, checker("1024:65536:1"))
+	assert.Error(t, checker("-1"))
+	assert.Error(t, checker("lots"))
+
+	_, err = ConfigKeyChecker("limits.kernel.nofilez", api.InstanceTypeContainer)
+	assert.Error(t, err)
+
+	_, err = ConfigKeyChecker("limits.kernel.foo.nofile", api.InstanceTypeContainer)
+	assert.Error(t, err)
+}
+
+func TestConfigKeyCheckerUnknownKeyError(t *testing.T) {
+	_, err := ConfigKeyChecker("magic.future.key", api.InstanceTypeContainer)
+	assert.EqualError(t, err, "Unknown configuration key: magic.future.key")
+	assert.ErrorIs(t, err, ErrUnknownConfigKey)
+
+	var unknownErr UnknownConfigKeyError
+	assert.ErrorAs(t, fmt.Errorf("Failed importing instance: %w", err), &unknownErr)
+	assert.Equal(t, "magic.future.key", unknownErr.Key)
+
+	// Invalid values of known keys aren't reported as unknown keys.
+	checker, err := ConfigKeyChecker("limits.cpu", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+	assert.False(t, errors.As(checker("invalid"), &unknownErr))
+}
+
+func TestConfigKeyCheckerLenient(t *testing.T) {
+	_, err := ConfigKeyChecker("magic.future.key", api.InstanceTypeContainer)
+	assert.ErrorIs(t, err, ErrUnknownConfigKey)
+
+	checker, unknown, err := ConfigKeyCheckerLenient("magic.future.key", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+	assert.True(t, unknown)
+	assert.NoError(t, checker("anything"))
+
+	checker, unknown, err = ConfigKeyCheckerLenient("limits.processes", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+	assert.False(t, unknown)
+	assert.Error(t, checker("-1"))
+}
+
+func TestConfigPrefixHelpers(t *testing.T) {
+	tests := []struct {
+		key         string
+		user        bool
+		image       bool
+		environment bool
+		volatile    bool
+	}{
+		{key: "user.foo", user: true},
+		{key: "image.os", image: true},
+		{key: "environment.PATH", environment: true},
+		{key: "volatile.eth0.hwaddr", volatile: true},
+		{key: "limits.cpu"},
+		{key: "userdata"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.user, IsUserConfig(tt.key))
+			assert.Equal(t, tt.image, IsImageConfig(tt.key))
+			assert.Equal(t, tt.environment, IsEnvironmentConfig(tt.key))
+			assert.Equal(t, tt.volatile, IsVolatileConfig(tt.key))
+		})
+	}
+}
+
+func TestConfigKeyCheckerIncrementalMemory(t *testing.T) {
+	iterations, err := ConfigKeyChecker("migration.incremental.memory.iterations", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+
+	assert.NoError(t, iterations(""))
+	assert.NoError(t, iterations("70"))
+	assert.Error(t, iterations("0"))
+	assert.Error(t, iterations("101"))
+	assert.Error(t, iterations("4000000000"))
+
+	goal, err := ConfigKeyChecker("migration.incremental.memory.goal", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+
+	assert.NoError(t, goal(""))
+	assert.NoError(t, goal("0"))
+	assert.NoError(t, goal("70"))
+	assert.Error(t, goal("101"))
+	assert.Error(t, goal("-1"))
+}
+
+func TestConfigKeyCheckerSEVSession(t *testing.T) {
+	for _, key := range []string{"security.sev.session.dh", "security.sev.session.data"} {
+		checker, err := ConfigKeyChecker(key, api.InstanceTypeVM)
+		assert.NoError(t, err)
+
+		assert.NoError(t, checker(""))
+		assert.NoError(t, checker("aW5jdXM="))
+		assert.Error(t, checker("aW5jdXM"))
+	}
+}
+
+func TestValidateMemoryLimit(t *testing.T) {
+	hostTotal := int64(8 * 1024 * 1024 * 1024)
+
+	assert.Error(t, ValidateMemoryLimit("0%", hostTotal))
+	assert.NoError(t, ValidateMemoryLimit("100%", hostTotal))
+	assert.NoError(t, ValidateMemoryLimit("50%", hostTotal))
+	assert.NoError(t, ValidateMemoryLimit("2GiB", hostTotal))
+	assert.Error(t, ValidateMemoryLimit("1%", 64*1024*1024))
+	assert.Error(t, ValidateMemoryLimit("half%", hostTotal))
+
+	// The plain validator doesn't need the host's total memory.
+	checker, err := ConfigKeyChecker("limits.memory", api.InstanceTypeAny)
+	assert.NoError(t, err)
+	assert.NoError(t, checker("1%"))
+}
+
+func TestValidateRawQemuConf(t *testing.T) {
+	checker, err := ConfigKeyChecker("raw.qemu.conf", api.InstanceTypeVM)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker(""))
+	assert.NoError(t, checker(`
+[memory]
+size = "4096M"
+
+[device "qemu_gpu"]
+driver = "qxl-vga"
+bus =
+multifunction=on
+
+[global][1]
+`))
+
+	assert.Error(t, checker("= \"value\""))
+	assert.Error(t, checker("[memory]\n= \"4096M\""))
+	assert.Error(t, checker("[memory]\nsize"))
+	assert.Error(t, checker("[memory]\nsize = \"4096M"))
+	assert.Error(t, checker("[memory"))
+	assert.Error(t, checker("[memory]x"))
+	assert.Error(t, checker("size = \"4096M\""))
+	assert.Error(t, checker("# Override the memory size.\n[memory]\nsize = \"4096M\""))
+	assert.Error(t, checker("[memory]\n  # 4GB\nsize = \"4096M\""))
+	assert.NoError(t, checker("[global][2147483647]\nkey = value"))
+	assert.Error(t, checker("[global][2147483648]\nkey = value"))
+	assert.Error(t, checker("[global][99999999999999999999]\nkey = value"))
+}
+
+func TestValidateHugePageLimit(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{key: "limits.hugepages.2MB", value: ""},
+		{key: "limits.hugepages.2MB", value: "4MiB"},
+		{key: "limits.hugepages.2MB", value: "1GiB"},
+		{key: "limits.hugepages.2MB", value: "3MiB", wantErr: true},
+		{key: "limits.hugepages.2MB", value: "4MB", wantErr: true},
+		{key: "limits.hugepages.2MB", value: "4194304"},
+		{key: "limits.hugepages.2MB", value: "abc", wantErr: true},
+		{key: "limits.hugepages.64KB", value: "128KiB"},
+		{key: "limits.hugepages.64KB", value: "100KiB", wantErr: true},
+		{key: "limits.hugepages.1MB", value: "3MiB"},
+		{key: "limits.hugepages.1GB", value: "2GiB"},
+		{key: "limits.hugepages.1GB", value: "1GB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			checker, err := ConfigKeyChecker(tt.key, api.InstanceTypeContainer)
+			assert.NoError(t, err)
+
+			err = checker(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfigKeyCheckerProtectionStart(t *testing.T) {
+	for _, instanceType := range []api.InstanceType{api.InstanceTypeContainer, api.InstanceTypeVM} {
+		checker, err := ConfigKeyChecker("security.protection.start", instanceType)
+		assert.NoError(t, err)
+
+		assert.NoError(t, checker(""))
+		assert.NoError(t, checker("true"))
+		assert.NoError(t, checker("false"))
+		assert.Error(t, checker("maybe"))
+	}
+}
+
+func TestConfigKeyCheckerOOMScoreAdj(t *testing.T) {
+	checker, err := ConfigKeyChecker("limits.memory.oom_score_adj", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker(""))
+	assert.NoError(t, checker("-1000"))
+	assert.NoError(t, checker("0"))
+	assert.NoError(t, checker("1000"))
+	assert.Error(t, checker("-1001"))
+	assert.Error(t, checker("1001"))
+	assert.Error(t, checker("high"))
+
+	_, err = ConfigKeyChecker("limits.memory.oom_score_adj", api.InstanceTypeVM)
+	assert.Error(t, err)
+}
+
+func TestConfigKeyCheckerCPUWeight(t *testing.T) {
+	checker, err := ConfigKeyChecker("limits.cpu.weight", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker(""))
+	assert.NoError(t, checker("1"))
+	assert.NoError(t, checker("100"))
+	assert.NoError(t, checker("10000"))
+	assert.Error(t, checker("0"))
+	assert.Error(t, checker("10001"))
+	assert.Error(t, checker("-1"))
+	assert.Error(t, checker("high"))
+
+	_, err = ConfigKeyChecker("limits.cpu.weight", api.InstanceTypeVM)
+	assert.Error(t, err)
+}
+
+func TestRegisterInstanceConfigKey(t *testing.T) {
+	t.Cleanup(func() {
+		delete(InstanceConfigKeysAny, "acme.tier")
+		delete(InstanceConfigKeysVM, "acme.firmware")
+	})
+
+	assert.NoError(t, RegisterInstanceConfigKey("acme.tier", api.InstanceTypeAny, validate.Optional(validate.IsOneOf("gold", "silver"))))
+	assert.NoError(t, RegisterInstanceConfigKey("acme.firmware", api.InstanceTypeVM, validate.IsAny))
+
+	checker, err := ConfigKeyChecker("acme.tier", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+	assert.NoError(t, checker("gold"))
+	assert.Error(t, checker("bronze"))
+
+	_, err = ConfigKeyChecker("acme.firmware", api.InstanceTypeVM)
+	assert.NoError(t, err)
+
+	_, err = ConfigKeyChecker("acme.firmware", api.InstanceTypeContainer)
+	assert.ErrorIs(t, err, ErrUnknownConfigKey)
+
+	// Existing keys, including keys in built-in namespaces, can't be overridden.
+	assert.Error(t, RegisterInstanceConfigKey("acme.tier", api.InstanceTypeAny, validate.IsAny))
+	assert.Error(t, RegisterInstanceConfigKey("limits.cpu", api.InstanceTypeAny, validate.IsAny))
+	assert.Error(t, RegisterInstanceConfigKey("raw.lxc", api.InstanceTypeVM, validate.IsAny))
+	assert.Error(t, RegisterInstanceConfigKey("user.foo", api.InstanceTypeAny, validate.IsAny))
+
+	assert.Error(t, RegisterInstanceConfigKey("", api.InstanceTypeAny, validate.IsAny))
+	assert.Error(t, RegisterInstanceConfigKey("acme.other", api.InstanceTypeAny, nil))
+	assert.Error(t, RegisterInstanceConfigKey("acme.other", "foo", validate.IsAny))
+}
+
+func TestInstanceIncludeWhenCopyingWithOptions(t *testing.T) {
+	keys := []string{"limits.cpu", "volatile.base_image", "volatile.last_state.idmap", "volatile.eth0.hwaddr", "volatile.eth0.host_name", "volatile.eth0.last_state.hwaddr", "volatile.eth1.last_state.vf.hwaddr", "volatile.uuid"}
+
+	tests := []struct {
+		name       string
+		remoteCopy bool
+		opts       CopyOptions
+		want       []string
+	}{
+		{
+			name: "Default local copy",
+			want: []string{"limits.cpu", "volatile.base_image", "volatile.last_state.idmap"},
+		},
+		{
+			name:       "Default remote copy",
+			remoteCopy: true,
+			want:       []string{"limits.cpu", "volatile.base_image"},
+		},
+		{
+			name: "Preserve MACs",
+			opts: CopyOptions{PreserveMACs: true},
+			want: []string{"limits.cpu", "volatile.base_image", "volatile.last_state.idmap", "volatile.eth0.hwaddr"},
+		},
+		{
+			name:       "Preserve MACs on remote copy",
+			remoteCopy: true,
+			opts:       CopyOptions{PreserveMACs: true},
+			want:       []string{"limits.cpu", "volatile.base_image", "volatile.eth0.hwaddr"},
+		},
+		{
+			name: "Strip volatile",
+			opts: CopyOptions{StripVolatile: true, PreserveMACs: true},
+			want: []string{"limits.cpu"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, key := range keys {
+				if InstanceIncludeWhenCopyingWithOptions(key, tt.remoteCopy, tt.opts) {
+					got = append(got, key)
+				}
+			}
+
+			assert.Equal(t, tt.want, got)
+
+			if tt.opts == (CopyOptions{}) {
+				for _, key := range keys {
+					assert.Equal(t, InstanceIncludeWhenCopying(key, tt.remoteCopy), InstanceIncludeWhenCopyingWithOptions(key, tt.remoteCopy, tt.opts))
+				}
+			}
+		})
+	}
+}
+
+func TestDiffInstanceConfig(t *testing.T) {
+	a := map[string]string{
+		"limits.cpu":                "2",
+		"limits.memory":             "1GiB",
+		"security.nesting":          "true",
+		"volatile.pid":              "1234",
+		"volatile.last_state.idmap": "[]",
+	}
+
+	b := map[string]string{
+		"limits.cpu":                "4",
+		"limits.memory":             "1GiB",
+		"boot.autostart":            "true",
+		"volatile.pid":              "5678",
+		"volatile.last_state.idmap": "[{}]",
+	}
+
+	assert.Equal(t, map[string][2]string{
+		"limits.cpu":                {"2", "4"},
+		"security.nesting":          {"true", ""},
+		"boot.autostart":            {"", "true"},
+		"volatile.last_state.idmap": {"[]", "[{}]"},
+	}, DiffInstanceConfig(a, b, false))
+
+	// The idmap is only kept on local copies.
+	assert.Equal(t, map[string][2]string{
+		"limits.cpu":       {"2", "4"},
+		"security.nesting": {"true", ""},
+		"boot.autostart":   {"", "true"},
+	}, DiffInstanceConfig(a, b, true))
+
+	assert.Empty(t, DiffInstanceConfig(a, a, false))
+}
+
+func TestConfigKeyMetadata(t *testing.T) {
+	keys := ConfigKeyMetadata()
+
+	assert.Contains(t, keys, KeyInfo{Key: "limits.cpu", InstanceType: api.InstanceTypeAny})
+	assert.Contains(t, keys, KeyInfo{Key: "raw.lxc", InstanceType: api.InstanceTypeContainer})
+	assert.Contains(t, keys, KeyInfo{Key: "raw.qemu", InstanceType: api.InstanceTypeVM})
+	assert.Len(t, keys, len(InstanceConfigKeysAny)+len(InstanceConfigKeysContainer)+len(InstanceConfigKeysVM))
+
+	assert.True(t, slices.IsSortedFunc(keys, func(a KeyInfo, b KeyInfo) int { return strings.Compare(a.Key, b.Key) }))
+
+	// Every listed key must be accepted by ConfigKeyChecker for its instance type.
+	for _, key := range keys {
+		_, err := ConfigKeyChecker(key.Key, key.InstanceType)
+		assert.NoError(t, err, key.Key)
+	}
+}
+
+func TestParseMknodAllowed(t *testing.T) {
+	devices, err := ParseMknodAllowed("1:3,1:5")
+	assert.NoError(t, err)
+	assert.Equal(t, []MknodDevice{{Major: 1, Minor: 3}, {Major: 1, Minor: 5}}, devices)
+
+	devices, err = ParseMknodAllowed("null, 10:200")
+	assert.NoError(t, err)
+	assert.Equal(t, []MknodDevice{{Major: 1, Minor: 3}, {Major: 10, Minor: 200}}, devices)
+
+	checker, err := ConfigKeyChecker("security.syscalls.intercept.mknod.allowed", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker(""))
+	assert.NoError(t, checker("1:3,1:5"))
+	assert.Error(t, checker("1-3"))
+	assert.Error(t, checker("1:x"))
+	assert.Error(t, checker("-1:3"))
+	assert.Error(t, checker("sda"))
+}
+
+func TestConfigKeyCheckerBootDebugEDK2(t *testing.T) {
+	checker, err := ConfigKeyChecker("boot.debug.edk2", api.InstanceTypeVM)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker(""))
+	assert.NoError(t, checker("true"))
+	assert.Error(t, checker("verbose"))
+
+	_, err = ConfigKeyChecker("boot.debug.edk2", api.InstanceTypeContainer)
+	assert.ErrorIs(t, err, ErrUnknownConfigKey)
+}
+
+func TestIsDeprecatedConfigKey(t *testing.T) {
+	newKey, deprecated := IsDeprecatedConfigKey("security.syscalls.blacklist")
+	assert.True(t, deprecated)
+	assert.Equal(t, "security.syscalls.deny", newKey)
+
+	newKey, deprecated = IsDeprecatedConfigKey("security.syscalls.whitelist")
+	assert.True(t, deprecated)
+	assert.Equal(t, "security.syscalls.allow", newKey)
+
+	_, deprecated = IsDeprecatedConfigKey("security.syscalls.deny")
+	assert.False(t, deprecated)
+
+	// Both the deprecated keys and their replacements must be valid keys.
+	for oldKey, newKey := range deprecatedConfigKeys {
+		_, err := ConfigKeyChecker(oldKey, api.InstanceTypeContainer)
+		assert.NoError(t, err, oldKey)
+
+		_, err = ConfigKeyChecker(newKey, api.InstanceTypeContainer)
+		assert.NoError(t, err, newKey)
+	}
+}
+
+func TestConfigKeyCheckerCPUNodes(t *testing.T) {
+	checker, err := ConfigKeyChecker("limits.cpu.nodes", api.InstanceTypeAny)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker(""))
+	assert.NoError(t, checker("balanced"))
+	assert.NoError(t, checker("0"))
+	assert.NoError(t, checker("0-1,3"))
+	assert.Error(t, checker("balanced,0"))
+	assert.Error(t, checker("auto"))
+}
+
+func TestConfigKeyCheckerCPUEmulator(t *testing.T) {
+	checker, err := ConfigKeyChecker("limits.cpu.emulator", api.InstanceTypeVM)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker(""))
+	assert.NoError(t, checker("0"))
+	assert.NoError(t, checker("0-1,6"))
+	assert.Error(t, checker("0-"))
+	assert.Error(t, checker("all"))
+
+	_, err = ConfigKeyChecker("limits.cpu.emulator", api.InstanceTypeContainer)
+	assert.ErrorIs(t, err, ErrUnknownConfigKey)
+}
+
+func TestConfigKeyCheckerNvidiaDriverCapabilities(t *testing.T) {
+	checker, err := ConfigKeyChecker("nvidia.driver.capabilities", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker(""))
+	assert.NoError(t, checker("compute,utility"))
+	assert.NoError(t, checker("graphics,ngx"))
+	assert.NoError(t, checker("all"))
+	assert.Error(t, checker("compute,utilty"))
+	assert.Error(t, checker("all,compute"))
+	assert.Error(t, checker("compute,"))
+}
+
+func TestConfigKeyCheckerHostShutdownTimeout(t *testing.T) {
+	checker, err := ConfigKeyChecker("boot.host_shutdown_timeout", api.InstanceTypeAny)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker(""))
+	assert.NoError(t, checker("0"))
+	assert.NoError(t, checker("60"))
+	assert.NoError(t, checker("3600"))
+	assert.NoError(t, checker("3601"))
+	assert.Error(t, checker("-5"))
+	assert.Error(t, checker("1m"))
+}
+
+func TestClampHostShutdownTimeout(t *testing.T) {
+	timeout, clamped := ClampHostShutdownTimeout(60)
+	assert.Equal(t, int64(60), timeout)
+	assert.False(t, clamped)
+
+	timeout, clamped = ClampHostShutdownTimeout(HostShutdownTimeoutMax + 1)
+	assert.Equal(t, int64(HostShutdownTimeoutMax), timeout)
+	assert.True(t, clamped)
+}
+
+func TestConfigKeyRequiresRestart(t *testing.T) {
+	restart, err := ConfigKeyRequiresRestart("security.privileged", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+	assert.True(t, restart)
+
+	restart, err = ConfigKeyRequiresRestart("limits.memory", api.InstanceTypeAny)
+	assert.NoError(t, err)
+	assert.False(t, restart)
+
+	restart, err = ConfigKeyRequiresRestart("user.user-data", api.InstanceTypeAny)
+	assert.NoError(t, err)
+	assert.True(t, restart)
+
+	restart, err = ConfigKeyRequiresRestart("user.foo", api.InstanceTypeAny)
+	assert.NoError(t, err)
+	assert.False(t, restart)
+
+	restart, err = ConfigKeyRequiresRestart("linux.sysctl.net.ipv4.ip_forward", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+	assert.True(t, restart)
+
+	restart, err = ConfigKeyRequiresRestart("security.syscalls.intercept.sched_setscheduler", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+	assert.True(t, restart)
+
+	// Keys only used when the related event occurs don't need a restart.
+	for _, key := range []string{"boot.autostart", "boot.autostart.delay", "snapshots.schedule", "snapshots.expiry"} {
+		restart, err = ConfigKeyRequiresRestart(key, api.InstanceTypeAny)
+		assert.NoError(t, err)
+		assert.False(t, restart, key)
+	}
+
+	_, err = ConfigKeyRequiresRestart("security.privileged", api.InstanceTypeVM)
+	assert.ErrorIs(t, err, ErrUnknownConfigKey)
+
+	// Every listed key must be a known key.
+	for _, key := range instanceConfigKeysRestart {
+		key = strings.Replace(key, "*", "foo", 1)
+		_, containerErr := ConfigKeyChecker(key, api.InstanceTypeContainer)
+		_, vmErr := ConfigKeyChecker(key, api.InstanceTypeVM)
+		assert.True(t, containerErr == nil || vmErr == nil, key)
+	}
+
+	// The list must match the keys documented as not supporting live updates.
+	eventKeys := []string{"boot.autorestart", "boot.autostart", "boot.stop.priority", "cluster.evacuate", "snapshots."}
+	documented := []string{}
+
+	configs, _ := metadata.Data["configs"].(map[string]any)
+	groups, _ := configs["instance"].(map[string]any)
+	for _, group := range groups {
+		groupKeys, _ := group.(map[string]any)["keys"].([]any)
+		for _, entry := range groupKeys {
+			entryKeys, _ := entry.(map[string]any)
+			for key, info := range entryKeys {
+				details, _ := info.(map[string]any)
+				if details["liveupdate"] != "no" {
+					continue
+				}
+
+				if slices.ContainsFunc(eventKeys, func(prefix string) bool { return strings.HasPrefix(key, prefix) }) {
+					continue
+				}
+
+				documented = append(documented, key)
+			}
+		}
+	}
+
+	assert.ElementsMatch(t, documented, instanceConfigKeysRestart)
+}
+
+func TestValidateInstanceUUIDs(t *testing.T) {
+	base := "0a9ab6bb-0a66-4e5b-9c55-6b4a2b4e6f61"
+	other := "8d2a1f2e-4c3b-4f53-8b5e-1c2d3e4f5a6b"
+
+	tests := []struct {
+		name           string
+		config         map[string]string
+		wantErr        bool
+		wantRestoreErr bool
+	}{
+		{name: "Empty", config: map[string]string{}, wantErr: false, wantRestoreErr: true},
+		{name: "Equal", config: map[string]string{"volatile.uuid": base, "volatile.uuid.generation": base}, wantErr: false, wantRestoreErr: true},
+		{name: "Distinct", config: map[string]string{"volatile.uuid": base, "volatile.uuid.generation": other}, wantErr: false, wantRestoreErr: false},
+		{name: "Invalid UUID", config: map[string]string{"volatile.uuid": "foo", "volatile.uuid.generation": other}, wantErr: true, wantRestoreErr: true},
+		{name: "Invalid generation", config: map[string]string{"volatile.uuid": base, "volatile.uuid.generation": "foo"}, wantErr: true, wantRestoreErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateInstanceUUIDs(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			err = ValidateRestoredInstanceUUIDs(tt.config)
+			if tt.wantRestoreErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateRawApparmor(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "Empty", value: ""},
+		{name: "Rules", value: "mount fstype=nfs,\n/srv/** rw,\n"},
+		{name: "Balanced block", value: "profile foo {\n  /bin/true ix,\n}\n"},
+		{name: "Brace in comment", value: "# profile {\n/srv/** rw,\n"},
+		{name: "Unclosed block", value: "profile foo {\n  /bin/true ix,\n", wantErr: true},
+		{name: "Unexpected closing brace", value: "/srv/** rw,\n}\n", wantErr: true},
+		{name: "Include", value: "#include <abstractions/missing>\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRawApparmor(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfigKeyCheckerSnapshotsScheduleMaximum(t *testing.T) {
+	checker, err := ConfigKeyChecker("snapshots.schedule.maximum", api.InstanceTypeAny)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker(""))
+	assert.NoError(t, checker("1"))
+	assert.NoError(t, checker("100"))
+	assert.Error(t, checker("0"))
+	assert.Error(t, checker("-1"))
+	assert.Error(t, checker("many"))
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{value: "/bin/sh", want: []string{"/bin/sh"}},
+		{value: "/usr/bin/myapp --verbose", want: []string{"/usr/bin/myapp", "--verbose"}},
+		{value: `/bin/sh -c "echo hello world"`, want: []string{"/bin/sh", "-c", "echo hello world"}},
+		{value: `/bin/echo 'a b' c\ d`, want: []string{"/bin/echo", "a b", "c d"}},
+		{value: "", wantErr: true},
+		{value: "   ", wantErr: true},
+		{value: `/bin/sh -c "unterminated`, wantErr: true},
+		{value: "/bin/echo foo\x00bar", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			args, err := ParseCommand(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, args)
+		})
+	}
+
+	checker, err := ConfigKeyChecker("oci.command", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+	assert.NoError(t, checker(""))
+	assert.NoError(t, checker("/bin/sh"))
+	assert.Error(t, checker("/bin/sh\x00"))
+
+	_, err = ConfigKeyChecker("oci.command", api.InstanceTypeVM)
+	assert.Error(t, err)
+}
+
+func TestValidateNofileLimit(t *testing.T) {
+	checker, err := ConfigKeyChecker("limits.nofile", api.InstanceTypeContainer)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker(""))
+	assert.NoError(t, checker("1024"))
+	assert.NoError(t, checker("1048576"))
+	assert.Error(t, checker("0"))
+	assert.Error(t, checker("-1"))
+	assert.Error(t, checker("unlimited"))
+	assert.Error(t, checker("1024:4096"))
+
+	_, err = ConfigKeyChecker("limits.nofile", api.InstanceTypeVM)
+	assert.Error(t, err)
+
+	assert.NoError(t, ValidateNofileLimit(map[string]string{"limits.nofile": "1024"}))
+	assert.NoError(t, ValidateNofileLimit(map[string]string{"limits.kernel.nofile": "1024:4096"}))
+	assert.Error(t, ValidateNofileLimit(map[string]string{"limits.nofile": "1024", "limits.kernel.nofile": "4096"}))
+}
+
+func TestNofileLimit(t *testing.T) {
+	both := map[string]string{"limits.nofile": "1024", "limits.kernel.nofile": "2048:4096"}
+
+	assert.Empty(t, NofileLimit(nil, map[string]string{}))
+	assert.Equal(t, "1024", NofileLimit(nil, map[string]string{"limits.nofile": "1024"}))
+	assert.Equal(t, "2048:4096", NofileLimit(nil, map[string]string{"limits.kernel.nofile": "2048:4096"}))
+
+	// The key set on the instance takes precedence over the one from a profile.
+	assert.Equal(t, "1024", NofileLimit(map[string]string{"limits.nofile": "1024"}, both))
+	assert.Equal(t, "2048:4096", NofileLimit(map[string]string{"limits.kernel.nofile": "2048:4096"}, both))
+
+	// Otherwise limits.nofile is used.
+	assert.Equal(t, "1024", NofileLimit(map[string]string{}, both))
+}
+
+func TestValidateEnvironmentKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{key: "environment.PATH"},
+		{key: "environment._private"},
+		{key: "environment.http_proxy2"},
+		{key: "environment.1abc"},
+		{key: "environment.MY-VAR"},
+		{key: "environment.MY VAR", wantErr: true},
+		{key: "environment.MY\tVAR", wantErr: true},
+		{key: "environment.MY=VAR", wantErr: true},
+		{key: "environment.MY\x00VAR", wantErr: true},
+		{key: "environment.", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			checker, err := ConfigKeyChecker(tt.key, api.InstanceTypeAny)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NoError(t, checker("any value"))
+		})
+	}
+}
+
+func TestSnapshotScheduleTriggers(t *testing.T) {
+	assert.Nil(t, SnapshotScheduleTriggers(""))
+	assert.Equal(t, []string{"@daily", "@startup"}, SnapshotScheduleTriggers("@daily,@startup"))
+	assert.Equal(t, []string{"@daily", "@startup"}, SnapshotScheduleTriggers("@daily, @startup"))
+	assert.Equal(t, []string{"0 0,12 * * *"}, SnapshotScheduleTriggers("0 0,12 * * *"))
+	assert.Equal(t, []string{"0 0,12 * * *", "30 6 * * *"}, SnapshotScheduleTriggers("0 0,12 * * *, 30 6 * * *"))
+	assert.Equal(t, []string{"@startup", "0 0,12 * * *"}, SnapshotScheduleTriggers("@startup, 0 0,12 * * *"))
+
+	checker, err := ConfigKeyChecker("snapshots.schedule", api.InstanceTypeAny)
+	assert.NoError(t, err)
+	assert.NoError(t, checker("@daily,@weekly"))
+	assert.NoError(t, checker("0 0 * * *"))
+	assert.Error(t, checker("@daily,0 0 * * *"))
+	assert.NoError(t, checker("@startup, 0 6 * * *"))
+}
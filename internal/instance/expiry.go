@@ -14,7 +14,8 @@ import (
 func GetExpiry(refDate time.Time, s string) (time.Time, error) {
 	expr := strings.TrimSpace(s)
 
-	if expr == "" {
+	// An empty expression, "0" and "never" all mean that there is no expiry.
+	if expr == "" || expr == "0" || expr == "never" {
 		return time.Time{}, nil
 	}
 
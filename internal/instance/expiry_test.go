@@ -0,0 +1,32 @@
+package instance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExpiry(t *testing.T) {
+	refDate := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, value := range []string{"", "0", "never", " never "} {
+		expiry, err := GetExpiry(refDate, value)
+		assert.NoError(t, err)
+		assert.True(t, expiry.IsZero(), "Expected no expiry for %q", value)
+	}
+
+	expiry, err := GetExpiry(refDate, "2w")
+	assert.NoError(t, err)
+	assert.Equal(t, refDate.AddDate(0, 0, 14), expiry)
+
+	expiry, err = GetExpiry(refDate, "1d 3H")
+	assert.NoError(t, err)
+	assert.Equal(t, refDate.Add(27*time.Hour), expiry)
+
+	_, err = GetExpiry(refDate, "forever")
+	assert.Error(t, err)
+
+	_, err = GetExpiry(refDate, "1d 2d")
+	assert.Error(t, err)
+}
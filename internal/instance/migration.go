@@ -0,0 +1,64 @@
+package instance
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// CanMigrateStateful checks whether stateful migration is achievable for a virtual machine with the given
+// expanded config and devices. When it isn't, the returned string explains which setting prevents it.
+// NICs using a managed network are checked using their "nictype" property, which callers should fill in from
+// the type of the network. The devices will still perform their own validation when migration.stateful is enabled.
+func CanMigrateStateful(config map[string]string, devices map[string]map[string]string) (bool, string) {
+	if util.IsTrue(config["security.sev"]) {
+		return false, "Memory encrypted (SEV) virtual machines cannot be migrated"
+	}
+
+	names := make([]string, 0, len(devices))
+	for name := range devices {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		dev := devices[name]
+
+		switch dev["type"] {
+		case "gpu":
+			return false, fmt.Sprintf("GPU device %q cannot be migrated", name)
+		case "pci":
+			return false, fmt.Sprintf("PCI passthrough device %q cannot be migrated", name)
+		case "usb":
+			return false, fmt.Sprintf("USB device %q cannot be migrated", name)
+		case "infiniband":
+			return false, fmt.Sprintf("Infiniband device %q cannot be migrated", name)
+		case "nic":
+			if slices.Contains([]string{"physical", "sriov"}, dev["nictype"]) {
+				return false, fmt.Sprintf("Network device %q of type %q cannot be migrated", name, dev["nictype"])
+			}
+
+		case "disk":
+			if IsRootDiskDevice(dev) {
+				continue
+			}
+
+			if dev["path"] != "" {
+				return false, fmt.Sprintf("Shared filesystem disk %q cannot be migrated", name)
+			}
+
+			if dev["pool"] == "" && !slices.Contains([]string{"cloud-init:config", "agent:config"}, dev["source"]) {
+				return false, fmt.Sprintf("Disk %q isn't managed by Incus and cannot be migrated", name)
+			}
+
+			if dev["io.bus"] == "nvme" {
+				return false, fmt.Sprintf("NVME disk %q cannot be migrated", name)
+			}
+		}
+	}
+
+	return true, ""
+}
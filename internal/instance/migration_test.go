@@ -0,0 +1,67 @@
+package instance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanMigrateStateful(t *testing.T) {
+	root := map[string]string{"type": "disk", "path": "/", "pool": "default"}
+
+	tests := []struct {
+		name    string
+		config  map[string]string
+		devices map[string]map[string]string
+		ok      bool
+	}{
+		{
+			name:    "Root disk and bridged NIC",
+			devices: map[string]map[string]string{"root": root, "eth0": {"type": "nic", "nictype": "bridged", "parent": "incusbr0"}},
+			ok:      true,
+		},
+		{
+			name:    "PCI passthrough",
+			devices: map[string]map[string]string{"root": root, "dev0": {"type": "pci", "address": "0000:01:00.0"}},
+			ok:      false,
+		},
+		{
+			name:    "SR-IOV NIC",
+			devices: map[string]map[string]string{"root": root, "eth0": {"type": "nic", "nictype": "sriov", "parent": "enp1s0"}},
+			ok:      false,
+		},
+		{
+			name:    "Managed SR-IOV network",
+			devices: map[string]map[string]string{"root": root, "eth0": {"type": "nic", "nictype": "sriov", "network": "sriov0"}},
+			ok:      false,
+		},
+		{
+			name:    "SEV",
+			config:  map[string]string{"security.sev": "true"},
+			devices: map[string]map[string]string{"root": root},
+			ok:      false,
+		},
+		{
+			name:    "Shared filesystem",
+			devices: map[string]map[string]string{"root": root, "data": {"type": "disk", "source": "/srv/data", "path": "/data"}},
+			ok:      false,
+		},
+		{
+			name:    "Cloud-init drive",
+			devices: map[string]map[string]string{"root": root, "config": {"type": "disk", "source": "cloud-init:config"}},
+			ok:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, reason := CanMigrateStateful(tt.config, tt.devices)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Empty(t, reason)
+			} else {
+				assert.NotEmpty(t, reason)
+			}
+		})
+	}
+}
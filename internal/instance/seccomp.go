@@ -0,0 +1,66 @@
+package instance
+
+import (
+	"fmt"
+
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// exclusiveConfigKeys returns the value of whichever of two alternative keys is set, failing if both are set.
+func exclusiveConfigKeys(key1 string, key2 string, config map[string]string) (val string, ok bool, err error) {
+	if config[key1] != "" && config[key2] != "" {
+		return "", false, fmt.Errorf("Mutually exclusive keys %s and %s are set", key1, key2)
+	}
+
+	val, ok = config[key1]
+	if ok {
+		return
+	}
+
+	val, ok = config[key2]
+	if ok {
+		return
+	}
+
+	return "", false, nil
+}
+
+// ValidateSyscallConfig checks that the config doesn't combine mutually exclusive syscall filtering keys.
+// raw.seccomp can't be combined with any security.syscalls.* filtering key and security.syscalls.allow can't be
+// combined with security.syscalls.deny or an enabled security.syscalls.deny_default or security.syscalls.deny_compat.
+func ValidateSyscallConfig(config map[string]string) error {
+	_, rawSeccomp := config["raw.seccomp"]
+	_, isAllow, err := exclusiveConfigKeys("security.syscalls.allow", "security.syscalls.whitelist", config)
+	if err != nil {
+		return err
+	}
+
+	_, isDeny, err := exclusiveConfigKeys("security.syscalls.deny", "security.syscalls.blacklist", config)
+	if err != nil {
+		return err
+	}
+
+	val, _, err := exclusiveConfigKeys("security.syscalls.deny_default", "security.syscalls.blacklist_default", config)
+	if err != nil {
+		return err
+	}
+
+	isDenyDefault := util.IsTrue(val)
+
+	val, _, err = exclusiveConfigKeys("security.syscalls.deny_compat", "security.syscalls.blacklist_compat", config)
+	if err != nil {
+		return err
+	}
+
+	isDenyCompat := util.IsTrue(val)
+
+	if rawSeccomp && (isAllow || isDeny || isDenyDefault || isDenyCompat) {
+		return fmt.Errorf("raw.seccomp is mutually exclusive with security.syscalls*")
+	}
+
+	if isAllow && (isDeny || isDenyDefault || isDenyCompat) {
+		return fmt.Errorf("security.syscalls.allow is mutually exclusive with security.syscalls.deny*")
+	}
+
+	return nil
+}
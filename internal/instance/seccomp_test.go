@@ -0,0 +1,38 @@
+package instance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSyscallConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]string
+		wantErr bool
+	}{
+		{name: "Nothing set", config: map[string]string{}},
+		{name: "Allow only", config: map[string]string{"security.syscalls.allow": "read"}},
+		{name: "Deny only", config: map[string]string{"security.syscalls.deny": "mount", "security.syscalls.deny_default": "true", "security.syscalls.deny_compat": "true"}},
+		{name: "Allow with default deny disabled", config: map[string]string{"security.syscalls.allow": "read", "security.syscalls.deny_default": "false"}},
+		{name: "Raw only", config: map[string]string{"raw.seccomp": "2\ndenylist\n"}},
+		{name: "Allow and deny", config: map[string]string{"security.syscalls.allow": "read", "security.syscalls.deny": "mount"}, wantErr: true},
+		{name: "Allow and deny_default", config: map[string]string{"security.syscalls.allow": "read", "security.syscalls.deny_default": "true"}, wantErr: true},
+		{name: "Allow and deny_compat", config: map[string]string{"security.syscalls.allow": "read", "security.syscalls.deny_compat": "true"}, wantErr: true},
+		{name: "Whitelist and blacklist", config: map[string]string{"security.syscalls.whitelist": "read", "security.syscalls.blacklist": "mount"}, wantErr: true},
+		{name: "Allow and whitelist", config: map[string]string{"security.syscalls.allow": "read", "security.syscalls.whitelist": "write"}, wantErr: true},
+		{name: "Raw and deny", config: map[string]string{"raw.seccomp": "2\ndenylist\n", "security.syscalls.deny": "mount"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSyscallConfig(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package instance
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// ValidationError represents a validation failure along with the path of the offending field.
+// The path is either "config/<key>" for instance configuration keys or "devices/<name>[/<option>]" for devices.
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+// Error returns the validation error message.
+func (e ValidationError) Error() string {
+	return e.Message
+}
+
+// ValidationErrors is a list of validation failures.
+type ValidationErrors []ValidationError
+
+// Error returns all the validation error messages.
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Err returns the validation errors as an error, or nil if there are none.
+func (e ValidationErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// deviceOptionError is implemented by device validation errors which relate to a specific device option.
+type deviceOptionError interface {
+	error
+	DeviceOption() string
+}
+
+// ValidateConfig runs validator against every config key and returns every failure found.
+func ValidateConfig(config map[string]string, validator func(key string, value string) error) ValidationErrors {
+	keys := make([]string, 0, len(config))
+	for k := range config {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	var errs ValidationErrors
+	for _, k := range keys {
+		err := validator(k, config[k])
+		if err != nil {
+			errs = append(errs, ValidationError{Path: "config/" + k, Message: err.Error()})
+		}
+	}
+
+	return errs
+}
+
+// ValidateInstanceConfig performs the syntactic validation of all the supplied config keys using ConfigKeyChecker.
+// It returns nil when all the keys are valid, and ValidationErrors listing every invalid key otherwise.
+func ValidateInstanceConfig(config map[string]string, instanceType api.InstanceType) error {
+	return ValidateConfig(config, func(key string, value string) error {
+		checker, err := ConfigKeyChecker(key, instanceType)
+		if err != nil {
+			return err
+		}
+
+		return checker(value)
+	}).Err()
+}
+
+// ValidateDevices runs validator against every device and returns every failure found.
+// When the validator returns an error carrying the failing device option, the option is included in the path.
+func ValidateDevices[M ~map[string]D, D ~map[string]string](devices M, validator func(name string, config D) error) ValidationErrors {
+	names := make([]string, 0, len(devices))
+	for name := range devices {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var errs ValidationErrors
+	for _, name := range names {
+		err := validator(name, devices[name])
+		if err == nil {
+			continue
+		}
+
+		path := "devices/" + name
+
+		var optionErr deviceOptionError
+		if errors.As(err, &optionErr) {
+			path = path + "/" + optionErr.DeviceOption()
+		}
+
+		errs = append(errs, ValidationError{Path: path, Message: err.Error()})
+	}
+
+	return errs
+}
@@ -0,0 +1,128 @@
+package instance
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// testOptionError is a device validation error for a specific device option.
+type testOptionError struct {
+	option string
+}
+
+func (e testOptionError) Error() string {
+	return fmt.Sprintf("Invalid device option %q", e.option)
+}
+
+func (e testOptionError) DeviceOption() string {
+	return e.option
+}
+
+func TestValidateConfig(t *testing.T) {
+	config := map[string]string{
+		"limits.memory":       "invalid",
+		"limits.cpu":          "2",
+		"security.privileged": "maybe",
+		"unknown.key":         "foo",
+	}
+
+	validator := func(key string, value string) error {
+		checker, err := ConfigKeyChecker(key, api.InstanceTypeContainer)
+		if err != nil {
+			return err
+		}
+
+		return checker(value)
+	}
+
+	errs := ValidateConfig(config, validator)
+	assert.Len(t, errs, 3)
+	assert.Equal(t, "config/limits.memory", errs[0].Path)
+	assert.NotEmpty(t, errs[0].Message)
+	assert.Equal(t, "config/security.privileged", errs[1].Path)
+	assert.Equal(t, "config/unknown.key", errs[2].Path)
+
+	err := errs.Err()
+	assert.Error(t, err)
+	assert.Equal(t, errs[0].Message+"; "+errs[1].Message+"; "+errs[2].Message, err.Error())
+
+	var validationErrs ValidationErrors
+	assert.ErrorAs(t, fmt.Errorf("Invalid config: %w", err), &validationErrs)
+	assert.Len(t, validationErrs, 3)
+
+	// A single failure keeps the validator's error message.
+	err = ValidateConfig(map[string]string{"limits.cpu": "2", "unknown.key": "foo"}, validator).Err()
+	_, checkerErr := ConfigKeyChecker("unknown.key", api.InstanceTypeContainer)
+	assert.EqualError(t, err, checkerErr.Error())
+
+	assert.NoError(t, ValidateConfig(map[string]string{"limits.cpu": "2"}, validator).Err())
+	assert.NoError(t, ValidateConfig(nil, validator).Err())
+}
+
+func TestValidateInstanceConfig(t *testing.T) {
+	config := map[string]string{
+		"limits.memory":       "invalid",
+		"limits.cpu":          "2",
+		"security.privileged": "maybe",
+		"unknown.key":         "foo",
+	}
+
+	err := ValidateInstanceConfig(config, api.InstanceTypeContainer)
+	assert.Error(t, err)
+
+	var errs ValidationErrors
+	assert.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 3)
+	assert.Equal(t, "config/limits.memory", errs[0].Path)
+	assert.Equal(t, "config/security.privileged", errs[1].Path)
+	assert.Equal(t, "config/unknown.key", errs[2].Path)
+
+	// Keys are checked against the instance type.
+	err = ValidateInstanceConfig(map[string]string{"security.nesting": "true", "migration.stateful": "maybe", "limits.cpu": "2"}, api.InstanceTypeVM)
+	assert.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "config/migration.stateful", errs[0].Path)
+	assert.Equal(t, "config/security.nesting", errs[1].Path)
+
+	assert.NoError(t, ValidateInstanceConfig(map[string]string{"limits.cpu": "2"}, api.InstanceTypeContainer))
+	assert.NoError(t, ValidateInstanceConfig(nil, api.InstanceTypeContainer))
+}
+
+func TestValidateDevices(t *testing.T) {
+	devices := map[string]map[string]string{
+		"eth0": {"type": "nic", "mtu": "invalid"},
+		"eth1": {"type": "nic", "unknown": "foo"},
+		"root": {"type": "disk", "path": "/", "pool": "default"},
+	}
+
+	validator := func(name string, config map[string]string) error {
+		if name == "root" && config["pool"] == "" {
+			return fmt.Errorf("Missing pool")
+		}
+
+		for k := range config {
+			if k != "type" && k != "path" && k != "pool" {
+				return fmt.Errorf("Device validation failed for %q: %w", name, testOptionError{option: k})
+			}
+		}
+
+		return nil
+	}
+
+	errs := ValidateDevices(devices, validator)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "devices/eth0/mtu", errs[0].Path)
+	assert.Equal(t, `Device validation failed for "eth0": Invalid device option "mtu"`, errs[0].Message)
+	assert.Equal(t, "devices/eth1/unknown", errs[1].Path)
+
+	errs = ValidateDevices(map[string]map[string]string{"root": {"type": "disk", "path": "/"}}, validator)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "devices/root", errs[0].Path)
+	assert.EqualError(t, errs.Err(), "Missing pool")
+
+	assert.NoError(t, ValidateDevices(map[string]map[string]string{"root": devices["root"]}, validator).Err())
+}
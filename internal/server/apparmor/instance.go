@@ -6,6 +6,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/lxc/incus/v6/internal/server/cgroup"
@@ -110,6 +111,46 @@ func InstanceDelete(sysOS *sys.OS, inst instance) error {
 	return deleteProfile(sysOS, InstanceProfileName(inst), instanceProfileFilename(inst))
 }
 
+// rawIncludeDirs are the directories searched for includes using the <path> syntax.
+var rawIncludeDirs = []string{"/etc/apparmor.d", "/usr/share/apparmor.d"}
+
+// rawIncludeRule matches an include rule, capturing the optional "if exists", the opening delimiter and the path.
+var rawIncludeRule = regexp.MustCompile(`^#?include\s+(if\s+exists\s+)?([<"])([^>"]+)[>"]\s*$`)
+
+// rawIncludesCheck checks that the files included by raw.apparmor exist, so that a missing one is reported
+// clearly rather than through the output of the parser. Relative paths using the "path" syntax aren't checked.
+func rawIncludesCheck(rawApparmor string) error {
+	for i, line := range strings.Split(rawApparmor, "\n") {
+		match := rawIncludeRule.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil || match[1] != "" {
+			continue
+		}
+
+		path := match[3]
+		if match[2] == "\"" {
+			if !filepath.IsAbs(path) || util.PathExists(path) {
+				continue
+			}
+
+			return fmt.Errorf("Included file %q on line %d of raw.apparmor doesn't exist", path, i+1)
+		}
+
+		found := false
+		for _, dir := range rawIncludeDirs {
+			if util.PathExists(filepath.Join(dir, path)) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("Included file <%s> on line %d of raw.apparmor doesn't exist", path, i+1)
+		}
+	}
+
+	return nil
+}
+
 // instanceProfileGenerate generates instance apparmor profile policy file.
 func instanceProfileGenerate(sysOS *sys.OS, inst instance, extraBinaries []string) error {
 	/* In order to avoid forcing a profile parse (potentially slow) on
@@ -150,6 +191,11 @@ func instanceProfile(sysOS *sys.OS, inst instance, extraBinaries []string) (stri
 	rawContent := ""
 	rawApparmor, ok := inst.ExpandedConfig()["raw.apparmor"]
 	if ok {
+		err := rawIncludesCheck(rawApparmor)
+		if err != nil {
+			return "", err
+		}
+
 		for _, line := range strings.Split(strings.Trim(rawApparmor, "\n"), "\n") {
 			rawContent += fmt.Sprintf("  %s\n", line)
 		}
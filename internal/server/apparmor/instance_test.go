@@ -0,0 +1,53 @@
+package apparmor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRawIncludesCheck(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.MkdirAll(filepath.Join(dir, "abstractions"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "abstractions", "base"), nil, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original := rawIncludeDirs
+	rawIncludeDirs = []string{filepath.Join(dir, "missing"), dir}
+	t.Cleanup(func() { rawIncludeDirs = original })
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "Rules", value: "mount fstype=nfs,\n/srv/** rw,\n"},
+		{name: "Existing include", value: "#include <abstractions/base>\n"},
+		{name: "Existing include without hash", value: "  include <abstractions/base>\n"},
+		{name: "Missing include", value: "#include <abstractions/missing>\n", wantErr: true},
+		{name: "Missing optional include", value: "include if exists <abstractions/missing>\n"},
+		{name: "Existing absolute include", value: "#include \"" + filepath.Join(dir, "abstractions", "base") + "\"\n"},
+		{name: "Missing absolute include", value: "#include \"" + filepath.Join(dir, "missing") + "\"\n", wantErr: true},
+		{name: "Relative include", value: "#include \"local/missing\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rawIncludesCheck(tt.value)
+			if tt.wantErr && err == nil {
+				t.Fatal("Expected an error")
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
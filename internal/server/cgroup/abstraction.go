@@ -675,6 +675,26 @@ func (cg *CGroup) SetBlkioWeight(limit int64) error {
 	return ErrUnknownVersion
 }
 
+// SetBlkioDeviceWeight sets the specified I/O weight for a device, overriding the default weight for it.
+// A weight of 0 removes the override, making the device use the default weight again.
+func (cg *CGroup) SetBlkioDeviceWeight(dev string, weight int64) error {
+	version := cgControllers["blkio"]
+	switch version {
+	case Unavailable:
+		return ErrControllerMissing
+	case V1:
+		return cg.rw.Set(version, "blkio", "blkio.weight_device", fmt.Sprintf("%s %d", dev, weight))
+	case V2:
+		if weight == 0 {
+			return cg.rw.Set(version, "io", "io.weight", fmt.Sprintf("%s default", dev))
+		}
+
+		return cg.rw.Set(version, "io", "io.weight", fmt.Sprintf("%s %d", dev, weight))
+	}
+
+	return ErrUnknownVersion
+}
+
 // SetBlkioLimit sets the specified read or write limit for a device.
 func (cg *CGroup) SetBlkioLimit(dev string, oType string, uType string, limit int64) error {
 	if !slices.Contains([]string{"read", "write"}, oType) {
@@ -0,0 +1,22 @@
+package cgroup
+
+import (
+	"strconv"
+)
+
+// ParseDiskPriority converts a disk I/O priority (0-10) into a blkio weight.
+// The resulting weight is valid for both blkio.weight on cgroup1 and io.weight on cgroup2.
+// Returns 0 when no priority is set.
+func ParseDiskPriority(priority string) (int64, error) {
+	if priority == "" {
+		return 0, nil
+	}
+
+	priorityInt, err := strconv.ParseInt(priority, 10, 64)
+	if err != nil {
+		return -1, err
+	}
+
+	// Minimum valid weight is 10.
+	return max(priorityInt*100, 10), nil
+}
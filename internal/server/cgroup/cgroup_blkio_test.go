@@ -0,0 +1,81 @@
+package cgroup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDiskPriority(t *testing.T) {
+	tests := []struct {
+		priority string
+		want     int64
+		wantErr  bool
+	}{
+		{priority: "", want: 0},
+		{priority: "0", want: 10},
+		{priority: "1", want: 100},
+		{priority: "5", want: 500},
+		{priority: "7", want: 700},
+		{priority: "10", want: 1000},
+		{priority: "high", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.priority, func(t *testing.T) {
+			weight, err := ParseDiskPriority(tt.priority)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, weight)
+		})
+	}
+}
+
+// testReadWriter records the values written to the cgroup.
+type testReadWriter struct {
+	values map[string]string
+}
+
+func (rw *testReadWriter) Get(backend Backend, controller string, key string) (string, error) {
+	return rw.values[key], nil
+}
+
+func (rw *testReadWriter) Set(backend Backend, controller string, key string, value string) error {
+	rw.values[key] = value
+	return nil
+}
+
+func TestSetBlkioDeviceWeight(t *testing.T) {
+	original := cgControllers
+	t.Cleanup(func() { cgControllers = original })
+
+	tests := []struct {
+		backend Backend
+		weight  int64
+		key     string
+		want    string
+	}{
+		{backend: V1, weight: 500, key: "blkio.weight_device", want: "8:0 500"},
+		{backend: V1, weight: 0, key: "blkio.weight_device", want: "8:0 0"},
+		{backend: V2, weight: 500, key: "io.weight", want: "8:0 500"},
+		{backend: V2, weight: 0, key: "io.weight", want: "8:0 default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			cgControllers = map[string]Backend{"blkio": tt.backend}
+
+			rw := &testReadWriter{values: map[string]string{}}
+			cg, err := New(rw)
+			assert.NoError(t, err)
+
+			err = cg.SetBlkioDeviceWeight("8:0", tt.weight)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, rw.values[tt.key])
+		})
+	}
+}
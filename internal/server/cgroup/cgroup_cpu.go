@@ -90,3 +90,28 @@ func ParseCPU(cpuAllowance string, cpuPriority string) (int64, int64, int64, err
 
 	return cpuShares, cpuCfsQuota, cpuCfsPeriod, nil
 }
+
+// ParseCPUWeight converts a cgroup2 CPU weight (1-10000) into the value expected by SetCPUShare.
+// On cgroup1, the weight is scaled to cpu.shares so that the default weight of 100 maps to the default of 1024 shares.
+// Returns 0 when no weight is set.
+func ParseCPUWeight(cpuWeight string) (int64, error) {
+	if cpuWeight == "" {
+		return 0, nil
+	}
+
+	weight, err := strconv.ParseInt(cpuWeight, 10, 64)
+	if err != nil {
+		return -1, err
+	}
+
+	if weight < 1 || weight > 10000 {
+		return -1, fmt.Errorf("Invalid CPU weight %d (must be between 1 and 10000)", weight)
+	}
+
+	if cgControllers["cpu"] == V2 {
+		return weight, nil
+	}
+
+	// The minimum value of cpu.shares is 2.
+	return max(weight*1024/100, 2), nil
+}
@@ -0,0 +1,45 @@
+package cgroup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCPUWeight(t *testing.T) {
+	original := cgControllers
+	t.Cleanup(func() { cgControllers = original })
+
+	tests := []struct {
+		backend Backend
+		weight  string
+		want    int64
+		wantErr bool
+	}{
+		{backend: V2, weight: "", want: 0},
+		{backend: V2, weight: "1", want: 1},
+		{backend: V2, weight: "100", want: 100},
+		{backend: V2, weight: "10000", want: 10000},
+		{backend: V2, weight: "0", wantErr: true},
+		{backend: V2, weight: "10001", wantErr: true},
+		{backend: V2, weight: "high", wantErr: true},
+		{backend: V1, weight: "1", want: 10},
+		{backend: V1, weight: "100", want: 1024},
+		{backend: V1, weight: "10000", want: 102400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.weight, func(t *testing.T) {
+			cgControllers = map[string]Backend{"cpu": tt.backend}
+
+			weight, err := ParseCPUWeight(tt.weight)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, weight)
+		})
+	}
+}
@@ -1004,46 +1004,6 @@ ORDER BY instances_snapshots.creation_date, instances_snapshots.id
 	return result, nil
 }
 
-// GetNextInstanceSnapshotIndex returns the index that the next snapshot of the
-// instance with the given name and pattern should have.
-func (c *ClusterTx) GetNextInstanceSnapshotIndex(ctx context.Context, project string, name string, pattern string) int {
-	q := `
-SELECT instances_snapshots.name
-  FROM instances_snapshots
-  JOIN instances ON instances.id = instances_snapshots.instance_id
-  JOIN projects ON projects.id = instances.project_id
-WHERE projects.name=? AND instances.name=?
-ORDER BY instances_snapshots.creation_date, instances_snapshots.id
-`
-	var numstr string
-	inargs := []any{project, name}
-	outfmt := []any{numstr}
-
-	results, err := queryScan(ctx, c, q, inargs, outfmt)
-	if err != nil {
-		return 0
-	}
-
-	max := 0
-
-	for _, r := range results {
-		snapOnlyName := r[0].(string)
-		fields := strings.SplitN(pattern, "%d", 2)
-
-		var num int
-		count, err := fmt.Sscanf(snapOnlyName, fmt.Sprintf("%s%%d%s", fields[0], fields[1]), &num)
-		if err != nil || count != 1 {
-			continue
-		}
-
-		if num >= max {
-			max = num + 1
-		}
-	}
-
-	return max
-}
-
 // DeleteReadyStateFromLocalInstances deletes the volatile.last_state.ready config key
 // from all local instances.
 func (c *ClusterTx) DeleteReadyStateFromLocalInstances(ctx context.Context) error {
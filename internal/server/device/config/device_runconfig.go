@@ -31,6 +31,7 @@ type MountEntryItem struct {
 	OwnerShift string      // Ownership shifting mode, use constants MountOwnerShiftNone, MountOwnerShiftStatic or MountOwnerShiftDynamic.
 	Limits     *DiskLimits // Disk limits.
 	Size       int64       // Expected disk size in bytes.
+	Remount    bool        // Whether to only update the read-only state ("ro" option) of an existing mount.
 }
 
 // RootFSEntryItem represents the root filesystem options for an Instance.
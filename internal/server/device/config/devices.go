@@ -9,6 +9,27 @@ import (
 	"github.com/lxc/incus/v6/shared/util"
 )
 
+// OptionError is returned when validation of a specific device option fails.
+type OptionError struct {
+	Option string
+	Err    error
+}
+
+// Error returns the validation error message.
+func (e OptionError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e OptionError) Unwrap() error {
+	return e.Err
+}
+
+// DeviceOption returns the name of the device option which failed validation.
+func (e OptionError) DeviceOption() string {
+	return e.Option
+}
+
 // Device represents an instance device.
 type Device map[string]string
 
@@ -25,7 +46,7 @@ func (device Device) Validate(rules map[string]func(value string) error) error {
 		checkedFields[k] = struct{}{} // Mark field as checked.
 		err := validator(device[k])
 		if err != nil {
-			return fmt.Errorf("Invalid value for device option %q: %w", k, err)
+			return OptionError{Option: k, Err: fmt.Errorf("Invalid value for device option %q: %w", k, err)}
 		}
 	}
 
@@ -59,7 +80,18 @@ func (device Device) Validate(rules map[string]func(value string) error) error {
 			continue
 		}
 
-		return fmt.Errorf("Invalid device option %q", k)
+		// The deprecated "optional" property is only supported by some device types, point the
+		// user towards "required" where available, unless it was only used to make the device required.
+		if k == "optional" && !util.IsFalse(device[k]) {
+			_, hasRequired := rules["required"]
+			if hasRequired {
+				return OptionError{Option: k, Err: fmt.Errorf(`Invalid device option %q, use "required=false" instead`, k)}
+			}
+
+			return OptionError{Option: k, Err: fmt.Errorf("Invalid device option %q, device type %q cannot be made optional", k, device["type"])}
+		}
+
+		return OptionError{Option: k, Err: fmt.Errorf("Invalid device option %q", k)}
 	}
 
 	return nil
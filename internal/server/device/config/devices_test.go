@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -49,3 +50,63 @@ func TestSortableDevices(t *testing.T) {
 	result = devices.Reversed()
 	assert.Equal(t, expectedReversed, result)
 }
+
+func TestDeviceValidateOptional(t *testing.T) {
+	rulesWithRequired := map[string]func(value string) error{
+		"path":     func(value string) error { return nil },
+		"required": func(value string) error { return nil },
+	}
+
+	rulesWithoutRequired := map[string]func(value string) error{
+		"name": func(value string) error { return nil },
+	}
+
+	// Devices supporting "optional" list it in their rules.
+	rulesWithOptional := map[string]func(value string) error{
+		"path":     func(value string) error { return nil },
+		"required": func(value string) error { return nil },
+		"optional": func(value string) error { return nil },
+	}
+
+	err := Device{"type": "disk", "path": "/foo", "optional": "true"}.Validate(rulesWithOptional)
+	assert.NoError(t, err)
+
+	err = Device{"type": "unix-char", "path": "/dev/foo", "optional": "true"}.Validate(rulesWithRequired)
+	assert.EqualError(t, err, `Invalid device option "optional", use "required=false" instead`)
+
+	err = Device{"type": "nic", "name": "eth0", "optional": "true"}.Validate(rulesWithoutRequired)
+	assert.EqualError(t, err, `Invalid device option "optional", device type "nic" cannot be made optional`)
+
+	// Explicitly disabling "optional" isn't pointed towards "required=false".
+	err = Device{"type": "unix-char", "path": "/dev/foo", "optional": "false"}.Validate(rulesWithRequired)
+	assert.EqualError(t, err, `Invalid device option "optional"`)
+
+	err = Device{"type": "nic", "name": "eth0", "optional": "false"}.Validate(rulesWithoutRequired)
+	assert.EqualError(t, err, `Invalid device option "optional"`)
+
+	err = Device{"type": "nic", "name": "eth0", "foo": "bar"}.Validate(rulesWithoutRequired)
+	assert.EqualError(t, err, `Invalid device option "foo"`)
+}
+
+func TestDeviceValidateOptionError(t *testing.T) {
+	rules := map[string]func(value string) error{
+		"mtu": func(value string) error {
+			if value != "" {
+				return errors.New("Invalid MTU")
+			}
+
+			return nil
+		},
+	}
+
+	var optionErr OptionError
+
+	err := Device{"type": "nic", "mtu": "foo"}.Validate(rules)
+	assert.ErrorAs(t, err, &optionErr)
+	assert.Equal(t, "mtu", optionErr.DeviceOption())
+	assert.EqualError(t, err, `Invalid value for device option "mtu": Invalid MTU`)
+
+	err = Device{"type": "nic", "foo": "bar"}.Validate(rules)
+	assert.ErrorAs(t, err, &optionErr)
+	assert.Equal(t, "foo", optionErr.DeviceOption())
+}
@@ -43,6 +43,8 @@ func newByType(state *state.State, projectName string, conf deviceConfig.Device)
 			dev = &nicSRIOV{}
 		case "ovn":
 			dev = &nicOVN{}
+		case "none":
+			dev = &none{}
 		}
 
 	case "infiniband":
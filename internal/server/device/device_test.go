@@ -0,0 +1,45 @@
+package device
+
+import (
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// testInstance is a minimal instance for device tests.
+// Only the methods used by the tests are implemented, calling any other method panics.
+type testInstance struct {
+	instance.Instance
+
+	instanceType instancetype.Type
+	initPID      int
+}
+
+func (i *testInstance) Name() string {
+	return "c1"
+}
+
+func (i *testInstance) Project() api.Project {
+	return api.Project{Name: api.ProjectDefaultName}
+}
+
+func (i *testInstance) Type() instancetype.Type {
+	return i.instanceType
+}
+
+func (i *testInstance) InitPID() int {
+	return i.initPID
+}
+
+func (i *testInstance) ExpandedConfig() map[string]string {
+	return map[string]string{}
+}
+
+func (i *testInstance) ExpandedDevices() deviceConfig.Devices {
+	return deviceConfig.Devices{}
+}
+
+func (i *testInstance) LocalDevices() deviceConfig.Devices {
+	return deviceConfig.Devices{}
+}
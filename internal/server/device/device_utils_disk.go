@@ -1,9 +1,12 @@
 package device
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/exec"
@@ -93,17 +96,36 @@ func IsBlockdev(path string) bool {
 	return false
 }
 
-// DiskMount mounts a disk device.
-func DiskMount(srcPath string, dstPath string, recursive bool, propagation string, mountOptions []string, fsName string) error {
-	var err error
+// diskQcow2Magic is the header signature of qcow2 images.
+var diskQcow2Magic = []byte{'Q', 'F', 'I', 0xfb}
 
-	flags, mountOptionsStr := linux.ResolveMountOptions(mountOptions)
+// diskIsQcow2 returns whether the file at path starts with the qcow2 image signature.
+func diskIsQcow2(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+
+	defer func() { _ = f.Close() }()
 
-	var readonly bool
-	if slices.Contains(mountOptions, "ro") {
-		readonly = true
+	header := make([]byte, len(diskQcow2Magic))
+	_, err = io.ReadFull(f, header)
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+
+		return false, err
 	}
 
+	return bytes.Equal(header, diskQcow2Magic), nil
+}
+
+// diskMountFlags computes the mount flags and data used by DiskMount.
+// It also returns whether the mount must be remounted read-only, as bind mounts ignore the read-only flag.
+func diskMountFlags(recursive bool, propagation string, mountOptions []string, fsName string) (uintptr, string, bool, error) {
+	flags, mountOptionsStr := linux.ResolveMountOptions(mountOptions)
+
 	// Detect the filesystem
 	if fsName == "none" {
 		flags |= unix.MS_BIND
@@ -128,7 +150,7 @@ func DiskMount(srcPath string, dstPath string, recursive bool, propagation strin
 		case "runbindable":
 			flags |= unix.MS_UNBINDABLE | unix.MS_REC
 		default:
-			return fmt.Errorf("Invalid propagation mode %q", propagation)
+			return 0, "", false, fmt.Errorf("Invalid propagation mode %q", propagation)
 		}
 	}
 
@@ -136,6 +158,159 @@ func DiskMount(srcPath string, dstPath string, recursive bool, propagation strin
 		flags |= unix.MS_REC
 	}
 
+	remountReadonly := slices.Contains(mountOptions, "ro") && flags&unix.MS_BIND == unix.MS_BIND
+
+	return flags, mountOptionsStr, remountReadonly, nil
+}
+
+// diskSubMounts returns the mount points at or below path which are reachable through it, in the order listed
+// by the mountinfo content. Mounts covered by another mount, either at the same path or on a parent path, are
+// skipped as they can't be reached through their mount point.
+func diskSubMounts(mountinfo io.Reader, path string) ([]string, error) {
+	unescape := strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`)
+
+	type mountEntry struct {
+		id         string
+		parentID   string
+		mountPoint string
+	}
+
+	entries := []mountEntry{}
+	scanner := bufio.NewScanner(mountinfo)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+
+		mountPoint := unescape.Replace(fields[4])
+		if mountPoint == path || strings.HasPrefix(mountPoint, strings.TrimSuffix(path, "/")+"/") {
+			entries = append(entries, mountEntry{id: fields[0], parentID: fields[1], mountPoint: mountPoint})
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	// A mount is covered when another mount has it as parent and uses the same mount point.
+	covered := map[string]bool{}
+	for _, entry := range entries {
+		for _, parent := range entries {
+			if parent.id == entry.parentID && parent.mountPoint == entry.mountPoint {
+				covered[parent.id] = true
+			}
+		}
+	}
+
+	// A mount is reachable when it isn't covered and either is mounted at path or has a reachable parent.
+	reachable := map[string]bool{}
+	mounts := []string{}
+	for {
+		added := false
+		for _, entry := range entries {
+			if reachable[entry.id] || covered[entry.id] {
+				continue
+			}
+
+			if entry.mountPoint != path && !reachable[entry.parentID] {
+				continue
+			}
+
+			reachable[entry.id] = true
+			added = true
+		}
+
+		if !added {
+			break
+		}
+	}
+
+	for _, entry := range entries {
+		if reachable[entry.id] {
+			mounts = append(mounts, entry.mountPoint)
+		}
+	}
+
+	return mounts, nil
+}
+
+// diskStatfsMountFlags returns the per-mount flags matching the statfs flags of a mount, so that a bind remount
+// keeps them.
+func diskStatfsMountFlags(statfsFlags int64) uintptr {
+	flagMap := map[int64]uintptr{
+		unix.ST_RDONLY:     unix.MS_RDONLY,
+		unix.ST_NOSUID:     unix.MS_NOSUID,
+		unix.ST_NODEV:      unix.MS_NODEV,
+		unix.ST_NOEXEC:     unix.MS_NOEXEC,
+		unix.ST_NOATIME:    unix.MS_NOATIME,
+		unix.ST_NODIRATIME: unix.MS_NODIRATIME,
+		unix.ST_RELATIME:   unix.MS_RELATIME,
+	}
+
+	var flags uintptr
+	for statfsFlag, mountFlag := range flagMap {
+		if statfsFlags&statfsFlag != 0 {
+			flags |= mountFlag
+		}
+	}
+
+	return flags
+}
+
+// diskMountReadonlyRecursive makes a bind mount and all its sub-mounts read-only.
+// This uses mount_setattr when supported by the kernel and otherwise remounts each of the mounts read-only,
+// keeping their other flags.
+func diskMountReadonlyRecursive(path string) error {
+	err := unix.MountSetattr(-1, path, unix.AT_RECURSIVE, &unix.MountAttr{Attr_set: unix.MOUNT_ATTR_RDONLY})
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, unix.ENOSYS) {
+		return fmt.Errorf("Unable to make %q and its sub-mounts read-only: %w", path, err)
+	}
+
+	// Fallback for kernels older than 5.12.
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	mounts, err := diskSubMounts(f, path)
+	if err != nil {
+		return fmt.Errorf("Failed listing the sub-mounts of %q: %w", path, err)
+	}
+
+	for _, mount := range mounts {
+		var st unix.Statfs_t
+
+		err = unix.Statfs(mount, &st)
+		if err != nil {
+			return fmt.Errorf("Failed getting the mount flags of %q: %w", mount, err)
+		}
+
+		flags := diskStatfsMountFlags(st.Flags) | unix.MS_RDONLY | unix.MS_BIND | unix.MS_REMOUNT
+		err = unix.Mount("", mount, "none", flags, "")
+		if err != nil {
+			return fmt.Errorf("Unable to mount %q in readonly mode: %w", mount, err)
+		}
+	}
+
+	return nil
+}
+
+// DiskMount mounts a disk device.
+// When both recursive and read-only, all the sub-mounts of the source are made read-only too.
+func DiskMount(srcPath string, dstPath string, recursive bool, propagation string, mountOptions []string, fsName string) error {
+	flags, mountOptionsStr, remountReadonly, err := diskMountFlags(recursive, propagation, mountOptions, fsName)
+	if err != nil {
+		return err
+	}
+
 	// Mount the filesystem
 	err = unix.Mount(srcPath, dstPath, fsName, uintptr(flags), mountOptionsStr)
 	if err != nil {
@@ -143,7 +318,12 @@ func DiskMount(srcPath string, dstPath string, recursive bool, propagation strin
 	}
 
 	// Remount bind mounts in readonly mode if requested
-	if readonly && flags&unix.MS_BIND == unix.MS_BIND {
+	if remountReadonly && recursive {
+		err = diskMountReadonlyRecursive(dstPath)
+		if err != nil {
+			return err
+		}
+	} else if remountReadonly {
 		flags = unix.MS_RDONLY | unix.MS_BIND | unix.MS_REMOUNT
 		err = unix.Mount("", dstPath, fsName, uintptr(flags), "")
 		if err != nil {
@@ -179,6 +359,27 @@ func DiskMountClear(mntPath string) error {
 	return nil
 }
 
+// diskParseCephSource parses a "ceph:<pool>/<volume>" or "cephfs:<fs>/<path>" disk source.
+// Returns the pool or filesystem name and the volume name or path within the filesystem.
+func diskParseCephSource(source string) (string, string, error) {
+	prefix, value, _ := strings.Cut(source, ":")
+	if prefix != "ceph" && prefix != "cephfs" {
+		return "", "", fmt.Errorf("Invalid Ceph source %q, must start with \"ceph:\" or \"cephfs:\"", source)
+	}
+
+	name, path, found := strings.Cut(value, "/")
+	if !found || name == "" {
+		return "", "", fmt.Errorf("Invalid Ceph source %q, must be of the form \"%s:<name>/<path>\"", source, prefix)
+	}
+
+	// RBD sources must point to a volume while CephFS sources may use the root of the filesystem.
+	if prefix == "ceph" && path == "" {
+		return "", "", fmt.Errorf("Invalid Ceph source %q, missing the volume name", source)
+	}
+
+	return name, path, nil
+}
+
 func diskCephRbdMap(clusterName string, userName string, poolName string, volumeName string) (string, error) {
 	devPath, err := subprocess.RunCommand(
 		"rbd",
@@ -263,6 +464,21 @@ func diskCephfsOptions(clusterName string, userName string, fsName string, fsPat
 	return srcPath, fsOptions, nil
 }
 
+// diskOverlayOwner returns the host UID and GID of the root user of a container using the supplied idmap.
+// A nil idmap means the container is privileged and so the host root user is returned.
+func diskOverlayOwner(idmapSet *idmap.Set) (int64, int64, error) {
+	if idmapSet == nil {
+		return 0, 0, nil
+	}
+
+	uid, gid := idmapSet.ShiftIntoNS(0, 0)
+	if uid == -1 || gid == -1 {
+		return -1, -1, fmt.Errorf("The container's root user isn't mapped")
+	}
+
+	return uid, gid, nil
+}
+
 // diskAddRootUserNSEntry takes a set of idmap entries, and adds host -> userns root uid/gid mappings if needed.
 // Returns the supplied idmap entries with any added root entries.
 func diskAddRootUserNSEntry(idmaps []idmap.Entry, hostRootID int64) []idmap.Entry {
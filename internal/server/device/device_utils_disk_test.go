@@ -1,9 +1,13 @@
 package device
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
 
 	"github.com/lxc/incus/v6/shared/idmap"
 )
@@ -90,3 +94,169 @@ func TestDiskAddRootUserNSEntry(t *testing.T) {
 
 	assert.Equal(t, idmaps, expected)
 }
+
+func TestDiskOverlayOwner(t *testing.T) {
+	// Privileged containers use the host root user.
+	uid, gid, err := diskOverlayOwner(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), uid)
+	assert.Equal(t, int64(0), gid)
+
+	// Unprivileged containers use their mapped root user.
+	idmapSet := &idmap.Set{Entries: []idmap.Entry{
+		{IsUID: true, HostID: 1000000, NSID: 0, MapRange: 65536},
+		{IsGID: true, HostID: 2000000, NSID: 0, MapRange: 65536},
+	}}
+
+	uid, gid, err = diskOverlayOwner(idmapSet)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000000), uid)
+	assert.Equal(t, int64(2000000), gid)
+
+	// Fail if the root user isn't mapped.
+	idmapSet = &idmap.Set{Entries: []idmap.Entry{
+		{IsUID: true, IsGID: true, HostID: 1000000, NSID: 1000, MapRange: 65536},
+	}}
+
+	_, _, err = diskOverlayOwner(idmapSet)
+	assert.Error(t, err)
+}
+
+func TestDiskIsQcow2(t *testing.T) {
+	dir := t.TempDir()
+
+	qcow2Path := filepath.Join(dir, "disk.qcow2")
+	err := os.WriteFile(qcow2Path, []byte("QFI\xfb\x00\x00\x00\x03"), 0600)
+	assert.NoError(t, err)
+
+	rawPath := filepath.Join(dir, "disk.img")
+	err = os.WriteFile(rawPath, make([]byte, 512), 0600)
+	assert.NoError(t, err)
+
+	shortPath := filepath.Join(dir, "short")
+	err = os.WriteFile(shortPath, []byte("QF"), 0600)
+	assert.NoError(t, err)
+
+	isQcow2, err := diskIsQcow2(qcow2Path)
+	assert.NoError(t, err)
+	assert.True(t, isQcow2)
+
+	isQcow2, err = diskIsQcow2(rawPath)
+	assert.NoError(t, err)
+	assert.False(t, isQcow2)
+
+	isQcow2, err = diskIsQcow2(shortPath)
+	assert.NoError(t, err)
+	assert.False(t, isQcow2)
+
+	_, err = diskIsQcow2(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestDiskParseCephSource(t *testing.T) {
+	tests := []struct {
+		source   string
+		wantName string
+		wantPath string
+		wantErr  bool
+	}{
+		{source: "ceph:rbd/vol1", wantName: "rbd", wantPath: "vol1"},
+		{source: "ceph:rbd/ns/vol1", wantName: "rbd", wantPath: "ns/vol1"},
+		{source: "cephfs:fs1/data/www", wantName: "fs1", wantPath: "data/www"},
+		{source: "cephfs:fs1/", wantName: "fs1", wantPath: ""},
+		{source: "ceph:rbd", wantErr: true},
+		{source: "ceph:rbd/", wantErr: true},
+		{source: "ceph:/vol1", wantErr: true},
+		{source: "cephfs:fs1", wantErr: true},
+		{source: "/dev/sdb", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			name, path, err := diskParseCephSource(tt.source)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantPath, path)
+		})
+	}
+}
+
+func TestDiskMountFlags(t *testing.T) {
+	tests := []struct {
+		name            string
+		recursive       bool
+		propagation     string
+		options         []string
+		fsName          string
+		flags           uintptr
+		data            string
+		remountReadonly bool
+		wantErr         bool
+	}{
+		{name: "Bind", fsName: "none", flags: unix.MS_BIND},
+		{name: "Recursive bind", recursive: true, fsName: "none", flags: unix.MS_BIND | unix.MS_REC},
+		{name: "Read-only bind", options: []string{"ro"}, fsName: "none", flags: unix.MS_BIND | unix.MS_RDONLY, remountReadonly: true},
+		{name: "Recursive read-only bind", recursive: true, options: []string{"ro"}, fsName: "none", flags: unix.MS_BIND | unix.MS_REC | unix.MS_RDONLY, remountReadonly: true},
+		{name: "Read-only filesystem", options: []string{"ro", "discard"}, fsName: "ext4", flags: unix.MS_RDONLY, data: "discard"},
+		{name: "Propagation", propagation: "rslave", fsName: "none", flags: unix.MS_BIND | unix.MS_SLAVE | unix.MS_REC},
+		{name: "Invalid propagation", propagation: "foo", fsName: "none", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, data, remountReadonly, err := diskMountFlags(tt.recursive, tt.propagation, tt.options, tt.fsName)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.flags, flags)
+			assert.Equal(t, tt.data, data)
+			assert.Equal(t, tt.remountReadonly, remountReadonly)
+		})
+	}
+}
+
+func TestDiskSubMounts(t *testing.T) {
+	mountinfo := `22 1 0:21 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+30 22 0:25 / /srv/data rw,relatime shared:2 - ext4 /dev/sdb1 rw
+31 30 0:26 / /srv/data/sub rw,relatime shared:3 - tmpfs tmpfs rw
+32 30 0:27 / /srv/data/with\040space rw,relatime shared:4 - tmpfs tmpfs rw
+33 22 0:28 / /srv/database rw,relatime shared:5 - tmpfs tmpfs rw
+`
+
+	mounts, err := diskSubMounts(strings.NewReader(mountinfo), "/srv/data")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/srv/data", "/srv/data/sub", "/srv/data/with space"}, mounts)
+
+	mounts, err = diskSubMounts(strings.NewReader(mountinfo), "/srv/other")
+	assert.NoError(t, err)
+	assert.Empty(t, mounts)
+
+	// Mounts covered by another mount at the same path, or on a parent path, aren't reachable.
+	mountinfo = `22 1 0:21 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+30 22 0:25 / /srv/data rw,relatime shared:2 - ext4 /dev/sdb1 rw
+31 30 0:26 / /srv/data/sub rw,relatime shared:3 - tmpfs tmpfs rw
+32 31 0:27 / /srv/data/sub/inner rw,relatime shared:4 - tmpfs tmpfs rw
+33 31 0:28 / /srv/data/sub rw,relatime shared:5 - tmpfs tmpfs rw
+34 22 0:29 / /srv/data/hidden rw,relatime shared:6 - tmpfs tmpfs rw
+35 30 0:30 / /srv/data rw,relatime shared:7 - tmpfs tmpfs rw
+36 35 0:31 / /srv/data/visible rw,relatime shared:8 - tmpfs tmpfs rw
+`
+
+	mounts, err = diskSubMounts(strings.NewReader(mountinfo), "/srv/data")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/srv/data", "/srv/data/visible"}, mounts)
+}
+
+func TestDiskStatfsMountFlags(t *testing.T) {
+	assert.Equal(t, uintptr(0), diskStatfsMountFlags(0))
+	assert.Equal(t, uintptr(unix.MS_NOSUID|unix.MS_NODEV|unix.MS_RELATIME), diskStatfsMountFlags(unix.ST_NOSUID|unix.ST_NODEV|unix.ST_RELATIME))
+	assert.Equal(t, uintptr(unix.MS_RDONLY|unix.MS_NOEXEC|unix.MS_NOATIME), diskStatfsMountFlags(unix.ST_RDONLY|unix.ST_NOEXEC|unix.ST_NOATIME))
+}
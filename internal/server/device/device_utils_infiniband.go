@@ -1,18 +1,33 @@
 package device
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"net"
+	"os"
 	"strings"
 
+	"github.com/lxc/incus/v6/internal/linux"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/internal/server/state"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/util"
 )
 
 // IBDevPrefix Infiniband devices prefix.
 const IBDevPrefix = "infiniband.unix"
 
+// infinibandRDMACMPath is the host-wide RDMA connection manager device.
+const infinibandRDMACMPath = "/dev/infiniband/rdma_cm"
+
+// infinibandRDMACMPrefix and infinibandRDMACMName identify the RDMA connection manager device files.
+// The device is shared by all infiniband devices of an instance, so it isn't tied to a device name.
+const (
+	infinibandRDMACMPrefix = "infiniband"
+	infinibandRDMACMName   = "rdma_cm"
+)
+
 // infinibandDevices extracts the infiniband parent device from the supplied nic list and any free
 // associated virtual functions (VFs) that are on the same card and port as the specified parent.
 // This function expects that the supplied nic list does not include VFs that are already attached
@@ -112,9 +127,89 @@ func infinibandAddDevices(s *state.State, devicesPath string, deviceName string,
 		}
 	}
 
+	// Add the RDMA connection manager device if the host provides it (requires the rdma_ucm module)
+	// and another infiniband device of the instance hasn't already added it.
+	rdmaCMPrefix := deviceJoinPath(infinibandRDMACMPrefix, infinibandRDMACMName)
+	if util.PathExists(infinibandRDMACMPath) && !UnixDeviceExists(devicesPath, rdmaCMPrefix, infinibandRDMACMPath) {
+		device := deviceConfig.Device{
+			"source": infinibandRDMACMPath,
+		}
+
+		err := unixDeviceSetup(s, devicesPath, infinibandRDMACMPrefix, infinibandRDMACMName, device, false, runConf)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// infinibandRDMACMInUse returns whether an infiniband device of the instance other than the one
+// specified still has device files, in which case the shared RDMA connection manager device must be kept.
+func infinibandRDMACMInUse(devicesPath string, deviceName string) (bool, error) {
+	dents, err := os.ReadDir(devicesPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	ibPrefix := linux.PathNameEncode(IBDevPrefix + ".")
+	ourPrefix := linux.PathNameEncode(deviceJoinPath(IBDevPrefix, deviceName) + ".")
+
+	for _, ent := range dents {
+		devName := ent.Name()
+		if strings.HasPrefix(devName, ibPrefix) && !strings.HasPrefix(devName, ourPrefix) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// infinibandRemoveDevices configures the supplied runConfig to remove the UNIX devices of the
+// specified infiniband device from the instance, including the shared RDMA connection manager
+// device if no other infiniband device is using it.
+func infinibandRemoveDevices(devicesPath string, deviceName string, runConf *deviceConfig.RunConfig) error {
+	err := unixDeviceRemove(devicesPath, IBDevPrefix, deviceName, "", runConf)
+	if err != nil {
+		return err
+	}
+
+	inUse, err := infinibandRDMACMInUse(devicesPath, deviceName)
+	if err != nil {
+		return err
+	}
+
+	if inUse {
+		return nil
+	}
+
+	return unixDeviceRemove(devicesPath, infinibandRDMACMPrefix, infinibandRDMACMName, "", runConf)
+}
+
+// infinibandDeleteFiles removes the host side UNIX device files of the specified infiniband device,
+// including the shared RDMA connection manager device if no other infiniband device is using it.
+func infinibandDeleteFiles(s *state.State, devicesPath string, deviceName string) error {
+	err := unixDeviceDeleteFiles(s, devicesPath, IBDevPrefix, deviceName, "")
+	if err != nil {
+		return err
+	}
+
+	inUse, err := infinibandRDMACMInUse(devicesPath, deviceName)
+	if err != nil {
+		return err
+	}
+
+	if inUse {
+		return nil
+	}
+
+	return unixDeviceDeleteFiles(s, devicesPath, infinibandRDMACMPrefix, infinibandRDMACMName, "")
+}
+
 // infinibandValidMAC validates an infiniband MAC address. Supports both short and long variants,
 // e.g. "4a:c8:f9:1b:aa:57:ef:19" and "a0:00:0f:c0:fe:80:00:00:00:00:00:00:4a:c8:f9:1b:aa:57:ef:19".
 func infinibandValidMAC(value string) error {
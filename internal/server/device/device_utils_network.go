@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/netip"
 	"os"
+	"runtime"
 	"slices"
 	"strconv"
 	"strings"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/j-keck/arping"
 	"github.com/mdlayher/ndp"
+	"golang.org/x/sys/unix"
 
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	pcidev "github.com/lxc/incus/v6/internal/server/device/pci"
@@ -207,6 +209,32 @@ func networkRestorePhysicalNIC(hostName string, volatile map[string]string) erro
 	return nil
 }
 
+// nicEffectiveParentAndMTU returns the host device a macvlan, ipvlan or sriov NIC is attached to and the MTU it gets.
+// A set VLAN means macvlan and ipvlan NICs are attached to the VLAN interface of the parent, while for sriov NICs the
+// VLAN is applied to the VF itself. An unset MTU is inherited from the host device, whose MTU is looked up with devMTU.
+func nicEffectiveParentAndMTU(nicType string, config map[string]string, devMTU func(devName string) (uint32, error)) (string, uint32, error) {
+	parent := config["parent"]
+	if nicType != "sriov" {
+		parent = network.GetHostDevice(config["parent"], config["vlan"])
+	}
+
+	if config["mtu"] != "" {
+		mtu, err := strconv.ParseUint(config["mtu"], 10, 32)
+		if err != nil {
+			return "", 0, fmt.Errorf("Invalid MTU specified %q: %w", config["mtu"], err)
+		}
+
+		return parent, uint32(mtu), nil
+	}
+
+	mtu, err := devMTU(parent)
+	if err != nil {
+		return "", 0, fmt.Errorf("Failed getting MTU of %q: %w", parent, err)
+	}
+
+	return parent, mtu, nil
+}
+
 // networkCreateVethPair creates and configures a veth pair. It will set the hwaddr and mtu settings
 // in the supplied config to the newly created peer interface. If mtu is not specified, but parent
 // is supplied in config, then the MTU of the new peer interface will inherit the parent MTU.
@@ -214,6 +242,10 @@ func networkRestorePhysicalNIC(hostName string, volatile map[string]string) erro
 func networkCreateVethPair(hostName string, m deviceConfig.Device) (string, uint32, error) {
 	var err error
 
+	if network.InterfaceExists(hostName) {
+		return "", 0, fmt.Errorf("Host interface %q already exists", hostName)
+	}
+
 	veth := &ip.Veth{
 		Link: ip.Link{
 			Name: hostName,
@@ -304,6 +336,10 @@ func networkCreateVethPair(hostName string, m deviceConfig.Device) (string, uint
 // networkCreateTap creates and configures a TAP device.
 // Returns the MTU used.
 func networkCreateTap(hostName string, m deviceConfig.Device) (uint32, error) {
+	if network.InterfaceExists(hostName) {
+		return 0, fmt.Errorf("Host interface %q already exists", hostName)
+	}
+
 	tuntap := &ip.Tuntap{
 		Name:       hostName,
 		Mode:       "tap",
@@ -493,6 +529,21 @@ func networkNICRouteDelete(routeDev string, routes ...string) {
 	}
 }
 
+// networkValidateLimits checks that limits.max isn't combined with the direction specific limits it overrides.
+func networkValidateLimits(config deviceConfig.Device) error {
+	if config["limits.max"] == "" {
+		return nil
+	}
+
+	for _, key := range []string{"limits.ingress", "limits.egress"} {
+		if config[key] != "" {
+			return fmt.Errorf("Cannot use %q property in conjunction with %q property", key, "limits.max")
+		}
+	}
+
+	return nil
+}
+
 // networkSetupHostVethLimits applies any network rate limits to the veth device specified in the config.
 func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device, bridged bool) error {
 	var err error
@@ -599,6 +650,38 @@ func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device,
 	return nil
 }
 
+// networkInstanceNetnsDo runs the function from within the network namespace of the instance's init process.
+// Commands spawned by the function are also run within that namespace.
+func networkInstanceNetnsDo(pid int, f func() error) error {
+	if pid <= 0 {
+		return fmt.Errorf("Instance isn't running")
+	}
+
+	netns, err := os.Open(fmt.Sprintf("/proc/%d/ns/net", pid))
+	if err != nil {
+		return fmt.Errorf("Failed opening network namespace: %w", err)
+	}
+
+	defer func() { _ = netns.Close() }()
+
+	// The network namespace is that of the calling thread, so this is done from a dedicated locked
+	// thread. It isn't unlocked, so the thread is discarded once the goroutine returns.
+	chErr := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+
+		err := unix.Setns(int(netns.Fd()), unix.CLONE_NEWNET)
+		if err != nil {
+			chErr <- fmt.Errorf("Failed entering network namespace: %w", err)
+			return
+		}
+
+		chErr <- f()
+	}()
+
+	return <-chErr
+}
+
 // networkClearHostVethLimits clears any network rate limits to the veth device specified in the config.
 func networkClearHostVethLimits(d *deviceCommon) error {
 	err := d.state.Firewall.InstanceClearNetPrio(d.inst.Project().Name, d.inst.Name(), d.config["host_name"])
@@ -1031,7 +1114,7 @@ func networkSRIOVSetupContainerVFNIC(hostName string, config map[string]string)
 		}
 
 		// Try using a random MAC address and bringing interface up.
-		randMAC, err := instance.DeviceNextInterfaceHWAddr()
+		randMAC, err := instance.DeviceNextInterfaceHWAddr("")
 		if err != nil {
 			return fmt.Errorf("Failed generating random MAC for VF %q: %w", hostName, err)
 		}
@@ -0,0 +1,149 @@
+package device
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+)
+
+func TestNICValidateLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  deviceConfig.Device
+		wantErr bool
+	}{
+		{name: "No limits", config: deviceConfig.Device{}},
+		{name: "Ingress and egress", config: deviceConfig.Device{"limits.ingress": "100Mbit", "limits.egress": "1Gbit"}},
+		{name: "Max", config: deviceConfig.Device{"limits.max": "100Mbit"}},
+		{name: "Bad unit", config: deviceConfig.Device{"limits.ingress": "100MB"}, wantErr: true},
+		{name: "Max with ingress", config: deviceConfig.Device{"limits.max": "100Mbit", "limits.ingress": "10Mbit"}, wantErr: true},
+		{name: "Max with egress", config: deviceConfig.Device{"limits.max": "100Mbit", "limits.egress": "10Mbit"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &nicP2P{}
+			d.config = tt.config
+			d.config["type"] = "nic"
+			d.config["nictype"] = "p2p"
+
+			err := d.validateConfig(&testInstance{instanceType: instancetype.Container})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNICValidateHostName(t *testing.T) {
+	tests := []struct {
+		name     string
+		hostName string
+		wantErr  bool
+	}{
+		{name: "Unset", hostName: ""},
+		{name: "Valid", hostName: "veth-web01"},
+		{name: "Maximum length", hostName: "veth-0123456789"},
+		{name: "Too long", hostName: "veth-0123456789a", wantErr: true},
+		{name: "Too short", hostName: "v", wantErr: true},
+		{name: "Invalid characters", hostName: "veth/web01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &nicP2P{}
+			d.config = deviceConfig.Device{"type": "nic", "nictype": "p2p", "host_name": tt.hostName}
+
+			err := d.validateConfig(&testInstance{instanceType: instancetype.Container})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNICValidateSecurityFiltering(t *testing.T) {
+	keys := []string{"security.mac_filtering", "security.ipv4_filtering", "security.ipv6_filtering"}
+	rules := nicValidationRules(nil, keys, &testInstance{instanceType: instancetype.Container})
+
+	for _, key := range keys {
+		t.Run(key, func(t *testing.T) {
+			assert.NoError(t, rules[key](""))
+			assert.NoError(t, rules[key]("true"))
+			assert.NoError(t, rules[key]("false"))
+			assert.Error(t, rules[key]("yes please"))
+		})
+	}
+}
+
+func TestNICValidateAcceptRA(t *testing.T) {
+	rules := nicValidationRules(nil, []string{"ipv6.accept_ra"}, &testInstance{instanceType: instancetype.Container})
+
+	assert.NoError(t, rules["ipv6.accept_ra"](""))
+	assert.NoError(t, rules["ipv6.accept_ra"]("true"))
+	assert.NoError(t, rules["ipv6.accept_ra"]("false"))
+	assert.Error(t, rules["ipv6.accept_ra"]("2"))
+}
+
+func TestNICEffectiveParentAndMTU(t *testing.T) {
+	devMTU := func(devName string) (uint32, error) {
+		switch devName {
+		case "eth0":
+			return 9000, nil
+		case "eth0.10":
+			return 1500, nil
+		}
+
+		return 0, fmt.Errorf("Device %q not found", devName)
+	}
+
+	tests := []struct {
+		name       string
+		nicType    string
+		config     map[string]string
+		wantParent string
+		wantMTU    uint32
+		wantErr    bool
+	}{
+		{name: "macvlan inherited MTU", nicType: "macvlan", config: map[string]string{"parent": "eth0"}, wantParent: "eth0", wantMTU: 9000},
+		{name: "macvlan configured MTU", nicType: "macvlan", config: map[string]string{"parent": "eth0", "mtu": "1400"}, wantParent: "eth0", wantMTU: 1400},
+		{name: "macvlan VLAN", nicType: "macvlan", config: map[string]string{"parent": "eth0", "vlan": "10"}, wantParent: "eth0.10", wantMTU: 1500},
+		{name: "ipvlan inherited MTU", nicType: "ipvlan", config: map[string]string{"parent": "eth0"}, wantParent: "eth0", wantMTU: 9000},
+		{name: "ipvlan VLAN", nicType: "ipvlan", config: map[string]string{"parent": "eth0", "vlan": "10"}, wantParent: "eth0.10", wantMTU: 1500},
+		{name: "sriov inherited MTU", nicType: "sriov", config: map[string]string{"parent": "eth0"}, wantParent: "eth0", wantMTU: 9000},
+		{name: "sriov VLAN on VF", nicType: "sriov", config: map[string]string{"parent": "eth0", "vlan": "10"}, wantParent: "eth0", wantMTU: 9000},
+		{name: "Missing parent", nicType: "macvlan", config: map[string]string{"parent": "eth1"}, wantErr: true},
+		{name: "Invalid MTU", nicType: "sriov", config: map[string]string{"parent": "eth0", "mtu": "big"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent, mtu, err := nicEffectiveParentAndMTU(tt.nicType, tt.config, devMTU)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantParent, parent)
+			assert.Equal(t, tt.wantMTU, mtu)
+		})
+	}
+}
+
+func TestNICValidationRulesHWAddrGenerate(t *testing.T) {
+	rules := nicValidationRules(nil, []string{"hwaddr.generate"}, &testInstance{instanceType: instancetype.Container})
+
+	assert.NoError(t, rules["hwaddr.generate"](""))
+	assert.NoError(t, rules["hwaddr.generate"]("true"))
+	assert.NoError(t, rules["hwaddr.generate"]("false"))
+	assert.Error(t, rules["hwaddr.generate"]("never"))
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"math/rand"
 	"net/http"
 	"os"
 	"os/exec"
@@ -29,6 +30,8 @@ import (
 	"github.com/lxc/incus/v6/internal/server/project"
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
 	storageDrivers "github.com/lxc/incus/v6/internal/server/storage/drivers"
+	"github.com/lxc/incus/v6/internal/server/storage/quota"
+	localUtil "github.com/lxc/incus/v6/internal/server/util"
 	"github.com/lxc/incus/v6/internal/server/warnings"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/shared/api"
@@ -72,6 +75,7 @@ type diskBlockLimit struct {
 	readIops  int64
 	writeBps  int64
 	writeIops int64
+	weight    int64
 }
 
 // diskSourceNotFoundError error used to indicate source not found.
@@ -193,7 +197,8 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		"optional": validate.Optional(validate.IsBool), // "optional" is deprecated, replaced by "required".
 
 		// gendoc:generate(entity=devices, group=disk, key=readonly)
-		//
+		// When combined with `recursive`, all the mounts below the source path are read-only too.
+		// Changing it on a running container remounts the device in place, unless `recursive` or `overlay` is used.
 		// ---
 		//  type: bool
 		//  default: `false`
@@ -201,6 +206,16 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		//  shortdesc: Controls whether to make the mount read-only
 		"readonly": validate.Optional(validate.IsBool),
 
+		// gendoc:generate(entity=devices, group=disk, key=overlay)
+		// When combined with `readonly`, the source is used as the lower layer of an overlay and writes
+		// from the instance go to a per-instance upper layer which is discarded when the device is removed.
+		// ---
+		//  type: bool
+		//  default: `false`
+		//  required: no
+		//  shortdesc: Controls whether to layer a writable overlay on top of a read-only mount (only for containers)
+		"overlay": validate.Optional(validate.IsBool),
+
 		// gendoc:generate(entity=devices, group=disk, key=recursive)
 		//
 		// ---
@@ -211,7 +226,10 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		"recursive": validate.Optional(validate.IsBool),
 
 		// gendoc:generate(entity=devices, group=disk, key=shift)
-		//
+		// The source is mounted into unprivileged containers through an idmapped mount, which applies the
+		// container's idmap without changing the ownership of the files on the host.
+		// This requires idmapped mounts to be supported by the kernel and by the file system of the source,
+		// the device fails to start otherwise.
 		// ---
 		//  type: bool
 		//  default: `false`
@@ -251,12 +269,20 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		//  shortdesc: I/O limit in byte/s or IOPS for both read and write (same as setting both `limits.read` and `limits.write`)
 		"limits.max": validate.IsAny,
 
+		// gendoc:generate(entity=devices, group=disk, key=limits.priority)
+		// When set, this overrides the instance-wide `limits.disk.priority` for the block devices backing this disk.
+		// ---
+		//  type: integer
+		//  required: no
+		//  shortdesc: Only for containers: I/O priority of this disk between `0` and `10`
+		"limits.priority": validate.Optional(validate.IsPriority),
+
 		// gendoc:generate(entity=devices, group=disk, key=size)
 		//
 		// ---
 		//  type: string
 		//  required: no
-		//  shortdesc: Disk size in bytes (various suffixes supported, see {ref}`instances-limit-units`) - only supported for the `rootfs` (`/`)
+		//  shortdesc: Disk size in bytes (various suffixes supported, see {ref}`instances-limit-units`) - only supported for the `rootfs` (`/`) and bind-mounted host paths
 		"size": validate.Optional(validate.IsSize),
 
 		// gendoc:generate(entity=devices, group=disk, key=size.state)
@@ -362,6 +388,20 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		//  required: no
 		//  shortdesc: Only for VMs: Override the bus for the device
 		"io.bus": validate.Optional(validate.IsOneOf("nvme", "virtio-blk", "virtio-scsi", "auto", "9p", "virtiofs")),
+
+		// gendoc:generate(entity=devices, group=disk, key=io.mode)
+		// This controls the asynchronous I/O backend QEMU uses for a block device, one of:
+		// - `io_uring` (requires support from QEMU and a host kernel 5.13 or later)
+		// - `native` (requires `io.cache` to be `none`)
+		// - `threads`
+		//
+		// When unset, Incus picks the mode based on the caching mode and the backing storage.
+		// This has no effect on Ceph RBD volumes.
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: Only for VMs: Override the asynchronous I/O mode for the device
+		"io.mode": validate.Optional(validate.IsOneOf("io_uring", "native", "threads")),
 	}
 
 	err := d.config.Validate(rules)
@@ -377,6 +417,14 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("IO cache configuration cannot be applied to containers")
 	}
 
+	if instConf.Type() == instancetype.Container && d.config["io.mode"] != "" {
+		return fmt.Errorf("IO mode configuration cannot be applied to containers")
+	}
+
+	if instConf.Type() == instancetype.VM && d.config["limits.priority"] != "" {
+		return fmt.Errorf("Disk I/O priority can only be applied to containers")
+	}
+
 	if d.config["required"] != "" && d.config["optional"] != "" {
 		return fmt.Errorf(`Cannot use both "required" and deprecated "optional" properties at the same time`)
 	}
@@ -393,8 +441,8 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf(`Root disk entry must have a "pool" property set`)
 	}
 
-	if d.config["size"] != "" && d.config["path"] != "/" {
-		return fmt.Errorf("Only the root disk may have a size quota")
+	if d.config["size"] != "" && d.config["path"] != "/" && (d.config["pool"] != "" || !d.sourceIsLocalPath(d.config["source"])) {
+		return fmt.Errorf("Only the root disk and bind-mounted host paths may have a size quota")
 	}
 
 	if d.config["size.state"] != "" && d.config["path"] != "/" {
@@ -405,8 +453,22 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("The recursive option is only supported for additional bind-mounted paths")
 	}
 
-	if util.IsTrue(d.config["recursive"]) && util.IsTrue(d.config["readonly"]) {
-		return fmt.Errorf("Recursive read-only bind-mounts aren't currently supported by the kernel")
+	if util.IsTrue(d.config["overlay"]) {
+		if instConf.Type() != instancetype.Container {
+			return fmt.Errorf("The overlay option is only supported for containers")
+		}
+
+		if !util.IsTrue(d.config["readonly"]) {
+			return fmt.Errorf("The overlay option requires readonly to be enabled")
+		}
+
+		if d.config["path"] == "/" || d.config["pool"] != "" || !d.sourceIsLocalPath(d.config["source"]) {
+			return fmt.Errorf("The overlay option is only supported for bind-mounted local paths")
+		}
+
+		if util.IsTrue(d.config["recursive"]) || util.IsTrue(d.config["shift"]) {
+			return fmt.Errorf("The overlay option cannot be combined with recursive or shift")
+		}
 	}
 
 	// Check ceph options are only used when ceph or cephfs type source is specified.
@@ -414,6 +476,14 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("Invalid options ceph.cluster_name/ceph.user_name for source %q", d.config["source"])
 	}
 
+	// Check the syntax of ceph and cephfs sources.
+	if d.sourceIsCeph() || d.sourceIsCephFs() {
+		_, _, err := diskParseCephSource(d.config["source"])
+		if err != nil {
+			return err
+		}
+	}
+
 	// Check no other devices also have the same path as us. Use LocalDevices for this check so
 	// that we can check before the config is expanded or when a profile is being checked.
 	// Don't take into account the device names, only count active devices that point to the
@@ -726,12 +796,30 @@ func (d *disk) validateEnvironment() error {
 // UpdatableFields returns a list of fields that can be updated without triggering a device remove & add.
 func (d *disk) UpdatableFields(oldDevice Type) []string {
 	// Check old and new device types match.
-	_, match := oldDevice.(*disk)
+	oldDisk, match := oldDevice.(*disk)
 	if !match {
 		return []string{}
 	}
 
-	return []string{"limits.max", "limits.read", "limits.write", "size", "size.state"}
+	fields := []string{"limits.max", "limits.priority", "limits.read", "limits.write", "size", "size.state"}
+
+	// Read-only changes are applied by remounting the device in place where possible.
+	if diskCanRemount(d.inst.Type(), oldDisk.config) && diskCanRemount(d.inst.Type(), d.config) {
+		fields = append(fields, "readonly")
+	}
+
+	return fields
+}
+
+// diskCanRemount returns whether a change of the readonly option of a disk device can be applied to a running
+// instance by remounting the device in place. This is only possible for non-recursive container bind-mounts that
+// don't use an overlay.
+func diskCanRemount(instanceType instancetype.Type, config deviceConfig.Device) bool {
+	if instanceType != instancetype.Container || config["path"] == "/" {
+		return false
+	}
+
+	return util.IsFalseOrEmpty(config["recursive"]) && util.IsFalseOrEmpty(config["overlay"])
 }
 
 // Register calls mount for the disk volume (which should already be mounted) to reinitialize the reference counter
@@ -797,6 +885,10 @@ func (d *disk) Start() (*deviceConfig.RunConfig, error) {
 	var runConfig *deviceConfig.RunConfig
 
 	err := d.validateEnvironment()
+	if err == nil && d.sourceHasQuota() {
+		err = d.applySourceQuota()
+	}
+
 	if err == nil {
 		if d.inst.Type() == instancetype.VM {
 			runConfig, err = d.startVM()
@@ -827,7 +919,7 @@ func (d *disk) startContainer() (*deviceConfig.RunConfig, error) {
 	runConf.PostHooks = append(runConf.PostHooks, func() error {
 		runConf := deviceConfig.RunConfig{}
 
-		err := d.generateLimits(&runConf)
+		err := d.generateLimits(&runConf, false)
 		if err != nil {
 			return err
 		}
@@ -905,8 +997,11 @@ func (d *disk) startContainer() (*deviceConfig.RunConfig, error) {
 			}
 		}
 
+		// With an overlay, the source is mounted read-only but the instance gets a writable view of it.
+		isOverlay := util.IsTrue(d.config["overlay"])
+
 		options := []string{}
-		if isReadOnly {
+		if isReadOnly && !isOverlay {
 			options = append(options, "ro")
 		}
 
@@ -954,6 +1049,19 @@ func (d *disk) startContainer() (*deviceConfig.RunConfig, error) {
 
 		revert.Add(revertFunc)
 
+		if isOverlay {
+			if isFile {
+				return nil, fmt.Errorf("The overlay option is only supported for directories")
+			}
+
+			revertFunc, sourceDevPath, err = d.createOverlay(sourceDevPath)
+			if err != nil {
+				return nil, err
+			}
+
+			revert.Add(revertFunc)
+		}
+
 		if isFile {
 			options = append(options, "create=file")
 		} else {
@@ -1036,6 +1144,11 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 		opts = append(opts, fmt.Sprintf("cache=%s", d.config["io.cache"]))
 	}
 
+	// Allow the user to override the asynchronous I/O mode.
+	if d.config["io.mode"] != "" {
+		opts = append(opts, fmt.Sprintf("aio=%s", d.config["io.mode"]))
+	}
+
 	// Add I/O limits if set.
 	var diskLimits *deviceConfig.DiskLimits
 	if d.config["limits.read"] != "" || d.config["limits.write"] != "" || d.config["limits.max"] != "" {
@@ -1133,12 +1246,15 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 	} else if d.config["source"] != "" {
 		if d.sourceIsCeph() {
 			// Get the pool and volume names.
-			fields := strings.SplitN(d.config["source"], ":", 2)
-			fields = strings.SplitN(fields[1], "/", 2)
+			poolName, volumeName, err := diskParseCephSource(d.config["source"])
+			if err != nil {
+				return nil, err
+			}
+
 			clusterName, userName := d.cephCreds()
 			runConf.Mounts = []deviceConfig.MountEntryItem{
 				{
-					DevPath: DiskGetRBDFormat(clusterName, userName, fields[0], fields[1]),
+					DevPath: DiskGetRBDFormat(clusterName, userName, poolName, volumeName),
 					DevName: d.name,
 					Opts:    opts,
 					Limits:  diskLimits,
@@ -1249,6 +1365,10 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 					return nil, err
 				}
 
+				if d.config["io.mode"] != "" {
+					return nil, fmt.Errorf("IO mode configuration cannot be applied to file systems")
+				}
+
 				if d.config["path"] == "" {
 					return nil, fmt.Errorf(`Missing mount "path" setting`)
 				}
@@ -1428,6 +1548,13 @@ func (d *disk) postStart() error {
 		return err
 	}
 
+	if util.IsTrue(d.config["overlay"]) {
+		err = unix.Unmount(d.getOverlayMergedPath(), unix.MNT_DETACH)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -1507,6 +1634,36 @@ func (d *disk) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 				}
 			}
 		}
+	} else if d.sourceHasQuota() {
+		// Apply size quota changes to bind-mounted host paths.
+		err := d.applySourceQuota()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Apply read-only changes by remounting the device inside the running container.
+	isReadOnly := util.IsTrue(d.config["readonly"])
+	if isRunning && diskCanRemount(d.inst.Type(), d.config) && util.IsTrue(oldDevices[d.name]["readonly"]) != isReadOnly {
+		options := []string{}
+		if isReadOnly {
+			options = append(options, "ro")
+		}
+
+		runConf := deviceConfig.RunConfig{}
+		runConf.Mounts = []deviceConfig.MountEntryItem{
+			{
+				DevName:    d.name,
+				TargetPath: strings.TrimPrefix(d.config["path"], "/"),
+				Opts:       options,
+				Remount:    true,
+			},
+		}
+
+		err := d.inst.DeviceEventHandler(&runConf)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Only apply IO limits if instance is running.
@@ -1514,7 +1671,10 @@ func (d *disk) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 		runConf := deviceConfig.RunConfig{}
 
 		if d.inst.Type() == instancetype.Container {
-			err := d.generateLimits(&runConf)
+			// Reset the I/O weight when the disk priority was unset.
+			resetPriority := oldDevices[d.name]["limits.priority"] != "" && d.config["limits.priority"] == ""
+
+			err := d.generateLimits(&runConf, resetPriority)
 			if err != nil {
 				return err
 			}
@@ -1552,6 +1712,184 @@ func (d *disk) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 	return nil
 }
 
+// sourceHasQuota returns true if the device is a bind-mounted host path which has, or had, a size quota.
+func (d *disk) sourceHasQuota() bool {
+	if d.config["path"] == "/" || d.config["pool"] != "" || !d.sourceIsLocalPath(d.config["source"]) {
+		return false
+	}
+
+	return d.config["size"] != "" || d.volatileGet()["source_quota"] != ""
+}
+
+// diskSourceQuotaProjectIDMin is the lowest project quota ID allocated to bind-mounted host paths.
+// It's kept above the range used by the storage drivers.
+const diskSourceQuotaProjectIDMin = 1 << 31
+
+// diskSourceQuotaAllocateProjectID picks a random project quota ID from diskSourceQuotaProjectIDMin upwards for
+// which inUse returns false.
+func diskSourceQuotaAllocateProjectID(inUse func(id uint32) (bool, error)) (uint32, error) {
+	for range 100 {
+		id := diskSourceQuotaProjectIDMin | rand.Uint32()
+
+		used, err := inUse(id)
+		if err != nil {
+			return 0, err
+		}
+
+		if !used {
+			return id, nil
+		}
+	}
+
+	return 0, fmt.Errorf("Failed to find an unused project quota ID")
+}
+
+// diskSourceQuotaCheckFilesystem checks that the size quota of a bind-mounted host path can be applied on the
+// filesystem type. Btrfs and ZFS don't implement project quotas through quotactl, their own quota mechanisms are
+// used by custom storage volumes instead.
+func diskSourceQuotaCheckFilesystem(fsType string) error {
+	if slices.Contains([]string{"btrfs", "zfs"}, fsType) {
+		return fmt.Errorf("Size quotas on bind-mounted host paths aren't supported on %s, use a custom storage volume instead", fsType)
+	}
+
+	return nil
+}
+
+// sourceQuotaProjectID returns the project quota ID recorded in the volatile "source_quota_id" key, if any.
+func (d *disk) sourceQuotaProjectID() (uint32, error) {
+	value := d.volatileGet()["source_quota_id"]
+	if value == "" {
+		return 0, nil
+	}
+
+	projectID, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid source quota project ID %q: %w", value, err)
+	}
+
+	return uint32(projectID), nil
+}
+
+// removeSourceQuota removes the size quota of a bind-mounted host path along with its volatile keys.
+func (d *disk) removeSourceQuota() error {
+	projectID, err := d.sourceQuotaProjectID()
+	if err != nil {
+		return err
+	}
+
+	if projectID != 0 {
+		// Only the source directory itself is reset as its content is controlled by the instance.
+		// The content keeps the project ID, without any quota.
+		err = quota.SetDirProject(d.config["source"], 0)
+		if err != nil {
+			return fmt.Errorf("Failed removing size quota from %q: %w", d.config["source"], err)
+		}
+
+		err = quota.SetProjectQuota(d.config["source"], projectID, 0)
+		if err != nil {
+			return fmt.Errorf("Failed removing size quota from %q: %w", d.config["source"], err)
+		}
+	}
+
+	return d.volatileSet(map[string]string{"source_quota": "", "source_quota_id": ""})
+}
+
+// applySourceQuota applies the size quota of a bind-mounted host path using a filesystem project quota.
+// Each device gets its own project ID, recorded in the volatile "source_quota_id" key, while the applied quota is
+// recorded in the volatile "source_quota" key so that changes can be detected.
+func (d *disk) applySourceQuota() error {
+	srcPath := d.config["source"]
+	v := d.volatileGet()
+
+	// Remove the quota if no longer requested.
+	if d.config["size"] == "" {
+		if v["source_quota"] == "" {
+			return nil
+		}
+
+		return d.removeSourceQuota()
+	}
+
+	sizeBytes, err := units.ParseByteSizeString(d.config["size"])
+	if err != nil {
+		return err
+	}
+
+	if v["source_quota"] == fmt.Sprintf("%d", sizeBytes) {
+		return nil
+	}
+
+	fsType, err := linux.DetectFilesystem(srcPath)
+	if err != nil {
+		return fmt.Errorf("Failed detecting filesystem of %q: %w", srcPath, err)
+	}
+
+	err = diskSourceQuotaCheckFilesystem(fsType)
+	if err != nil {
+		return err
+	}
+
+	ok, err := quota.Supported(srcPath)
+	if err != nil || !ok {
+		return fmt.Errorf("Cannot apply size quota as the filesystem backing %q doesn't support project quotas", srcPath)
+	}
+
+	projectID, err := d.sourceQuotaProjectID()
+	if err != nil {
+		return err
+	}
+
+	if projectID == 0 {
+		projectID, err = diskSourceQuotaAllocateProjectID(func(id uint32) (bool, error) {
+			usage, err := quota.GetProjectUsage(srcPath, id)
+			if errors.Is(err, unix.ENOENT) {
+				return false, nil
+			}
+
+			return usage > 0, err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	currentProjectID, err := quota.GetProject(srcPath)
+	if err != nil {
+		return err
+	}
+
+	if currentProjectID != projectID {
+		// A directory can only be part of a single project, don't take it over from another device.
+		if currentProjectID != 0 {
+			return fmt.Errorf("Cannot apply size quota as %q already has a project quota (ID %d)", srcPath, currentProjectID)
+		}
+
+		// The source is a host path, so don't recursively change the project of existing files which
+		// the server doesn't own. Only an empty source is accepted, with its content then inheriting the
+		// project ID from the directory.
+		empty, err := internalUtil.PathIsEmpty(srcPath)
+		if err != nil {
+			return fmt.Errorf("Failed checking content of %q: %w", srcPath, err)
+		}
+
+		if !empty {
+			return fmt.Errorf("Cannot apply size quota as %q isn't empty", srcPath)
+		}
+
+		err = quota.SetDirProject(srcPath, projectID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = quota.SetProjectQuota(srcPath, projectID, sizeBytes)
+	if err != nil {
+		return err
+	}
+
+	return d.volatileSet(map[string]string{"source_quota": fmt.Sprintf("%d", sizeBytes), "source_quota_id": fmt.Sprintf("%d", projectID)})
+}
+
 // applyDeferredQuota attempts to apply the deferred quota specified in the volatile "apply_quota" key if set.
 // If successfully applies new quota then removes the volatile "apply_quota" key.
 func (d *disk) applyDeferredQuota() error {
@@ -1619,9 +1957,11 @@ func (d *disk) applyQuota(remount bool) error {
 }
 
 // generateLimits adds a set of cgroup rules to apply specified limits to the supplied RunConfig.
-func (d *disk) generateLimits(runConf *deviceConfig.RunConfig) error {
-	// Disk throttle limits.
-	hasDiskLimits := false
+// When resetPriority is set, the I/O weight of the block devices not prioritized by any disk is reset to the default.
+func (d *disk) generateLimits(runConf *deviceConfig.RunConfig, resetPriority bool) error {
+	// Disk throttle limits and priorities.
+	hasDiskLimits := resetPriority
+	hasDiskPriority := false
 	for _, dev := range d.inst.ExpandedDevices() {
 		if dev["type"] != "disk" {
 			continue
@@ -1630,6 +1970,11 @@ func (d *disk) generateLimits(runConf *deviceConfig.RunConfig) error {
 		if dev["limits.read"] != "" || dev["limits.write"] != "" || dev["limits.max"] != "" {
 			hasDiskLimits = true
 		}
+
+		if dev["limits.priority"] != "" {
+			hasDiskLimits = true
+			hasDiskPriority = true
+		}
 	}
 
 	if hasDiskLimits {
@@ -1637,6 +1982,12 @@ func (d *disk) generateLimits(runConf *deviceConfig.RunConfig) error {
 			return fmt.Errorf("Cannot apply disk limits as blkio cgroup controller is missing")
 		}
 
+		if hasDiskPriority && !d.state.OS.CGInfo.Supports(cgroup.BlkioWeight, nil) {
+			return fmt.Errorf("Cannot apply disk priority as blkio.weight cgroup controller is missing")
+		}
+
+		resetPriority = resetPriority && d.state.OS.CGInfo.Supports(cgroup.BlkioWeight, nil)
+
 		diskLimits, err := d.getDiskLimits()
 		if err != nil {
 			return err
@@ -1675,6 +2026,13 @@ func (d *disk) generateLimits(runConf *deviceConfig.RunConfig) error {
 					return err
 				}
 			}
+
+			if limit.weight > 0 || resetPriority {
+				err = cg.SetBlkioDeviceWeight(block, limit.weight)
+				if err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -1784,10 +2142,11 @@ func (d *disk) createDevice(srcPath string) (func(), string, bool, error) {
 	if d.config["pool"] == "" {
 		if d.sourceIsCephFs() {
 			// Get fs name and path from d.config.
-			fields := strings.SplitN(d.config["source"], ":", 2)
-			fields = strings.SplitN(fields[1], "/", 2)
-			mdsName := fields[0]
-			mdsPath := fields[1]
+			mdsName, mdsPath, err := diskParseCephSource(d.config["source"])
+			if err != nil {
+				return nil, "", false, err
+			}
+
 			clusterName, userName := d.cephCreds()
 
 			// Get the mount options.
@@ -1804,10 +2163,11 @@ func (d *disk) createDevice(srcPath string) (func(), string, bool, error) {
 			isFile = false
 		} else if d.sourceIsCeph() {
 			// Get the pool and volume names.
-			fields := strings.SplitN(d.config["source"], ":", 2)
-			fields = strings.SplitN(fields[1], "/", 2)
-			poolName := fields[0]
-			volumeName := fields[1]
+			poolName, volumeName, err := diskParseCephSource(d.config["source"])
+			if err != nil {
+				return nil, "", false, err
+			}
+
 			clusterName, userName := d.cephCreds()
 
 			// Map the RBD.
@@ -1852,6 +2212,17 @@ func (d *disk) createDevice(srcPath string) (func(), string, bool, error) {
 
 			defer func() { _ = f.Close() }()
 
+			// Image files are bind-mounted as-is, so a qcow2 image will show up as a plain file.
+			// That may well be what the user wants, so only warn about it.
+			if fileMode.IsRegular() {
+				isQcow2, err := diskIsQcow2(fmt.Sprintf("/proc/self/fd/%d", f.Fd()))
+				if err != nil {
+					d.logger.Warn("Failed checking source path image format", logger.Ctx{"path": srcPath, "err": err})
+				} else if isQcow2 {
+					d.logger.Warn("Source path is a qcow2 image which will be bind-mounted as a plain file, convert it to a raw image or a block device to access its content", logger.Ctx{"path": srcPath})
+				}
+			}
+
 			srcPath = fmt.Sprintf("/proc/self/fd/%d", f.Fd())
 		}
 	} else if d.config["source"] != "" {
@@ -1937,6 +2308,82 @@ func (d *disk) createDevice(srcPath string) (func(), string, bool, error) {
 	return cleanup, devPath, isFile, err
 }
 
+// getOverlayPath returns the host directory holding the overlay layers for this device.
+func (d *disk) getOverlayPath() string {
+	return filepath.Join(d.inst.DevicesPath(), linux.PathNameEncode(deviceJoinPath("disk-overlay", d.name)))
+}
+
+// getOverlayMergedPath returns the host path where the overlay for this device is mounted.
+func (d *disk) getOverlayMergedPath() string {
+	return filepath.Join(d.getOverlayPath(), "merged")
+}
+
+// createOverlay mounts a writable overlay using the read-only lowerPath as its lower layer.
+// The upper layer is kept in the instance devices directory until the device is removed.
+// Returns the path of the merged overlay mount.
+func (d *disk) createOverlay(lowerPath string) (func(), string, error) {
+	if !localUtil.SupportsFilesystem("overlay") {
+		return nil, "", fmt.Errorf("Overlay file systems aren't supported by the kernel")
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	overlayPath := d.getOverlayPath()
+	upperPath := filepath.Join(overlayPath, "upper")
+	workPath := filepath.Join(overlayPath, "work")
+	mergedPath := d.getOverlayMergedPath()
+
+	// Get the container's idmap so the writable layer is owned by the container's root user.
+	c, ok := d.inst.(instance.Container)
+	if !ok {
+		return nil, "", fmt.Errorf("The overlay option is only supported for containers")
+	}
+
+	var idmapSet *idmap.Set
+	var err error
+	if c.IsRunning() {
+		idmapSet, err = c.CurrentIdmap()
+	} else {
+		idmapSet, err = c.NextIdmap()
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	rootUID, rootGID, err := diskOverlayOwner(idmapSet)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, path := range []string{upperPath, workPath, mergedPath} {
+		err := os.MkdirAll(path, 0700)
+		if err != nil {
+			return nil, "", fmt.Errorf("Failed creating overlay directory %q: %w", path, err)
+		}
+	}
+
+	for _, path := range []string{upperPath, workPath} {
+		err := os.Chown(path, int(rootUID), int(rootGID))
+		if err != nil {
+			return nil, "", fmt.Errorf("Failed setting ownership of overlay directory %q: %w", path, err)
+		}
+	}
+
+	mntOptions := fmt.Sprintf("lowerdir=%s,upperdir=%s,workdir=%s", lowerPath, upperPath, workPath)
+	err = unix.Mount("overlay", mergedPath, "overlay", 0, mntOptions)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed mounting overlay on %q: %w", mergedPath, err)
+	}
+
+	revert.Add(func() { _ = DiskMountClear(mergedPath) })
+
+	cleanup := revert.Clone().Fail
+	revert.Success()
+	return cleanup, mergedPath, nil
+}
+
 // localSourceOpen opens a local disk source path and returns a file handle to it.
 // If d.restrictedParentSourcePath has been set during validation, then the openat2 syscall is used to ensure that
 // the srcPath opened doesn't resolve above the allowed parent source path.
@@ -2199,6 +2646,14 @@ func (d *disk) stopVM() (*deviceConfig.RunConfig, error) {
 
 // postStop is run after the device is removed from the instance.
 func (d *disk) postStop() error {
+	// Clean any overlay mounted on top of the device mount entry.
+	if util.IsTrue(d.config["overlay"]) {
+		err := DiskMountClear(d.getOverlayMergedPath())
+		if err != nil {
+			return err
+		}
+	}
+
 	// Clean any existing device mount entry. Should occur first before custom volume unmounts.
 	err := DiskMountClear(d.getDevicePath(d.name, d.config))
 	if err != nil {
@@ -2277,6 +2732,11 @@ func (d *disk) getDiskLimits() (map[string]diskBlockLimit, error) {
 			return nil, err
 		}
 
+		weight, err := cgroup.ParseDiskPriority(dev["limits.priority"])
+		if err != nil {
+			return nil, err
+		}
+
 		// Set the source path
 		source := d.getDevicePath(devName, dev)
 		if dev["source"] == "" {
@@ -2295,7 +2755,7 @@ func (d *disk) getDiskLimits() (map[string]diskBlockLimit, error) {
 		// Get the backing block devices (major:minor)
 		blocks, err := d.getParentBlocks(source)
 		if err != nil {
-			if readBps == 0 && readIops == 0 && writeBps == 0 && writeIops == 0 {
+			if readBps == 0 && readIops == 0 && writeBps == 0 && writeIops == 0 && weight == 0 {
 				// If the device doesn't exist, there is no limit to clear so ignore the failure
 				continue
 			} else {
@@ -2303,7 +2763,7 @@ func (d *disk) getDiskLimits() (map[string]diskBlockLimit, error) {
 			}
 		}
 
-		device := diskBlockLimit{readBps: readBps, readIops: readIops, writeBps: writeBps, writeIops: writeIops}
+		device := diskBlockLimit{readBps: readBps, readIops: readIops, writeBps: writeBps, writeIops: writeIops, weight: weight}
 		for _, block := range blocks {
 			blockStr := ""
 
@@ -2335,6 +2795,9 @@ func (d *disk) getDiskLimits() (map[string]diskBlockLimit, error) {
 	for block, limits := range blockLimits {
 		var readBpsCount, readBpsTotal, readIopsCount, readIopsTotal, writeBpsCount, writeBpsTotal, writeIopsCount, writeIopsTotal int64
 
+		// Use the highest priority of the disks sharing the block device.
+		var weight int64
+
 		for _, limit := range limits {
 			if limit.readBps > 0 {
 				readBpsCount++
@@ -2355,9 +2818,11 @@ func (d *disk) getDiskLimits() (map[string]diskBlockLimit, error) {
 				writeIopsCount++
 				writeIopsTotal += limit.writeIops
 			}
+
+			weight = max(weight, limit.weight)
 		}
 
-		device := diskBlockLimit{}
+		device := diskBlockLimit{weight: weight}
 
 		if readBpsCount > 0 {
 			device.readBps = readBpsTotal / readBpsCount
@@ -2667,13 +3132,10 @@ func (d *disk) generateVMConfigDrive() (string, error) {
 
 	instanceConfig := d.inst.ExpandedConfig()
 
-	// Use an empty vendor-data file if no custom vendor-data supplied.
-	vendorData, ok := instanceConfig["cloud-init.vendor-data"]
-	if !ok {
-		vendorData = instanceConfig["user.vendor-data"]
-		if vendorData == "" {
-			vendorData = "#cloud-config\n{}"
-		}
+	// Get the user-data and vendor-data (empty if no custom data supplied) along with any SSH keys.
+	userData, vendorData, err := internalInstance.CloudInitConfig(instanceConfig)
+	if err != nil {
+		return "", err
 	}
 
 	err = os.WriteFile(filepath.Join(scratchDir, "vendor-data"), []byte(vendorData), 0400)
@@ -2681,15 +3143,6 @@ func (d *disk) generateVMConfigDrive() (string, error) {
 		return "", err
 	}
 
-	// Use an empty user-data file if no custom user-data supplied.
-	userData, ok := instanceConfig["cloud-init.user-data"]
-	if !ok {
-		userData = instanceConfig["user.user-data"]
-		if userData == "" {
-			userData = "#cloud-config\n{}"
-		}
-	}
-
 	err = os.WriteFile(filepath.Join(scratchDir, "user-data"), []byte(userData), 0400)
 	if err != nil {
 		return "", err
@@ -2702,6 +3155,11 @@ func (d *disk) generateVMConfigDrive() (string, error) {
 	}
 
 	if networkConfig != "" {
+		err = internalInstance.ValidateNetworkConfig(networkConfig)
+		if err != nil {
+			d.logger.Warn("The cloud-init network configuration may be ignored by the instance", logger.Ctx{"err": err})
+		}
+
 		err = os.WriteFile(filepath.Join(scratchDir, "network-config"), []byte(networkConfig), 0400)
 		if err != nil {
 			return "", err
@@ -2750,6 +3208,22 @@ func (d *disk) cephCreds() (string, string) {
 
 // Remove cleans up the device when it is removed from an instance.
 func (d *disk) Remove() error {
+	// Remove the size quota from bind-mounted host paths.
+	if d.volatileGet()["source_quota_id"] != "" {
+		err := d.removeSourceQuota()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Discard the overlay upper layer.
+	if util.IsTrue(d.config["overlay"]) {
+		err := os.RemoveAll(d.getOverlayPath())
+		if err != nil {
+			return fmt.Errorf("Failed removing overlay directory: %w", err)
+		}
+	}
+
 	// Remove the config.iso file for cloud-init config drives.
 	if d.config["source"] == diskSourceCloudInit {
 		pool, err := storagePools.LoadByInstance(d.state, d.inst)
@@ -0,0 +1,221 @@
+package device
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+)
+
+func TestDiskValidateLimitsPriority(t *testing.T) {
+	tests := []struct {
+		name         string
+		instanceType instancetype.Type
+		priority     string
+		wantErr      bool
+	}{
+		{name: "Unset", instanceType: instancetype.Container, priority: ""},
+		{name: "Lowest", instanceType: instancetype.Container, priority: "0"},
+		{name: "Highest", instanceType: instancetype.Container, priority: "10"},
+		{name: "Too high", instanceType: instancetype.Container, priority: "11", wantErr: true},
+		{name: "Negative", instanceType: instancetype.Container, priority: "-1", wantErr: true},
+		{name: "Not a number", instanceType: instancetype.Container, priority: "high", wantErr: true},
+		{name: "Virtual machine", instanceType: instancetype.VM, priority: "5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &disk{}
+			d.config = deviceConfig.Device{
+				"type":   "disk",
+				"path":   "/mnt",
+				"source": t.TempDir(),
+			}
+
+			if tt.priority != "" {
+				d.config["limits.priority"] = tt.priority
+			}
+
+			err := d.validateConfig(&testInstance{instanceType: tt.instanceType})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDiskValidateBootPriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority string
+		wantErr  bool
+	}{
+		{name: "Unset", priority: ""},
+		{name: "Lowest", priority: "0"},
+		{name: "Highest", priority: "4294967295"},
+		{name: "Negative", priority: "-1", wantErr: true},
+		{name: "Not a number", priority: "first", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &disk{}
+			d.config = deviceConfig.Device{
+				"type":          "disk",
+				"path":          "/mnt",
+				"source":        t.TempDir(),
+				"boot.priority": tt.priority,
+			}
+
+			err := d.validateConfig(&testInstance{instanceType: instancetype.VM})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDiskValidateIOMode(t *testing.T) {
+	tests := []struct {
+		name         string
+		instanceType instancetype.Type
+		mode         string
+		wantErr      bool
+	}{
+		{name: "Unset", instanceType: instancetype.VM, mode: ""},
+		{name: "io_uring", instanceType: instancetype.VM, mode: "io_uring"},
+		{name: "native", instanceType: instancetype.VM, mode: "native"},
+		{name: "threads", instanceType: instancetype.VM, mode: "threads"},
+		{name: "Unknown mode", instanceType: instancetype.VM, mode: "posix", wantErr: true},
+		{name: "Container", instanceType: instancetype.Container, mode: "threads", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &disk{}
+			d.config = deviceConfig.Device{
+				"type":   "disk",
+				"path":   "/mnt",
+				"source": t.TempDir(),
+			}
+
+			if tt.mode != "" {
+				d.config["io.mode"] = tt.mode
+			}
+
+			err := d.validateConfig(&testInstance{instanceType: tt.instanceType})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDiskValidateShift(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  deviceConfig.Device
+		wantErr bool
+	}{
+		{name: "Unset", config: deviceConfig.Device{}},
+		{name: "Enabled", config: deviceConfig.Device{"shift": "true"}},
+		{name: "Disabled", config: deviceConfig.Device{"shift": "false"}},
+		{name: "Not a boolean", config: deviceConfig.Device{"shift": "idmap"}, wantErr: true},
+		{name: "With overlay", config: deviceConfig.Device{"shift": "true", "overlay": "true", "readonly": "true"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &disk{}
+			d.config = deviceConfig.Device{
+				"type":   "disk",
+				"path":   "/mnt",
+				"source": t.TempDir(),
+			}
+
+			for k, v := range tt.config {
+				d.config[k] = v
+			}
+
+			err := d.validateConfig(&testInstance{instanceType: instancetype.Container})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDiskSourceQuotaAllocateProjectID(t *testing.T) {
+	// IDs are allocated above the storage driver range and differ between calls.
+	seen := map[uint32]bool{}
+	for range 10 {
+		id, err := diskSourceQuotaAllocateProjectID(func(id uint32) (bool, error) { return false, nil })
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, id, uint32(diskSourceQuotaProjectIDMin))
+
+		seen[id] = true
+	}
+
+	assert.Greater(t, len(seen), 1)
+
+	// IDs already in use are skipped.
+	var used []uint32
+	id, err := diskSourceQuotaAllocateProjectID(func(id uint32) (bool, error) {
+		if len(used) < 3 {
+			used = append(used, id)
+			return true, nil
+		}
+
+		return false, nil
+	})
+	assert.NoError(t, err)
+	assert.NotContains(t, used, id)
+
+	// Allocation gives up when every ID is in use.
+	_, err = diskSourceQuotaAllocateProjectID(func(id uint32) (bool, error) { return true, nil })
+	assert.Error(t, err)
+
+	// Errors are returned.
+	_, err = diskSourceQuotaAllocateProjectID(func(id uint32) (bool, error) { return false, errors.New("failed") })
+	assert.Error(t, err)
+}
+
+func TestDiskCanRemount(t *testing.T) {
+	tests := []struct {
+		name         string
+		instanceType instancetype.Type
+		config       deviceConfig.Device
+		want         bool
+	}{
+		{name: "Container bind-mount", instanceType: instancetype.Container, config: deviceConfig.Device{"path": "/mnt", "source": "/srv"}, want: true},
+		{name: "Container volume", instanceType: instancetype.Container, config: deviceConfig.Device{"path": "/mnt", "pool": "default", "source": "vol", "readonly": "true"}, want: true},
+		{name: "Container root disk", instanceType: instancetype.Container, config: deviceConfig.Device{"path": "/", "pool": "default"}},
+		{name: "Recursive", instanceType: instancetype.Container, config: deviceConfig.Device{"path": "/mnt", "source": "/srv", "recursive": "true"}},
+		{name: "Overlay", instanceType: instancetype.Container, config: deviceConfig.Device{"path": "/mnt", "source": "/srv", "readonly": "true", "overlay": "true"}},
+		{name: "Virtual machine", instanceType: instancetype.VM, config: deviceConfig.Device{"path": "/mnt", "source": "/srv"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, diskCanRemount(tt.instanceType, tt.config))
+		})
+	}
+}
+
+func TestDiskSourceQuotaCheckFilesystem(t *testing.T) {
+	assert.NoError(t, diskSourceQuotaCheckFilesystem("ext4"))
+	assert.NoError(t, diskSourceQuotaCheckFilesystem("xfs"))
+	assert.Error(t, diskSourceQuotaCheckFilesystem("btrfs"))
+	assert.Error(t, diskSourceQuotaCheckFilesystem("zfs"))
+}
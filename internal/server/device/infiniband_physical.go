@@ -188,7 +188,7 @@ func (d *infinibandPhysical) Stop() (*deviceConfig.RunConfig, error) {
 	}
 
 	if d.inst.Type() == instancetype.Container {
-		err := unixDeviceRemove(d.inst.DevicesPath(), IBDevPrefix, d.name, "", &runConf)
+		err := infinibandRemoveDevices(d.inst.DevicesPath(), d.name, &runConf)
 		if err != nil {
 			return nil, err
 		}
@@ -230,7 +230,7 @@ func (d *infinibandPhysical) postStop() error {
 		}
 	} else if d.inst.Type() == instancetype.Container {
 		// Remove infiniband host files for this device.
-		err := unixDeviceDeleteFiles(d.state, d.inst.DevicesPath(), IBDevPrefix, d.name, "")
+		err := infinibandDeleteFiles(d.state, d.inst.DevicesPath(), d.name)
 		if err != nil {
 			return fmt.Errorf("Failed to delete files for device '%s': %w", d.name, err)
 		}
@@ -259,7 +259,7 @@ func (d *infinibandSRIOV) Stop() (*deviceConfig.RunConfig, error) {
 	}
 
 	if d.inst.Type() == instancetype.Container {
-		err := unixDeviceRemove(d.inst.DevicesPath(), IBDevPrefix, d.name, "", &runConf)
+		err := infinibandRemoveDevices(d.inst.DevicesPath(), d.name, &runConf)
 		if err != nil {
 			return nil, err
 		}
@@ -283,7 +283,7 @@ func (d *infinibandSRIOV) postStop() error {
 
 	if d.inst.Type() == instancetype.Container {
 		// Remove infiniband host files for this device.
-		err := unixDeviceDeleteFiles(d.state, d.inst.DevicesPath(), IBDevPrefix, d.name, "")
+		err := infinibandDeleteFiles(d.state, d.inst.DevicesPath(), d.name)
 		if err != nil {
 			return fmt.Errorf("Failed to delete files for device '%s': %w", d.name, err)
 		}
@@ -22,19 +22,23 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		"vlan":                                 validate.IsNetworkVLAN,
 		"gvrp":                                 validate.Optional(validate.IsBool),
 		"hwaddr":                               validate.IsNetworkMAC,
-		"host_name":                            validate.IsAny,
-		"limits.ingress":                       validate.IsAny,
-		"limits.egress":                        validate.IsAny,
-		"limits.max":                           validate.IsAny,
+		"hwaddr.generate":                      validate.Optional(validate.IsBool),
+		"hwaddr.stable":                        validate.Optional(validate.IsBool),
+		"hwaddr.oui":                           validate.Optional(validate.IsNetworkOUI),
+		"host_name":                            validate.IsInterfaceName,
+		"limits.ingress":                       validate.IsBitSize,
+		"limits.egress":                        validate.IsBitSize,
+		"limits.max":                           validate.IsBitSize,
 		"limits.priority":                      validate.Optional(validate.IsUint32),
-		"security.mac_filtering":               validate.IsAny,
-		"security.ipv4_filtering":              validate.IsAny,
-		"security.ipv6_filtering":              validate.IsAny,
+		"security.mac_filtering":               validate.Optional(validate.IsBool),
+		"security.ipv4_filtering":              validate.Optional(validate.IsBool),
+		"security.ipv6_filtering":              validate.Optional(validate.IsBool),
 		"security.port_isolation":              validate.Optional(validate.IsBool),
 		"ipv4.address":                         validate.Optional(validate.IsNetworkAddressV4),
 		"ipv6.address":                         validate.Optional(validate.IsNetworkAddressV6),
 		"ipv4.routes":                          validate.Optional(validate.IsListOf(validate.IsNetworkV4)),
 		"ipv6.routes":                          validate.Optional(validate.IsListOf(validate.IsNetworkV6)),
+		"ipv6.accept_ra":                       validate.Optional(validate.IsBool),
 		"boot.priority":                        validate.Optional(validate.IsUint32),
 		"ipv4.gateway":                         networkValidGateway,
 		"ipv6.gateway":                         networkValidGateway,
@@ -74,6 +74,8 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 		"mtu",
 		"queue.tx.length",
 		"hwaddr",
+		"hwaddr.stable",
+		"hwaddr.oui",
 		"host_name",
 		"limits.ingress",
 		"limits.egress",
@@ -85,6 +87,7 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 		"ipv6.routes",
 		"ipv4.routes.external",
 		"ipv6.routes.external",
+		"ipv6.accept_ra",
 		"security.mac_filtering",
 		"security.ipv4_filtering",
 		"security.ipv6_filtering",
@@ -286,6 +289,10 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 		}
 	}
 
+	if d.config["ipv6.accept_ra"] != "" && instConf.Type() == instancetype.VM {
+		return fmt.Errorf("The ipv6.accept_ra option is only supported for containers")
+	}
+
 	rules := nicValidationRules(requiredFields, optionalFields, instConf)
 
 	// Add bridge specific vlan validation.
@@ -341,6 +348,11 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
+	err = networkValidateLimits(d.config)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -445,7 +457,7 @@ func (d *nicBridged) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "ipv4.routes", "ipv6.routes", "ipv4.routes.external", "ipv6.routes.external", "ipv4.address", "ipv6.address", "security.mac_filtering", "security.ipv4_filtering", "security.ipv6_filtering"}
+	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "ipv4.routes", "ipv6.routes", "ipv4.routes.external", "ipv6.routes.external", "ipv4.address", "ipv6.address", "ipv6.accept_ra", "security.mac_filtering", "security.ipv4_filtering", "security.ipv6_filtering"}
 }
 
 // Add is run when a device is added to a non-snapshot instance whether or not the instance is running.
@@ -676,6 +688,70 @@ func (d *nicBridged) postStart() error {
 		return err
 	}
 
+	if d.config["ipv6.accept_ra"] != "" {
+		err = d.setAcceptRA()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setAcceptRA applies ipv6.accept_ra to the interface inside the container.
+// When unset, the default of the container's network namespace is restored.
+func (d *nicBridged) setAcceptRA() error {
+	if d.inst.Type() != instancetype.Container {
+		return nil
+	}
+
+	err := networkInstanceNetnsDo(d.inst.InitPID(), func() error {
+		acceptRA := "1"
+		if d.config["ipv6.accept_ra"] == "" {
+			value, err := localUtil.SysctlGet("net/ipv6/conf/default/accept_ra")
+			if err != nil {
+				return err
+			}
+
+			acceptRA = strings.TrimSpace(value)
+		} else if util.IsFalse(d.config["ipv6.accept_ra"]) {
+			acceptRA = "0"
+		}
+
+		err := localUtil.SysctlSet(fmt.Sprintf("net/ipv6/conf/%s/accept_ra", d.config["name"]), acceptRA)
+		if err != nil {
+			return err
+		}
+
+		if acceptRA != "0" {
+			return nil
+		}
+
+		// The interface is already up by the time this runs, so remove any addresses and routes
+		// learned from router advertisements received before they were disabled.
+		addr := &ip.Addr{
+			DevName: d.config["name"],
+			Family:  ip.FamilyV6,
+			Flags:   "dynamic",
+		}
+
+		err = addr.Flush()
+		if err != nil {
+			return err
+		}
+
+		route := &ip.Route{
+			DevName: d.config["name"],
+			Family:  ip.FamilyV6,
+			Proto:   "ra",
+		}
+
+		return route.Flush()
+	})
+	if err != nil {
+		return fmt.Errorf("Failed setting ipv6.accept_ra: %w", err)
+	}
+
 	return nil
 }
 
@@ -741,6 +817,13 @@ func (d *nicBridged) Update(oldDevices deviceConfig.Devices, isRunning bool) err
 			return err
 		}
 
+		if d.config["ipv6.accept_ra"] != oldConfig["ipv6.accept_ra"] {
+			err = d.setAcceptRA()
+			if err != nil {
+				return err
+			}
+		}
+
 		// Apply and host-side network filters (uses enriched host_name from networkVethFillFromVolatile).
 		r, err := d.setupHostFilters(oldConfig)
 		if err != nil {
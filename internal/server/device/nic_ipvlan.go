@@ -250,6 +250,16 @@ func (d *nicIPVLAN) Start() (*deviceConfig.RunConfig, error) {
 	// Record whether we created this device or not so it can be removed on stop.
 	saveData["last_state.created"] = fmt.Sprintf("%t", statusDev != "existing")
 
+	// Get the effective MTU, inherited from the parent if unset.
+	_, mtu, err := nicEffectiveParentAndMTU("ipvlan", d.config, network.GetDevMTU)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the effective MTU and VLAN.
+	saveData["mtu"] = fmt.Sprintf("%d", mtu)
+	saveData["vlan"] = d.config["vlan"]
+
 	mode := d.mode()
 
 	// If we created a VLAN interface, we need to setup the sysctls on that interface for l3s mode l2proxy.
@@ -273,10 +283,7 @@ func (d *nicIPVLAN) Start() (*deviceConfig.RunConfig, error) {
 		{Key: "ipvlan.mode", Value: mode},
 		{Key: "ipvlan.isolation", Value: "bridge"},
 		{Key: "link", Value: parentName},
-	}
-
-	if d.config["mtu"] != "" {
-		nic = append(nic, deviceConfig.RunConfigItem{Key: "mtu", Value: d.config["mtu"]})
+		{Key: "mtu", Value: saveData["mtu"]},
 	}
 
 	// Perform network configuration.
@@ -433,6 +440,8 @@ func (d *nicIPVLAN) postStop() error {
 		_ = d.volatileSet(map[string]string{
 			"last_state.created": "",
 			"host_name":          "",
+			"mtu":                "",
+			"vlan":               "",
 		})
 	}()
 
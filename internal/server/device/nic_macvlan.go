@@ -6,7 +6,6 @@ import (
 	"io/fs"
 	"net"
 	"net/http"
-	"strconv"
 
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/internal/server/instance"
@@ -48,6 +47,9 @@ func (d *nicMACVLAN) validateConfig(instConf instance.ConfigReader) error {
 		"parent",
 		"mtu",
 		"hwaddr",
+		"hwaddr.generate",
+		"hwaddr.stable",
+		"hwaddr.oui",
 		"vlan",
 		"boot.priority",
 		"gvrp",
@@ -104,6 +106,11 @@ func (d *nicMACVLAN) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
+	// Virtual machines need a known MAC address for their virtual NIC.
+	if instConf.Type() == instancetype.VM && util.IsFalse(d.config["hwaddr.generate"]) {
+		return fmt.Errorf("Disabling MAC address generation is only supported for containers")
+	}
+
 	return nil
 }
 
@@ -175,6 +182,16 @@ func (d *nicMACVLAN) Start() (*deviceConfig.RunConfig, error) {
 		})
 	}
 
+	// Get the effective MTU, inherited from the parent if unset.
+	_, mtu, err := nicEffectiveParentAndMTU("macvlan", d.config, network.GetDevMTU)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the effective MTU and VLAN.
+	saveData["mtu"] = fmt.Sprintf("%d", mtu)
+	saveData["vlan"] = d.config["vlan"]
+
 	// Create MACVLAN interface.
 	link := &ip.Macvlan{
 		Link: ip.Link{
@@ -208,14 +225,7 @@ func (d *nicMACVLAN) Start() (*deviceConfig.RunConfig, error) {
 	}
 
 	// Set the MTU.
-	if d.config["mtu"] != "" {
-		mtu, err := strconv.ParseUint(d.config["mtu"], 10, 32)
-		if err != nil {
-			return nil, fmt.Errorf("Invalid MTU specified %q: %w", d.config["mtu"], err)
-		}
-
-		link.MTU = uint32(mtu)
-	}
+	link.MTU = mtu
 
 	if d.inst.Type() == instancetype.VM {
 		// Enable all multicast processing which is required for IPv6 NDP functionality.
@@ -269,7 +279,7 @@ func (d *nicMACVLAN) Start() (*deviceConfig.RunConfig, error) {
 		runConf.NetworkInterface = append(runConf.NetworkInterface,
 			[]deviceConfig.RunConfigItem{
 				{Key: "devName", Value: d.name},
-				{Key: "mtu", Value: d.config["mtu"]},
+				{Key: "mtu", Value: saveData["mtu"]},
 			}...)
 	}
 
@@ -298,6 +308,8 @@ func (d *nicMACVLAN) postStop() error {
 			"last_state.hwaddr":  "",
 			"last_state.mtu":     "",
 			"last_state.created": "",
+			"mtu":                "",
+			"vlan":               "",
 		})
 	}()
 
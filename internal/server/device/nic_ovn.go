@@ -92,6 +92,8 @@ func (d *nicOVN) validateConfig(instConf instance.ConfigReader) error {
 	optionalFields := []string{
 		"name",
 		"hwaddr",
+		"hwaddr.stable",
+		"hwaddr.oui",
 		"host_name",
 		"mtu",
 		"ipv4.address",
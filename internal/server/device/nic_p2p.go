@@ -34,6 +34,8 @@ func (d *nicP2P) validateConfig(instConf instance.ConfigReader) error {
 		"mtu",
 		"queue.tx.length",
 		"hwaddr",
+		"hwaddr.stable",
+		"hwaddr.oui",
 		"host_name",
 		"limits.ingress",
 		"limits.egress",
@@ -49,6 +51,11 @@ func (d *nicP2P) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
+	err = networkValidateLimits(d.config)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -1,8 +1,11 @@
 package device
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"net"
+	"os"
 	"strconv"
 	"strings"
 
@@ -17,6 +20,7 @@ import (
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/revert"
 	"github.com/lxc/incus/v6/shared/util"
+	"github.com/lxc/incus/v6/shared/validate"
 )
 
 type nicPhysical struct {
@@ -25,6 +29,11 @@ type nicPhysical struct {
 	network network.Network // Populated in validateConfig().
 }
 
+// parents returns the list of host devices specified in the parent property.
+func (d *nicPhysical) parents() []string {
+	return util.SplitNTrimSpace(d.config["parent"], ",", -1, true)
+}
+
 // CanHotPlug returns whether the device can be managed whilst the instance is running. Returns true.
 func (d *nicPhysical) CanHotPlug() bool {
 	return true
@@ -92,11 +101,27 @@ func (d *nicPhysical) validateConfig(instConf instance.ConfigReader) error {
 		requiredFields = append(requiredFields, "parent")
 	}
 
-	err := d.config.Validate(nicValidationRules(requiredFields, optionalFields, instConf))
+	rules := nicValidationRules(requiredFields, optionalFields, instConf)
+
+	// The parent property holds one or more host interfaces, it's always set either directly or from the network.
+	rules["parent"] = validate.Required(validate.IsNotEmpty, validate.IsListOf(validate.IsInterfaceName))
+
+	err := d.config.Validate(rules)
 	if err != nil {
 		return err
 	}
 
+	// Multiple parents are moved into the container and combined into an active-backup bond there.
+	if len(d.parents()) > 1 {
+		if instConf.Type() == instancetype.VM {
+			return fmt.Errorf("Multiple parent devices are only supported for containers")
+		}
+
+		if d.config["vlan"] != "" {
+			return fmt.Errorf("Cannot use %q property with multiple parent devices", "vlan")
+		}
+	}
+
 	return nil
 }
 
@@ -110,6 +135,16 @@ func (d *nicPhysical) validateEnvironment() error {
 		return fmt.Errorf("Requires name property to start")
 	}
 
+	parents := d.parents()
+	if len(parents) > 1 {
+		// A bond can be setup as long as at least one of its parents is present.
+		if len(d.availableParents()) == 0 {
+			return fmt.Errorf("None of the parent devices %q exist", d.config["parent"])
+		}
+
+		return nil
+	}
+
 	if !util.PathExists(fmt.Sprintf("/sys/class/net/%s", d.config["parent"])) {
 		return fmt.Errorf("Parent device '%s' doesn't exist", d.config["parent"])
 	}
@@ -117,6 +152,172 @@ func (d *nicPhysical) validateEnvironment() error {
 	return nil
 }
 
+// availableParents returns the parent devices which currently exist on the host.
+func (d *nicPhysical) availableParents() []string {
+	available := []string{}
+	for _, parent := range d.parents() {
+		if util.PathExists(fmt.Sprintf("/sys/class/net/%s", parent)) {
+			available = append(available, parent)
+		}
+	}
+
+	return available
+}
+
+// startBond moves the slave devices into the network namespace of the running container and bonds them there.
+// Bonds can't be moved between network namespaces, so the bond has to be created inside the container.
+func (d *nicPhysical) startBond(slaves []string) error {
+	pid := d.inst.InitPID()
+	if pid <= 0 {
+		return fmt.Errorf("Instance isn't running")
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	for _, slave := range slaves {
+		// Interfaces must be down before being moved and enslaved.
+		link := &ip.Link{Name: slave}
+		err := link.SetDown()
+		if err != nil {
+			return fmt.Errorf("Failed to bring down %q: %w", slave, err)
+		}
+
+		err = link.SetNetns(strconv.Itoa(pid))
+		if err != nil {
+			return fmt.Errorf("Failed moving %q into the instance: %w", slave, err)
+		}
+
+		revert.Add(func() {
+			_ = networkInstanceNetnsDo(pid, func() error { return link.SetNetns(strconv.Itoa(os.Getpid())) })
+		})
+	}
+
+	err := networkInstanceNetnsDo(pid, func() error { return d.createBond(slaves) })
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// createBond creates an active-backup bond using the slave devices, named after the device's name property.
+// This must be run from within the network namespace of the container.
+func (d *nicPhysical) createBond(slaves []string) error {
+	bond := &ip.Bond{
+		Link: ip.Link{Name: d.config["name"]},
+		Mode: "active-backup",
+	}
+
+	err := bond.Add()
+	if err != nil {
+		return fmt.Errorf("Failed creating bond %q: %w", bond.Name, err)
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	revert.Add(func() { _ = bond.Delete() })
+
+	// Set the MAC address.
+	if d.config["hwaddr"] != "" {
+		hwaddr, err := net.ParseMAC(d.config["hwaddr"])
+		if err != nil {
+			return fmt.Errorf("Failed parsing MAC address %q: %w", d.config["hwaddr"], err)
+		}
+
+		err = bond.SetAddress(hwaddr)
+		if err != nil {
+			return fmt.Errorf("Failed to set the MAC address: %w", err)
+		}
+	}
+
+	for _, slave := range slaves {
+		link := &ip.Link{Name: slave}
+		err = link.SetMaster(bond.Name)
+		if err != nil {
+			return fmt.Errorf("Failed adding %q to bond %q: %w", slave, bond.Name, err)
+		}
+	}
+
+	// Set the MTU, this also applies to the slaves.
+	if d.config["mtu"] != "" {
+		mtu, err := strconv.ParseUint(d.config["mtu"], 10, 32)
+		if err != nil {
+			return fmt.Errorf("Invalid MTU specified %q: %w", d.config["mtu"], err)
+		}
+
+		err = bond.SetMTU(uint32(mtu))
+		if err != nil {
+			return fmt.Errorf("Failed setting MTU %q on %q: %w", d.config["mtu"], bond.Name, err)
+		}
+	}
+
+	err = bond.SetUp()
+	if err != nil {
+		return fmt.Errorf("Failed to bring up %q: %w", bond.Name, err)
+	}
+
+	revert.Success()
+	return nil
+}
+
+// stopBond deletes the bond from the running container and returns its slave devices to the host.
+// When the container has stopped, the bond is destroyed along with its network namespace and the kernel returns
+// the slave devices to the host by itself.
+func (d *nicPhysical) stopBond(slaves []string) error {
+	pid := d.inst.InitPID()
+	if pid <= 0 {
+		return nil
+	}
+
+	err := networkInstanceNetnsDo(pid, func() error {
+		// Deleting the bond releases its slaves.
+		_, err := net.InterfaceByName(d.config["name"])
+		if err == nil {
+			bond := &ip.Link{Name: d.config["name"]}
+			err = bond.Delete()
+			if err != nil {
+				return fmt.Errorf("Failed removing bond %q: %w", bond.Name, err)
+			}
+		}
+
+		for _, slave := range slaves {
+			_, err := net.InterfaceByName(slave)
+			if err != nil {
+				continue
+			}
+
+			// Remove all IP addresses from the slave before moving it back, to avoid leaking the
+			// container's address config into the host.
+			addr := &ip.Addr{DevName: slave}
+			err = addr.Flush()
+			if err != nil {
+				return err
+			}
+
+			link := &ip.Link{Name: slave}
+			err = link.SetDown()
+			if err != nil {
+				return fmt.Errorf("Failed to bring down %q: %w", slave, err)
+			}
+
+			err = link.SetNetns(strconv.Itoa(os.Getpid()))
+			if err != nil {
+				return fmt.Errorf("Failed moving %q back to the host: %w", slave, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
 // Start is run when the device is added to a running instance or instance is starting up.
 func (d *nicPhysical) Start() (*deviceConfig.RunConfig, error) {
 	err := d.validateEnvironment()
@@ -144,6 +345,24 @@ func (d *nicPhysical) Start() (*deviceConfig.RunConfig, error) {
 		}
 	}
 
+	// Bond multiple parents from within the container once it's running.
+	if d.inst.Type() == instancetype.Container && len(d.parents()) > 1 {
+		slaves := d.availableParents()
+		saveData["last_state.bond.slaves"] = strings.Join(slaves, ",")
+
+		err = d.volatileSet(saveData)
+		if err != nil {
+			return nil, err
+		}
+
+		runConf := deviceConfig.RunConfig{
+			PostHooks: []func() error{func() error { return d.startBond(slaves) }},
+		}
+
+		revert.Success()
+		return &runConf, nil
+	}
+
 	// Record the host_name device used for restoration later.
 	saveData["host_name"] = network.GetHostDevice(d.config["parent"], d.config["vlan"])
 
@@ -327,8 +546,8 @@ func (d *nicPhysical) Stop() (*deviceConfig.RunConfig, error) {
 			DeviceName:     fmt.Sprintf("%s-%s-%s", d.name, v["last_state.usb.bus"], v["last_state.usb.device"]),
 			HostDevicePath: fmt.Sprintf("/dev/bus/usb/%s/%s", v["last_state.usb.bus"], v["last_state.usb.device"]),
 		})
-	} else {
-		// Handle all other NICs.
+	} else if v["last_state.bond.slaves"] == "" {
+		// Handle all other NICs, bonds are handled by postStop.
 		runConf.NetworkInterface = []deviceConfig.RunConfigItem{
 			{Key: "link", Value: v["host_name"]},
 		}
@@ -349,6 +568,7 @@ func (d *nicPhysical) postStop() error {
 			"last_state.pci.driver":    "",
 			"last_state.usb.bus":       "",
 			"last_state.usb.device":    "",
+			"last_state.bond.slaves":   "",
 		})
 	}()
 
@@ -365,6 +585,11 @@ func (d *nicPhysical) postStop() error {
 		if err != nil {
 			return err
 		}
+	} else if d.inst.Type() == instancetype.Container && v["last_state.bond.slaves"] != "" {
+		err := d.stopBond(strings.Split(v["last_state.bond.slaves"], ","))
+		if err != nil {
+			return err
+		}
 	} else if d.inst.Type() == instancetype.Container {
 		hostName := network.GetHostDevice(d.config["parent"], d.config["vlan"])
 
@@ -0,0 +1,115 @@
+package device
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+)
+
+func TestNICPhysicalValidateParent(t *testing.T) {
+	tests := []struct {
+		name         string
+		instanceType instancetype.Type
+		config       deviceConfig.Device
+		wantErr      bool
+	}{
+		{name: "Single parent", instanceType: instancetype.Container, config: deviceConfig.Device{"parent": "eth0"}},
+		{name: "Single parent with VLAN", instanceType: instancetype.Container, config: deviceConfig.Device{"parent": "eth0", "vlan": "10"}},
+		{name: "Single parent on VM", instanceType: instancetype.VM, config: deviceConfig.Device{"parent": "eth0"}},
+		{name: "Multiple parents", instanceType: instancetype.Container, config: deviceConfig.Device{"parent": "eth0,eth1"}},
+		{name: "Multiple parents with spaces", instanceType: instancetype.Container, config: deviceConfig.Device{"parent": "eth0, eth1"}},
+		{name: "Missing parent", instanceType: instancetype.Container, config: deviceConfig.Device{}, wantErr: true},
+		{name: "Invalid parent", instanceType: instancetype.Container, config: deviceConfig.Device{"parent": "eth/0"}, wantErr: true},
+		{name: "Parent too long", instanceType: instancetype.Container, config: deviceConfig.Device{"parent": "eth0123456789abc"}, wantErr: true},
+		{name: "Invalid second parent", instanceType: instancetype.Container, config: deviceConfig.Device{"parent": "eth0,eth/1"}, wantErr: true},
+		{name: "Empty second parent", instanceType: instancetype.Container, config: deviceConfig.Device{"parent": "eth0,"}, wantErr: true},
+		{name: "Multiple parents with VLAN", instanceType: instancetype.Container, config: deviceConfig.Device{"parent": "eth0,eth1", "vlan": "10"}, wantErr: true},
+		{name: "Multiple parents on VM", instanceType: instancetype.VM, config: deviceConfig.Device{"parent": "eth0,eth1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &nicPhysical{}
+			d.config = tt.config
+			d.config["type"] = "nic"
+			d.config["nictype"] = "physical"
+
+			err := d.validateConfig(&testInstance{instanceType: tt.instanceType})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNICPhysicalParents(t *testing.T) {
+	tests := []struct {
+		parent string
+		want   []string
+	}{
+		{parent: "", want: nil},
+		{parent: "eth0", want: []string{"eth0"}},
+		{parent: "eth0,eth1", want: []string{"eth0", "eth1"}},
+		{parent: " eth0 , eth1 ", want: []string{"eth0", "eth1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.parent, func(t *testing.T) {
+			d := &nicPhysical{}
+			d.config = deviceConfig.Device{"parent": tt.parent}
+
+			assert.Equal(t, tt.want, d.parents())
+		})
+	}
+}
+
+func TestNICPhysicalBond(t *testing.T) {
+	volatile := map[string]string{}
+
+	d := &nicPhysical{}
+	d.inst = &testInstance{instanceType: instancetype.Container}
+	d.name = "eth0"
+	d.config = deviceConfig.Device{"type": "nic", "nictype": "physical", "name": "eth0", "parent": "lo,incusmissing0"}
+	d.volatileGet = func() map[string]string { return volatile }
+	d.volatileSet = func(save map[string]string) error {
+		for k, v := range save {
+			if v == "" {
+				delete(volatile, k)
+				continue
+			}
+
+			volatile[k] = v
+		}
+
+		return nil
+	}
+
+	// The bond is setup from within the container once started, using only the existing parents.
+	runConf, err := d.Start()
+	assert.NoError(t, err)
+	assert.Empty(t, runConf.NetworkInterface)
+	assert.Len(t, runConf.PostHooks, 1)
+	assert.Equal(t, map[string]string{"last_state.bond.slaves": "lo"}, volatile)
+
+	// The bond isn't passed to the instance, so it isn't detached from it either.
+	runConf, err = d.Stop()
+	assert.NoError(t, err)
+	assert.Empty(t, runConf.NetworkInterface)
+	assert.Len(t, runConf.PostHooks, 1)
+
+	// Once the container has stopped, the slaves are already back on the host.
+	err = d.postStop()
+	assert.NoError(t, err)
+	assert.Empty(t, volatile)
+
+	// A bond requires at least one of its parents to exist.
+	d.config["parent"] = "incusmissing0,incusmissing1"
+	_, err = d.Start()
+	assert.Error(t, err)
+	assert.Empty(t, volatile)
+}
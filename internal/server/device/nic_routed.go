@@ -65,6 +65,8 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 		"mtu",
 		"queue.tx.length",
 		"hwaddr",
+		"hwaddr.stable",
+		"hwaddr.oui",
 		"host_name",
 		"vlan",
 		"limits.ingress",
@@ -94,6 +96,11 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
+	err = networkValidateLimits(d.config)
+	if err != nil {
+		return err
+	}
+
 	// Detect duplicate IPs in config.
 	for _, key := range []string{"ipv4.address", "ipv6.address"} {
 		ips := make(map[string]struct{})
@@ -162,8 +169,8 @@ func (d *nicRouted) validateEnvironment() error {
 				return fmt.Errorf("Routed mode requires sysctl net.ipv6.conf.%s.forwarding=1", "all")
 			}
 
-			// net.ipv6.conf.all.proxy_ndp=1 is needed otherwise unicast neighbour solicitations are .
-			// rejected This causes periodic latency spikes every 15-20s as the neighbour has to resort
+			// net.ipv6.conf.all.proxy_ndp=1 is needed otherwise unicast neighbour solicitations are
+			// rejected. This causes periodic latency spikes every 15-20s as the neighbour has to resort
 			// to using multicast NDP resolution and expires the previous neighbour entry.
 			ipv6ProxyNdpPath := fmt.Sprintf("net/ipv6/conf/%s/proxy_ndp", "all")
 			sysctlVal, err = localUtil.SysctlGet(ipv6ProxyNdpPath)
@@ -190,7 +197,7 @@ func (d *nicRouted) validateEnvironment() error {
 			}
 		}
 
-		// Check necessary devic specific sysctls are configured for use with l2proxy parent for routed mode.
+		// Check necessary device specific sysctls are configured for use with l2proxy parent for routed mode.
 		if d.config["ipv6.address"] != "" {
 			ipv6FwdPath := fmt.Sprintf("net/ipv6/conf/%s/forwarding", d.effectiveParentName)
 			sysctlVal, err := localUtil.SysctlGet(ipv6FwdPath)
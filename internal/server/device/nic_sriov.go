@@ -41,6 +41,8 @@ func (d *nicSRIOV) validateConfig(instConf instance.ConfigReader) error {
 		"network",
 		"parent",
 		"hwaddr",
+		"hwaddr.stable",
+		"hwaddr.oui",
 		"mtu",
 		"vlan",
 		"security.mac_filtering",
@@ -166,8 +168,22 @@ func (d *nicSRIOV) Start() (*deviceConfig.RunConfig, error) {
 
 	network.SRIOVVirtualFunctionMutex.Unlock()
 
+	// Record the effective VLAN, applied to the VF.
+	saveData["vlan"] = d.config["vlan"]
+
 	if d.inst.Type() == instancetype.Container {
-		err := networkSRIOVSetupContainerVFNIC(saveData["host_name"], d.config)
+		// Get the effective MTU, inherited from the parent if unset.
+		_, mtu, err := nicEffectiveParentAndMTU("sriov", d.config, network.GetDevMTU)
+		if err != nil {
+			return nil, err
+		}
+
+		saveData["mtu"] = fmt.Sprintf("%d", mtu)
+
+		vfConfig := d.config.Clone()
+		vfConfig["mtu"] = saveData["mtu"]
+
+		err = networkSRIOVSetupContainerVFNIC(saveData["host_name"], vfConfig)
 		if err != nil {
 			return nil, err
 		}
@@ -235,6 +251,8 @@ func (d *nicSRIOV) postStop() error {
 			"last_state.vf.vlan":       "",
 			"last_state.vf.spoofcheck": "",
 			"last_state.pci.driver":    "",
+			"mtu":                      "",
+			"vlan":                     "",
 		})
 	}()
 
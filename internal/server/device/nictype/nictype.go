@@ -16,10 +16,10 @@ import (
 // NICType resolves the NIC Type for the supplied NIC device config.
 // If the device "type" is "nic" and the "network" property is specified in the device config, then NIC type is
 // resolved from the network's type. Otherwise the device's "nictype" property is returned (which may be empty if
-// used with non-NIC device configs).
+// used with non-NIC device configs). A "nictype" of "none" always takes precedence.
 func NICType(s *state.State, deviceProjectName string, d deviceConfig.Device) (string, error) {
 	// NIC devices support resolving their "nictype" from their "network" property.
-	if d["type"] == "nic" {
+	if d["type"] == "nic" && d["nictype"] != "none" {
 		if d["network"] != "" {
 			// Translate device's project name into a network project name.
 			networkProjectName, _, err := project.NetworkProject(s.DB.Cluster, deviceProjectName)
@@ -20,7 +20,7 @@ func (d *none) CanHotPlug() bool {
 }
 
 // validateConfig checks the supplied config for correctness.
-// validateConfig checks the supplied config for correctness.
+// This is also used for nic devices with a nictype of "none", used to disable a NIC inherited from a profile.
 func (d *none) validateConfig(instConf instance.ConfigReader) error {
 	rules := map[string]func(string) error{} // No fields allowed.
 	err := d.config.Validate(rules)
@@ -0,0 +1,65 @@
+package device
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestNICNone(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  deviceConfig.Device
+		wantErr bool
+	}{
+		{name: "Disabled", config: deviceConfig.Device{"type": "nic", "nictype": "none"}},
+		{name: "User key", config: deviceConfig.Device{"type": "nic", "nictype": "none", "user.comment": "No network"}},
+		{name: "Network", config: deviceConfig.Device{"type": "nic", "nictype": "none", "network": "incusbr0"}, wantErr: true},
+		{name: "Parent", config: deviceConfig.Device{"type": "nic", "nictype": "none", "parent": "br0"}, wantErr: true},
+		{name: "Name", config: deviceConfig.Device{"type": "nic", "nictype": "none", "name": "eth0"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A "none" nictype takes precedence over any network and loads the none device.
+			dev, err := newByType(nil, api.ProjectDefaultName, tt.config)
+			assert.NoError(t, err)
+
+			d, ok := dev.(*none)
+			assert.True(t, ok)
+
+			d.config = tt.config
+			err = d.validateConfig(&testInstance{instanceType: instancetype.Container})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			runConf, err := d.Start()
+			assert.NoError(t, err)
+			assert.Nil(t, runConf)
+		})
+	}
+}
+
+// Test that a disabled NIC on the instance replaces the NIC inherited from a profile.
+func TestNICNoneOverride(t *testing.T) {
+	profiles := []api.Profile{{
+		Name: "default",
+		ProfilePut: api.ProfilePut{
+			Devices: map[string]map[string]string{"eth0": {"type": "nic", "network": "incusbr0"}},
+		},
+	}}
+
+	devices := deviceConfig.Devices{"eth0": deviceConfig.Device{"type": "nic", "nictype": "none"}}
+
+	expanded := db.ExpandInstanceDevices(devices, profiles)
+	assert.Equal(t, deviceConfig.Device{"type": "nic", "nictype": "none"}, expanded["eth0"])
+}
@@ -9,6 +9,7 @@ import (
 	pcidev "github.com/lxc/incus/v6/internal/server/device/pci"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/shared/revert"
 	"github.com/lxc/incus/v6/shared/util"
 	"github.com/lxc/incus/v6/shared/validate"
 )
@@ -78,11 +79,19 @@ func (d *pci) Start() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	revert := revert.New()
+	defer revert.Fail()
+
 	err = pcidev.DeviceDriverOverride(pciDev, "vfio-pci")
 	if err != nil {
 		return nil, fmt.Errorf("Failed to override IOMMU group driver: %w", err)
 	}
 
+	// Bind the device back to its original driver if it can't be passed to the instance.
+	revert.Add(func() {
+		_ = pcidev.DeviceDriverOverride(pcidev.Device{Driver: "vfio-pci", SlotName: pciDev.SlotName}, pciDev.Driver)
+	})
+
 	runConf.PCIDevice = append(runConf.PCIDevice,
 		[]deviceConfig.RunConfigItem{
 			{Key: "devName", Value: d.name},
@@ -95,6 +104,8 @@ func (d *pci) Start() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	revert.Success()
+
 	return &runConf, nil
 }
 
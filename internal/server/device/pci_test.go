@@ -0,0 +1,44 @@
+package device
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+)
+
+func TestPCIValidateConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		instanceType instancetype.Type
+		address      string
+		want         string
+		wantErr      bool
+	}{
+		{name: "Full address", instanceType: instancetype.VM, address: "0000:01:00.0", want: "0000:01:00.0"},
+		{name: "Short address", instanceType: instancetype.VM, address: "01:00.1", want: "0000:01:00.1"},
+		{name: "Uppercase address", instanceType: instancetype.VM, address: "0000:AF:00.0", want: "0000:af:00.0"},
+		{name: "Missing address", instanceType: instancetype.VM, address: "", wantErr: true},
+		{name: "Short bus", instanceType: instancetype.VM, address: "1:00.0", wantErr: true},
+		{name: "Missing function", instanceType: instancetype.VM, address: "0000:01:00", wantErr: true},
+		{name: "Container", instanceType: instancetype.Container, address: "0000:01:00.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &pci{}
+			d.config = deviceConfig.Device{"type": "pci", "address": tt.address}
+
+			err := d.validateConfig(&testInstance{instanceType: tt.instanceType})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, d.config["address"])
+		})
+	}
+}
@@ -3,7 +3,9 @@ package device
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"net"
 	"os"
 	"path/filepath"
@@ -13,6 +15,7 @@ import (
 	"time"
 
 	liblxc "github.com/lxc/go-lxc"
+	"golang.org/x/sys/unix"
 
 	"github.com/lxc/incus/v6/internal/linux"
 	"github.com/lxc/incus/v6/internal/server/apparmor"
@@ -370,6 +373,16 @@ func (d *proxy) Stop() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	// The forkproxy process runs in the connect namespace, so remove the unix socket it was listening on
+	// from the listening side.
+	rootPath, socketPath := proxyListenSocketPath(d.config["listen"], d.config["bind"], d.inst.InitPID())
+	if socketPath != "" {
+		err = proxyRemoveUnixSocket(rootPath, socketPath)
+		if err != nil {
+			logger.Warn("Failed to remove proxy unix socket", logger.Ctx{"device": d.name, "path": socketPath, "err": err})
+		}
+	}
+
 	// Unload apparmor profile.
 	err = apparmor.ForkproxyUnload(d.state.OS, d.inst, d)
 	if err != nil {
@@ -379,6 +392,73 @@ func (d *proxy) Stop() (*deviceConfig.RunConfig, error) {
 	return nil, nil
 }
 
+// proxyListenSocketPath returns the root directory and the path within it of the unix socket of the listen
+// address, or an empty path if there is no socket file to remove.
+// When listening inside the instance, the root is that of its init process.
+func proxyListenSocketPath(listen string, bind string, initPID int) (string, string) {
+	listenAddr, err := network.ProxyParseAddr(listen)
+	if err != nil || listenAddr.ConnType != "unix" || listenAddr.Abstract || !filepath.IsAbs(listenAddr.Address) {
+		return "", ""
+	}
+
+	if bind == "" || bind == "host" {
+		return "/", listenAddr.Address
+	}
+
+	if initPID <= 0 {
+		return "", ""
+	}
+
+	return fmt.Sprintf("/proc/%d/root", initPID), listenAddr.Address
+}
+
+// proxyRemoveUnixSocket removes the unix socket at the path, resolved within the root directory.
+// The instance controls its own filesystem, so resolution is confined to the root so that symlinks can't point
+// the removal to a path on the host, and only sockets are removed.
+func proxyRemoveUnixSocket(rootPath string, socketPath string) error {
+	root, err := os.OpenFile(rootPath, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("Failed opening root %q: %w", rootPath, err)
+	}
+
+	defer func() { _ = root.Close() }()
+
+	dirFd, err := unix.Openat2(int(root.Fd()), filepath.Dir(socketPath), &unix.OpenHow{
+		Flags:   unix.O_PATH | unix.O_DIRECTORY | unix.O_CLOEXEC,
+		Resolve: unix.RESOLVE_IN_ROOT | unix.RESOLVE_NO_MAGICLINKS,
+	})
+	if err != nil {
+		if errors.Is(err, unix.ENOENT) {
+			return nil
+		}
+
+		return fmt.Errorf("Failed opening parent directory of %q: %w", socketPath, err)
+	}
+
+	defer func() { _ = unix.Close(dirFd) }()
+
+	var stat unix.Stat_t
+	err = unix.Fstatat(dirFd, filepath.Base(socketPath), &stat, unix.AT_SYMLINK_NOFOLLOW)
+	if err != nil {
+		if errors.Is(err, unix.ENOENT) {
+			return nil
+		}
+
+		return err
+	}
+
+	if stat.Mode&unix.S_IFMT != unix.S_IFSOCK {
+		return fmt.Errorf("Path %q isn't a unix socket", socketPath)
+	}
+
+	err = unix.Unlinkat(dirFd, filepath.Base(socketPath), 0)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
 func (d *proxy) setupNAT() error {
 	listenAddr, err := network.ProxyParseAddr(d.config["listen"])
 	if err != nil {
@@ -0,0 +1,71 @@
+package device
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestProxyListenSocketPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		listen   string
+		bind     string
+		initPID  int
+		wantRoot string
+		wantPath string
+	}{
+		{name: "Host socket", listen: "unix:/run/app.sock", bind: "host", initPID: 1234, wantRoot: "/", wantPath: "/run/app.sock"},
+		{name: "Default bind", listen: "unix:/run/app.sock", initPID: 1234, wantRoot: "/", wantPath: "/run/app.sock"},
+		{name: "Instance socket", listen: "unix:/run/app.sock", bind: "instance", initPID: 1234, wantRoot: "/proc/1234/root", wantPath: "/run/app.sock"},
+		{name: "Instance not running", listen: "unix:/run/app.sock", bind: "instance", initPID: -1},
+		{name: "Abstract socket", listen: "unix:@app", bind: "host"},
+		{name: "Relative path", listen: "unix:app.sock", bind: "host"},
+		{name: "TCP", listen: "tcp:127.0.0.1:80", bind: "host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, path := proxyListenSocketPath(tt.listen, tt.bind, tt.initPID)
+			assert.Equal(t, tt.wantRoot, root)
+			assert.Equal(t, tt.wantPath, path)
+		})
+	}
+}
+
+func TestProxyRemoveUnixSocket(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	mkSocket := func(path string) {
+		require.NoError(t, unix.Mknod(path, unix.S_IFSOCK|0o600, 0))
+	}
+
+	require.NoError(t, os.Mkdir(filepath.Join(root, "run"), 0o755))
+	mkSocket(filepath.Join(root, "run", "app.sock"))
+	mkSocket(filepath.Join(outside, "app.sock"))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "run", "file"), nil, 0o600))
+
+	// Symlinks pointing outside of the root are resolved within it.
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "escape")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "app.sock"), filepath.Join(root, "run", "link.sock")))
+
+	assert.NoError(t, proxyRemoveUnixSocket(root, "/run/app.sock"))
+	assert.NoFileExists(t, filepath.Join(root, "run", "app.sock"))
+
+	assert.NoError(t, proxyRemoveUnixSocket(root, "/escape/app.sock"))
+	assert.FileExists(t, filepath.Join(outside, "app.sock"))
+
+	assert.Error(t, proxyRemoveUnixSocket(root, "/run/link.sock"))
+	assert.FileExists(t, filepath.Join(outside, "app.sock"))
+
+	assert.Error(t, proxyRemoveUnixSocket(root, "/run/file"))
+	assert.FileExists(t, filepath.Join(root, "run", "file"))
+
+	assert.NoError(t, proxyRemoveUnixSocket(root, "/run/missing.sock"))
+	assert.NoError(t, proxyRemoveUnixSocket(root, "/missing/app.sock"))
+}
@@ -37,8 +37,8 @@ func (d *tpm) validateConfig(instConf instance.ConfigReader) error {
 	rules := map[string]func(string) error{}
 
 	if instConf.Type() == instancetype.Container {
-		rules["path"] = validate.IsNotEmpty
-		rules["pathrm"] = validate.IsNotEmpty
+		rules["path"] = validate.And(validate.IsNotEmpty, validate.IsAbsFilePath)
+		rules["pathrm"] = validate.And(validate.IsNotEmpty, validate.IsAbsFilePath)
 	} else {
 		rules["path"] = validate.Optional(validate.IsNotEmpty)
 		rules["pathrm"] = validate.Optional(validate.IsNotEmpty)
@@ -49,6 +49,11 @@ func (d *tpm) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("Failed to validate config: %w", err)
 	}
 
+	// Both device nodes are created inside the container so they can't share a path.
+	if instConf.Type() == instancetype.Container && filepath.Clean(d.config["path"]) == filepath.Clean(d.config["pathrm"]) {
+		return fmt.Errorf(`The "path" and "pathrm" properties must be different`)
+	}
+
 	return nil
 }
 
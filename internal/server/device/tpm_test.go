@@ -0,0 +1,41 @@
+package device
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+)
+
+func TestTPMValidateConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		instanceType instancetype.Type
+		config       deviceConfig.Device
+		wantErr      bool
+	}{
+		{name: "Container", instanceType: instancetype.Container, config: deviceConfig.Device{"path": "/dev/tpm0", "pathrm": "/dev/tpmrm0"}},
+		{name: "Container without paths", instanceType: instancetype.Container, config: deviceConfig.Device{}, wantErr: true},
+		{name: "Container without pathrm", instanceType: instancetype.Container, config: deviceConfig.Device{"path": "/dev/tpm0"}, wantErr: true},
+		{name: "Container with relative path", instanceType: instancetype.Container, config: deviceConfig.Device{"path": "dev/tpm0", "pathrm": "/dev/tpmrm0"}, wantErr: true},
+		{name: "Container with the same paths", instanceType: instancetype.Container, config: deviceConfig.Device{"path": "/dev/tpm0", "pathrm": "/dev//tpm0"}, wantErr: true},
+		{name: "Virtual machine", instanceType: instancetype.VM, config: deviceConfig.Device{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &tpm{}
+			d.config = tt.config
+			d.config["type"] = "tpm"
+
+			err := d.validateConfig(&testInstance{instanceType: tt.instanceType})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
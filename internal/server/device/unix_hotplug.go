@@ -87,7 +87,7 @@ func (d *unixHotplug) validateConfig(instConf instance.ConfigReader) error {
 		//
 		// ---
 		//  type: bool
-		//  default: true
+		//  default: false
 		//  shortdesc: Whether this device is required to start the instance
 		"required": validate.Optional(validate.IsBool),
 	}
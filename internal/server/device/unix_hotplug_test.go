@@ -0,0 +1,55 @@
+//go:build linux && cgo
+
+package device
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+)
+
+func TestUnixHotplugIsOurDevice(t *testing.T) {
+	event, err := UnixHotplugNewEvent("add", "1d6b", "0002", "189", "1", "usb", "/dev/bus/usb/001/001", nil, 0)
+	assert.NoError(t, err)
+
+	assert.True(t, unixHotplugIsOurDevice(deviceConfig.Device{"vendorid": "1d6b", "productid": "0002"}, &event))
+	assert.True(t, unixHotplugIsOurDevice(deviceConfig.Device{"vendorid": "1d6b"}, &event))
+	assert.True(t, unixHotplugIsOurDevice(deviceConfig.Device{"productid": "0002"}, &event))
+	assert.False(t, unixHotplugIsOurDevice(deviceConfig.Device{"vendorid": "1d6b", "productid": "0003"}, &event))
+	assert.False(t, unixHotplugIsOurDevice(deviceConfig.Device{"vendorid": "046d"}, &event))
+
+	_, err = UnixHotplugNewEvent("add", "1d6b", "0002", "abc", "1", "usb", "/dev/bus/usb/001/001", nil, 0)
+	assert.Error(t, err)
+}
+
+func TestUnixHotplugValidateConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  deviceConfig.Device
+		wantErr bool
+	}{
+		{name: "Vendor and product", config: deviceConfig.Device{"vendorid": "1d6b", "productid": "0002"}},
+		{name: "Vendor only", config: deviceConfig.Device{"vendorid": "1d6b"}},
+		{name: "No selector", config: deviceConfig.Device{}, wantErr: true},
+		{name: "Uppercase vendor", config: deviceConfig.Device{"vendorid": "1D6B"}, wantErr: true},
+		{name: "Short product", config: deviceConfig.Device{"productid": "02"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &unixHotplug{}
+			d.config = tt.config
+			d.config["type"] = "unix-hotplug"
+
+			err := d.validateConfig(&testInstance{instanceType: instancetype.Container})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
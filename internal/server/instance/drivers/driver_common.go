@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -584,6 +585,12 @@ func (d *common) expandConfig() error {
 
 // restartCommon handles the common part of instance restarts.
 func (d *common) restartCommon(inst instance.Instance, timeout time.Duration) error {
+	// Check this before stopping the instance as it wouldn't be able to start again.
+	err := d.checkStartProtection()
+	if err != nil {
+		return err
+	}
+
 	// Setup a new operation for the stop/shutdown phase.
 	op, err := operationlock.Create(d.Project().Name, d.Name(), d.op, operationlock.ActionRestart, true, true)
 	if err != nil {
@@ -689,6 +696,7 @@ func (d *common) rebuildCommon(inst instance.Instance, img *api.Image, op *opera
 
 	// Reset relevant volatile keys.
 	delete(instLocalConfig, "volatile.idmap.next")
+	delete(instLocalConfig, "volatile.idmap.size")
 	delete(instLocalConfig, "volatile.last_state.idmap")
 
 	pool, err := d.getStoragePool()
@@ -751,15 +759,24 @@ func (d *common) runHooks(hooks []func() error) error {
 }
 
 // snapshot handles the common part of the snapshoting process.
-func (d *common) snapshotCommon(inst instance.Instance, name string, expiry time.Time, stateful bool) error {
+// Snapshots created by snapshots.schedule are marked as scheduled.
+func (d *common) snapshotCommon(inst instance.Instance, name string, expiry time.Time, stateful bool, scheduled bool) error {
 	revert := revert.New()
 	defer revert.Fail()
 
+	// Don't carry over the scheduled marker an instance got when restored from a scheduled snapshot.
+	config := maps.Clone(inst.LocalConfig())
+	delete(config, "volatile.snapshot.scheduled")
+
+	if scheduled {
+		config["volatile.snapshot.scheduled"] = "true"
+	}
+
 	// Setup the arguments.
 	args := db.InstanceArgs{
 		Project:      inst.Project().Name,
 		Architecture: inst.Architecture(),
-		Config:       inst.LocalConfig(),
+		Config:       config,
 		Type:         inst.Type(),
 		Snapshot:     true,
 		Devices:      inst.LocalDevices(),
@@ -881,7 +898,7 @@ func (d *common) getStartupSnapNameAndExpiry(inst instance.Instance) (string, *t
 		return "", nil, nil
 	}
 
-	triggers := strings.Split(schedule, ", ")
+	triggers := internalInstance.SnapshotScheduleTriggers(schedule)
 	if !slices.Contains(triggers, "@startup") {
 		return "", nil, nil
 	}
@@ -899,6 +916,30 @@ func (d *common) getStartupSnapNameAndExpiry(inst instance.Instance) (string, *t
 	return name, &expiry, nil
 }
 
+// checkStartProtection returns an error if security.protection.start prevents the instance from starting.
+func (d *common) checkStartProtection() error {
+	if util.IsTrue(d.expandedConfig["security.protection.start"]) {
+		return api.StatusErrorf(http.StatusForbidden, "Instance is protected from being started")
+	}
+
+	return nil
+}
+
+// validateHostConfig checks the config against the local host (see instance.ValidHostConfig).
+// Issues are only returned when enforced, otherwise they're logged.
+func (d *common) validateHostConfig(enforce bool) error {
+	err := instance.ValidHostConfig(d.expandedConfig, d.Type())
+	if err != nil {
+		if enforce {
+			return err
+		}
+
+		d.logger.Warn("Instance config isn't supported by this host", logger.Ctx{"err": err})
+	}
+
+	return nil
+}
+
 // validateStartup checks any constraints that would prevent start up from succeeding under normal circumstances.
 func (d *common) validateStartup(stateful bool, statusCode api.StatusCode) error {
 	// Because the root disk is special and is mounted before the root disk device is setup we duplicate the
@@ -914,6 +955,11 @@ func (d *common) validateStartup(stateful bool, statusCode api.StatusCode) error
 		return api.StatusErrorf(http.StatusServiceUnavailable, "Storage pool %q unavailable on this server", rootDiskConf["pool"])
 	}
 
+	err = d.checkStartProtection()
+	if err != nil {
+		return err
+	}
+
 	// Validate architecture.
 	if !slices.Contains(d.state.OS.Architectures, d.architecture) {
 		return fmt.Errorf("Requested architecture isn't supported by this host")
@@ -1103,6 +1149,7 @@ func (d *common) needsNewInstanceID(changedConfig []string, oldExpandedDevices d
 		"cloud-init.vendor-data",
 		"cloud-init.user-data",
 		"cloud-init.network-config",
+		"cloud-init.ssh-keys",
 		"user.vendor-data",
 		"user.user-data",
 		"user.network-config",
@@ -1564,15 +1611,27 @@ func (d *common) setNUMANode() error {
 		}
 	}
 
-	// Pick least used node.
-	var node uint64
+	node := numaLeastUsedNode(nodes, numaUsage)
+
+	return d.VolatileSet(map[string]string{"volatile.cpu.nodes": fmt.Sprintf("%d", node)})
+}
+
+// numaLeastUsedNode returns the NUMA node with the fewest instances, picking the lowest node ID on ties.
+// Node 0 is returned when the nodes list is empty.
+func numaLeastUsedNode(nodes []uint64, numaUsage map[int64]int) uint64 {
+	if len(nodes) == 0 {
+		return 0
+	}
+
+	node := slices.Min(nodes)
 	for _, numaNode := range nodes {
-		if numaUsage[int64(numaNode)] < numaUsage[int64(node)] {
+		usage := numaUsage[int64(numaNode)]
+		if usage < numaUsage[int64(node)] || (usage == numaUsage[int64(node)] && numaNode < node) {
 			node = numaNode
 		}
 	}
 
-	return d.VolatileSet(map[string]string{"volatile.cpu.nodes": fmt.Sprintf("%d", node)})
+	return node
 }
 
 // Gets the process starting time.
@@ -0,0 +1,35 @@
+package drivers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestNUMALeastUsedNode(t *testing.T) {
+	// Ties go to the lowest node.
+	assert.Equal(t, uint64(0), numaLeastUsedNode([]uint64{1, 0}, map[int64]int{}))
+
+	// The least used node wins.
+	assert.Equal(t, uint64(1), numaLeastUsedNode([]uint64{0, 1}, map[int64]int{0: 2, 1: 1}))
+
+	// Nodes missing from the system are never picked, even if unused.
+	assert.Equal(t, uint64(2), numaLeastUsedNode([]uint64{1, 2}, map[int64]int{1: 3, 2: 1}))
+	assert.Equal(t, uint64(1), numaLeastUsedNode([]uint64{2, 1}, map[int64]int{}))
+
+	// No nodes at all.
+	assert.Equal(t, uint64(0), numaLeastUsedNode(nil, map[int64]int{0: 1}))
+}
+
+func TestCheckStartProtection(t *testing.T) {
+	d := &common{expandedConfig: map[string]string{}}
+	assert.NoError(t, d.checkStartProtection())
+
+	d.expandedConfig["security.protection.start"] = "true"
+	err := d.checkStartProtection()
+	assert.Error(t, err)
+	assert.True(t, api.StatusErrorCheck(err, http.StatusForbidden))
+}
@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"maps"
+	"math"
 	"net"
 	"net/http"
 	"os"
@@ -151,6 +153,70 @@ func lxcSetConfigItem(c *liblxc.Container, key string, value string) error {
 	return nil
 }
 
+// lxcCgroupConfigKey returns the LXC configuration key for a cgroup setting on the given cgroup layout.
+// Pure cgroup2 hosts need the "lxc.cgroup2." keys as the "lxc.cgroup." ones only apply to cgroup1 controllers.
+func lxcCgroupConfigKey(layout cgroup.Layout, key string) string {
+	if layout == cgroup.CgroupsUnified {
+		return fmt.Sprintf("lxc.cgroup2.%s", key)
+	}
+
+	return fmt.Sprintf("lxc.cgroup.%s", key)
+}
+
+// lxcHighestID returns the highest ID found in the third field of a passwd or group style file.
+// Returns -1 when the file doesn't exist or doesn't contain any ID. Symlinks aren't followed as the file comes
+// from a container's root filesystem.
+func lxcHighestID(path string) (int64, error) {
+	dirInfo, err := os.Lstat(filepath.Dir(path))
+	if err != nil || !dirInfo.IsDir() {
+		return -1, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_RDONLY|unix.O_NOFOLLOW|unix.O_NONBLOCK, 0)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, unix.ELOOP) {
+			return -1, nil
+		}
+
+		return -1, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return -1, err
+	}
+
+	if !info.Mode().IsRegular() {
+		return -1, nil
+	}
+
+	var maxID int64 = -1
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 3 {
+			continue
+		}
+
+		// Skip the overflow IDs which are never mapped.
+		id, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil || id < 0 || id >= math.MaxUint32-1 {
+			continue
+		}
+
+		maxID = max(maxID, id)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return -1, err
+	}
+
+	return maxID, nil
+}
+
 func lxcStatusCode(state liblxc.State) api.StatusCode {
 	return map[int]api.StatusCode{
 		1: api.Stopped,
@@ -228,6 +294,15 @@ func lxcCreate(s *state.State, args db.InstanceArgs, p api.Project, op *operatio
 		return nil, nil, fmt.Errorf("Invalid config: %w", err)
 	}
 
+	advisory := internalInstance.MemoryConfigAdvisory(d.expandedConfig)
+	if advisory != "" {
+		d.logger.Warn(advisory)
+	}
+
+	// The config may come from another host (copy, migration, backup), so only log host related issues.
+	_ = d.validateHostConfig(false)
+	d.logSwapAdvisory()
+
 	err = instance.ValidDevices(s, d.project, d.Type(), d.localDevices, d.expandedDevices)
 	if err != nil {
 		return nil, nil, fmt.Errorf("Invalid devices: %w", err)
@@ -441,7 +516,12 @@ type lxc struct {
 var idmapLock sync.Mutex
 
 func (d *lxc) findIdmap() (*idmap.Set, int64, error) {
-	idmapSize := func(size string) (int64, error) {
+	idmapSize := func(size string, autoSize string) (int64, error) {
+		// Use the size computed from the root filesystem on first start when available.
+		if size == "auto" && autoSize != "" && autoSize != "0" {
+			return strconv.ParseInt(autoSize, 10, 64)
+		}
+
 		var idMapSize int64
 		if size == "" || size == "auto" {
 			if util.IsTrue(d.expandedConfig["security.idmap.isolated"]) {
@@ -493,7 +573,7 @@ func (d *lxc) findIdmap() (*idmap.Set, int64, error) {
 
 		// Restrict the range sizes if specified.
 		if d.expandedConfig["security.idmap.size"] != "" {
-			size, err := idmapSize(d.expandedConfig["security.idmap.size"])
+			size, err := idmapSize(d.expandedConfig["security.idmap.size"], d.localConfig["volatile.idmap.size"])
 			if err != nil {
 				return nil, 0, err
 			}
@@ -518,7 +598,7 @@ func (d *lxc) findIdmap() (*idmap.Set, int64, error) {
 		return &newIdmapset, 0, nil
 	}
 
-	size, err := idmapSize(d.expandedConfig["security.idmap.size"])
+	size, err := idmapSize(d.expandedConfig["security.idmap.size"], d.localConfig["volatile.idmap.size"])
 	if err != nil {
 		return nil, 0, err
 	}
@@ -575,7 +655,7 @@ func (d *lxc) findIdmap() (*idmap.Set, int64, error) {
 			return nil, 0, err
 		}
 
-		cSize, err := idmapSize(container.ExpandedConfig()["security.idmap.size"])
+		cSize, err := idmapSize(container.ExpandedConfig()["security.idmap.size"], container.LocalConfig()["volatile.idmap.size"])
 		if err != nil {
 			return nil, 0, err
 		}
@@ -630,6 +710,55 @@ func (d *lxc) findIdmap() (*idmap.Set, int64, error) {
 	return nil, 0, fmt.Errorf("Not enough uid/gid available for the container")
 }
 
+// logSwapAdvisory logs the swap settings which merely have no effect without swap on the host.
+func (d *lxc) logSwapAdvisory() {
+	swapTotal, err := linux.GetMeminfo("SwapTotal")
+	if err != nil {
+		return
+	}
+
+	advisory, _ := internalInstance.ValidateSwapConfig(d.expandedConfig, swapTotal > 0)
+	if advisory != "" {
+		d.logger.Warn(advisory)
+	}
+}
+
+// idmapSizeFromRootfs returns the idmap size needed to cover the highest user and group IDs defined in the
+// /etc/passwd and /etc/group files of the container's root filesystem, with a minimum of 65536 and capped to the
+// size of the host idmap. Returns 0 when the root filesystem isn't available yet or doesn't define any user or group.
+func (d *lxc) idmapSizeFromRootfs() (int64, error) {
+	_, err := d.mount()
+	if err != nil {
+		return 0, nil
+	}
+
+	defer func() { _ = d.unmount() }()
+
+	var maxID int64 = -1
+	for _, name := range []string{"passwd", "group"} {
+		id, err := lxcHighestID(filepath.Join(d.RootfsPath(), "etc", name))
+		if err != nil {
+			return 0, err
+		}
+
+		maxID = max(maxID, id)
+	}
+
+	if maxID < 0 {
+		return 0, nil
+	}
+
+	size := max(maxID+1, 65536)
+
+	// The files are controlled by the container, so never go beyond the IDs allocated to the host.
+	if d.state.OS.IdmapSet != nil && len(d.state.OS.IdmapSet.Entries) > 0 && size > d.state.OS.IdmapSet.Entries[0].MapRange {
+		d.logger.Warn("Root filesystem uses IDs beyond the host idmap, capping the idmap size", logger.Ctx{"highestID": maxID, "size": d.state.OS.IdmapSet.Entries[0].MapRange})
+		size = d.state.OS.IdmapSet.Entries[0].MapRange
+	}
+
+	return size, nil
+}
+
 func (d *lxc) init() error {
 	// Compute the expanded config and device list
 	err := d.expandConfig()
@@ -862,12 +991,7 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 
 	// Configure devices cgroup
 	if d.IsPrivileged() && !d.state.OS.RunningInUserNS && d.state.OS.CGInfo.Supports(cgroup.Devices, cg) {
-		if d.state.OS.CGInfo.Layout == cgroup.CgroupsUnified {
-			err = lxcSetConfigItem(cc, "lxc.cgroup2.devices.deny", "a")
-		} else {
-			err = lxcSetConfigItem(cc, "lxc.cgroup.devices.deny", "a")
-		}
-
+		err = lxcSetConfigItem(cc, lxcCgroupConfigKey(d.state.OS.CGInfo.Layout, "devices.deny"), "a")
 		if err != nil {
 			return nil, err
 		}
@@ -893,12 +1017,7 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		}
 
 		for _, dev := range devices {
-			if d.state.OS.CGInfo.Layout == cgroup.CgroupsUnified {
-				err = lxcSetConfigItem(cc, "lxc.cgroup2.devices.allow", dev)
-			} else {
-				err = lxcSetConfigItem(cc, "lxc.cgroup.devices.allow", dev)
-			}
-
+			err = lxcSetConfigItem(cc, lxcCgroupConfigKey(d.state.OS.CGInfo.Layout, "devices.allow"), dev)
 			if err != nil {
 				return nil, err
 			}
@@ -1200,17 +1319,35 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		}
 	}
 
+	// OOM score adjustment, set on the init process and inherited by all other processes.
+	oomScoreAdj := d.expandedConfig["limits.memory.oom_score_adj"]
+	if oomScoreAdj != "" {
+		err = lxcSetConfigItem(cc, "lxc.proc.oom_score_adj", oomScoreAdj)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// CPU limits
 	cpuPriority := d.expandedConfig["limits.cpu.priority"]
 	cpuAllowance := d.expandedConfig["limits.cpu.allowance"]
+	cpuWeight := d.expandedConfig["limits.cpu.weight"]
 
-	if (cpuPriority != "" || cpuAllowance != "") && d.state.OS.CGInfo.Supports(cgroup.CPU, cg) {
+	if (cpuPriority != "" || cpuAllowance != "" || cpuWeight != "") && d.state.OS.CGInfo.Supports(cgroup.CPU, cg) {
 		cpuShares, cpuCfsQuota, cpuCfsPeriod, err := cgroup.ParseCPU(cpuAllowance, cpuPriority)
 		if err != nil {
 			return nil, err
 		}
 
-		if cpuShares != 1024 {
+		// An explicit weight takes precedence over the one derived from the priority and allowance.
+		if cpuWeight != "" {
+			cpuShares, err = cgroup.ParseCPUWeight(cpuWeight)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if cpuShares != 1024 || cpuWeight != "" {
 			err = cg.SetCPUShare(cpuShares)
 			if err != nil {
 				return nil, err
@@ -1229,19 +1366,12 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 	diskPriority := d.ExpandedConfig()["limits.disk.priority"]
 	if diskPriority != "" {
 		if d.state.OS.CGInfo.Supports(cgroup.BlkioWeight, nil) {
-			priorityInt, err := strconv.Atoi(diskPriority)
+			weight, err := cgroup.ParseDiskPriority(diskPriority)
 			if err != nil {
 				return nil, err
 			}
 
-			priority := priorityInt * 100
-
-			// Minimum valid value is 10
-			if priority == 0 {
-				priority = 10
-			}
-
-			err = cg.SetBlkioWeight(int64(priority))
+			err = cg.SetBlkioWeight(weight)
 			if err != nil {
 				return nil, err
 			}
@@ -1259,6 +1389,11 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 				return nil, err
 			}
 
+			valueInt, clamped := internalInstance.ClampProcessesLimit(valueInt)
+			if clamped {
+				d.logger.Warn("Capping processes limit to the kernel maximum", logger.Ctx{"requested": processes, "limit": valueInt})
+			}
+
 			err = cg.SetMaxProcesses(valueInt)
 			if err != nil {
 				return nil, err
@@ -1286,6 +1421,10 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 
 	// Setup process limits
 	for k, v := range d.expandedConfig {
+		if k == "limits.kernel.nofile" {
+			continue // Applied below along with limits.nofile.
+		}
+
 		if strings.HasPrefix(k, "limits.kernel.") {
 			prlimitSuffix := strings.TrimPrefix(k, "limits.kernel.")
 			prlimitKey := fmt.Sprintf("lxc.prlimit.%s", prlimitSuffix)
@@ -1296,6 +1435,14 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		}
 	}
 
+	nofile := internalInstance.NofileLimit(d.localConfig, d.expandedConfig)
+	if nofile != "" {
+		err = lxcSetConfigItem(cc, "lxc.prlimit.nofile", nofile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Setup sysctls
 	for k, v := range d.expandedConfig {
 		// gendoc:generate(entity=instance, group=miscellaneous, key=linux.sysctl.*)
@@ -1685,10 +1832,19 @@ func (d *lxc) deviceDetachNIC(configCopy map[string]string, netIF []deviceConfig
 }
 
 // deviceHandleMounts live attaches or detaches mounts on a container.
-// If the mount DevPath is empty the mount action is treated as unmount.
+// If the mount DevPath is empty the mount action is treated as unmount, unless Remount is set in which case only
+// the read-only state of the existing mount is changed.
 func (d *lxc) deviceHandleMounts(mounts []deviceConfig.MountEntryItem) error {
+	revert := revert.New()
+	defer revert.Fail()
+
 	for _, mount := range mounts {
-		if mount.DevPath != "" {
+		if mount.Remount {
+			err := d.remountMount(mount.TargetPath, slices.Contains(mount.Opts, "ro"))
+			if err != nil {
+				return fmt.Errorf("Failed to remount device inside container: %w", err)
+			}
+		} else if mount.DevPath != "" {
 			flags := 0
 
 			// Convert options into flags.
@@ -1715,6 +1871,10 @@ func (d *lxc) deviceHandleMounts(mounts []deviceConfig.MountEntryItem) error {
 			if err != nil {
 				return fmt.Errorf("Failed to add mount for device inside container: %s", err)
 			}
+
+			// Undo the mount if any subsequent mount fails.
+			targetPath := mount.TargetPath
+			revert.Add(func() { _ = d.removeMount(targetPath) })
 		} else {
 			relativeTargetPath := strings.TrimPrefix(mount.TargetPath, "/")
 
@@ -1744,6 +1904,7 @@ func (d *lxc) deviceHandleMounts(mounts []deviceConfig.MountEntryItem) error {
 		}
 	}
 
+	revert.Success()
 	return nil
 }
 
@@ -1913,7 +2074,12 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 	revert := revert.New()
 	defer revert.Fail()
 
-	// Assign a NUMA node if needed.
+	// Check that the NUMA nodes still exist, otherwise assign one if needed.
+	err := instance.ValidHostNUMANodes(d.expandedConfig)
+	if err != nil {
+		return "", nil, err
+	}
+
 	if d.expandedConfig["limits.cpu.nodes"] == "balanced" {
 		err := d.setNUMANode()
 		if err != nil {
@@ -1928,8 +2094,23 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 			return "", nil, err
 		}
 
-		// Check if we need to change idmap.
-		if nextMap != nil && d.state.OS.IdmapSet != nil && !d.state.OS.IdmapSet.Includes(nextMap) {
+		// Size the map from the root filesystem on first start. The size is recorded even when falling back
+		// to the default size so that the map isn't recomputed on every start.
+		firstStartAuto := d.expandedConfig["security.idmap.size"] == "auto" && d.localConfig["volatile.idmap.size"] == ""
+		if firstStartAuto {
+			size, err := d.idmapSizeFromRootfs()
+			if err != nil {
+				d.logger.Warn("Failed computing idmap size from root filesystem, using default size", logger.Ctx{"err": err})
+			}
+
+			err = d.VolatileSet(map[string]string{"volatile.idmap.size": strconv.FormatInt(size, 10)})
+			if err != nil {
+				return "", nil, fmt.Errorf("Failed to update volatile idmap size: %w", err)
+			}
+		}
+
+		// Check if we need to change idmap, either because the host map changed or the map was sized.
+		if firstStartAuto || (nextMap != nil && d.state.OS.IdmapSet != nil && !d.state.OS.IdmapSet.Includes(nextMap)) {
 			// Update the idmap.
 			idmapSet, base, err := d.findIdmap()
 			if err != nil {
@@ -2199,12 +2380,7 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 					continue
 				}
 
-				if d.state.OS.CGInfo.Layout == cgroup.CgroupsUnified {
-					err = lxcSetConfigItem(cc, fmt.Sprintf("lxc.cgroup2.%s", rule.Key), rule.Value)
-				} else {
-					err = lxcSetConfigItem(cc, fmt.Sprintf("lxc.cgroup.%s", rule.Key), rule.Value)
-				}
-
+				err = lxcSetConfigItem(cc, lxcCgroupConfigKey(d.state.OS.CGInfo.Layout, rule.Key), rule.Value)
 				if err != nil {
 					return "", nil, fmt.Errorf("Failed to setup device cgroup %q: %w", dev.Name(), err)
 				}
@@ -2307,15 +2483,23 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 		}
 
 		// Configure the entry point.
-		if len(config.Process.Args) > 0 && slices.Contains([]string{"/init", "/sbin/init", "/s6-init"}, config.Process.Args[0]) {
+		entrypoint := config.Process.Args
+		if d.expandedConfig["oci.command"] != "" {
+			entrypoint, err = internalInstance.ParseCommand(d.expandedConfig["oci.command"])
+			if err != nil {
+				return "", nil, fmt.Errorf("Invalid oci.command: %w", err)
+			}
+		}
+
+		if len(entrypoint) > 0 && slices.Contains([]string{"/init", "/sbin/init", "/s6-init"}, entrypoint[0]) {
 			// For regular init systems, call them directly as PID1.
-			err = lxcSetConfigItem(cc, "lxc.init.cmd", shellquote.Join(config.Process.Args...))
+			err = lxcSetConfigItem(cc, "lxc.init.cmd", shellquote.Join(entrypoint...))
 			if err != nil {
 				return "", nil, err
 			}
 		} else {
 			// For anything else, run them under our own PID1.
-			err = lxcSetConfigItem(cc, "lxc.execute.cmd", shellquote.Join(config.Process.Args...))
+			err = lxcSetConfigItem(cc, "lxc.execute.cmd", shellquote.Join(entrypoint...))
 			if err != nil {
 				return "", nil, err
 			}
@@ -2437,6 +2621,10 @@ ff02::2 ip6-allrouters
 			return "", nil, err
 		}
 	} else {
+		if d.expandedConfig["oci.command"] != "" {
+			return "", nil, fmt.Errorf("The oci.command option is only supported for application containers")
+		}
+
 		// Clear OCI config key if present.
 		if d.expandedConfig["volatile.container.oci"] != "" {
 			volatileSet["volatile.container.oci"] = ""
@@ -2552,7 +2740,7 @@ ff02::2 ip6-allrouters
 	}
 
 	if snapName != "" && expiry != nil {
-		err := d.snapshot(snapName, *expiry, false)
+		err := d.snapshot(snapName, *expiry, false, true)
 		if err != nil {
 			return "", nil, fmt.Errorf("Failed taking startup snapshot: %w", err)
 		}
@@ -3200,8 +3388,9 @@ func (d *lxc) onStop(args map[string]string) error {
 
 	// Record power state.
 	err = d.VolatileSet(map[string]string{
-		"volatile.last_state.power": instance.PowerStateStopped,
-		"volatile.last_state.ready": "false",
+		"volatile.last_state.power":  instance.PowerStateStopped,
+		"volatile.last_state.ready":  "false",
+		"volatile.cpu.limit.clamped": "",
 	})
 	if err != nil {
 		// Don't return an error here as we still want to cleanup the instance even if DB not available.
@@ -3690,7 +3879,7 @@ func (d *lxc) RenderState(hostInterfaces []net.Interface) (*api.InstanceState, e
 }
 
 // snapshot creates a snapshot of the instance.
-func (d *lxc) snapshot(name string, expiry time.Time, stateful bool) error {
+func (d *lxc) snapshot(name string, expiry time.Time, stateful bool, scheduled bool) error {
 
 	// Check that migration.stateful is set for stateful actions.
 	if stateful && util.IsFalseOrEmpty(d.expandedConfig["migration.stateful"]) {
@@ -3773,12 +3962,12 @@ func (d *lxc) snapshot(name string, expiry time.Time, stateful bool) error {
 	// Wait for any file operations to complete to have a more consistent snapshot.
 	d.stopForkfile(false)
 
-	return d.snapshotCommon(d, name, expiry, stateful)
+	return d.snapshotCommon(d, name, expiry, stateful, scheduled)
 }
 
 // Snapshot takes a new snapshot.
-func (d *lxc) Snapshot(name string, expiry time.Time, stateful bool) error {
-	return d.snapshot(name, expiry, stateful)
+func (d *lxc) Snapshot(name string, expiry time.Time, stateful bool, scheduled bool) error {
+	return d.snapshot(name, expiry, stateful, scheduled)
 }
 
 // Restore restores a snapshot.
@@ -4607,6 +4796,20 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 			return fmt.Errorf("Invalid expanded config: %w", err)
 		}
 
+		advisory := internalInstance.MemoryConfigAdvisory(d.expandedConfig)
+		if advisory != "" {
+			d.logger.Warn(advisory)
+		}
+
+		if slices.Contains(changedConfig, "limits.memory.swap") || slices.Contains(changedConfig, "limits.memory.swap.priority") || slices.Contains(changedConfig, "limits.cpu.nodes") {
+			err = d.validateHostConfig(userRequested)
+			if err != nil {
+				return fmt.Errorf("Invalid expanded config: %w", err)
+			}
+
+			d.logSwapAdvisory()
+		}
+
 		// Do full expanded validation of the devices diff.
 		err = instance.ValidDevices(d.state, d.project, d.Type(), d.localDevices, d.expandedDevices)
 		if err != nil {
@@ -4663,6 +4866,11 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 		}
 	}
 
+	// Size the map again from the root filesystem on next start.
+	if slices.Contains(changedConfig, "security.idmap.size") {
+		delete(d.localConfig, "volatile.idmap.size")
+	}
+
 	if slices.Contains(changedConfig, "security.idmap.isolated") || slices.Contains(changedConfig, "security.idmap.base") || slices.Contains(changedConfig, "security.idmap.size") || slices.Contains(changedConfig, "raw.idmap") || slices.Contains(changedConfig, "security.privileged") {
 		var idmapSet *idmap.Set
 		base := int64(0)
@@ -4753,26 +4961,22 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 					}
 				}
 			} else if key == "limits.disk.priority" {
-				if !d.state.OS.CGInfo.Supports(cgroup.Blkio, cg) {
+				if !d.state.OS.CGInfo.Supports(cgroup.BlkioWeight, cg) {
 					continue
 				}
 
-				priorityInt := 5
+				// Go back to the default priority when unset.
 				diskPriority := d.expandedConfig["limits.disk.priority"]
-				if diskPriority != "" {
-					priorityInt, err = strconv.Atoi(diskPriority)
-					if err != nil {
-						return err
-					}
+				if diskPriority == "" {
+					diskPriority = "5"
 				}
 
-				// Minimum valid value is 10
-				priority := int64(priorityInt * 100)
-				if priority == 0 {
-					priority = 10
+				weight, err := cgroup.ParseDiskPriority(diskPriority)
+				if err != nil {
+					return err
 				}
 
-				err = cg.SetBlkioWeight(priority)
+				err = cg.SetBlkioWeight(weight)
 				if err != nil {
 					return err
 				}
@@ -4860,10 +5064,18 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 						return err
 					}
 				} else {
+					// Lowering the limit makes the kernel reclaim memory, which fails on cgroup1 if the
+					// usage can't be brought below the new limit.
 					err = cg.SetMemoryLimit(memoryInt)
 					if err != nil {
 						revertMemory()
-						return err
+
+						usage, usageErr := cg.GetMemoryUsage()
+						if usageErr == nil && memoryInt >= 0 && usage > memoryInt {
+							return fmt.Errorf("Failed setting memory limit to %q as the current memory usage of %s couldn't be reclaimed below it: %w", memory, units.GetByteSizeStringIEC(usage, 2), err)
+						}
+
+						return fmt.Errorf("Failed setting memory limit to %q: %w", memory, err)
 					}
 
 					if d.state.OS.CGInfo.Supports(cgroup.MemorySwap, cg) {
@@ -4927,10 +5139,10 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 						}
 					}
 				}
-			} else if key == "limits.cpu" || key == "limits.cpu.nodes" {
+			} else if key == "limits.cpu" || key == "limits.cpu.nodes" || key == "limits.cpu.policy" || key == "limits.cpu.isolated" {
 				// Trigger a scheduler re-run
 				cgroup.TaskSchedulerTrigger("container", d.name, "changed")
-			} else if key == "limits.cpu.priority" || key == "limits.cpu.allowance" {
+			} else if key == "limits.cpu.priority" || key == "limits.cpu.allowance" || key == "limits.cpu.weight" {
 				// Skip if no cpu CGroup
 				if !d.state.OS.CGInfo.Supports(cgroup.CPU, cg) {
 					continue
@@ -4942,6 +5154,13 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 					return err
 				}
 
+				if d.expandedConfig["limits.cpu.weight"] != "" {
+					cpuShares, err = cgroup.ParseCPUWeight(d.expandedConfig["limits.cpu.weight"])
+					if err != nil {
+						return err
+					}
+				}
+
 				err = cg.SetCPUShare(cpuShares)
 				if err != nil {
 					return err
@@ -4967,6 +5186,11 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 						return err
 					}
 
+					valueInt, clamped := internalInstance.ClampProcessesLimit(valueInt)
+					if clamped {
+						d.logger.Warn("Capping processes limit to the kernel maximum", logger.Ctx{"requested": value, "limit": valueInt})
+					}
+
 					err = cg.SetMaxProcesses(valueInt)
 					if err != nil {
 						return err
@@ -6839,6 +7063,39 @@ func (d *lxc) templateApplyNow(trigger instance.TemplateTrigger) error {
 		containerMeta["privileged"] = "false"
 	}
 
+	networkConfig, ok := d.expandedConfig["cloud-init.network-config"]
+	if !ok {
+		networkConfig = d.expandedConfig["user.network-config"]
+	}
+
+	if networkConfig != "" {
+		err = internalInstance.ValidateNetworkConfig(networkConfig)
+		if err != nil {
+			d.logger.Warn("The cloud-init network configuration may be ignored by the instance", logger.Ctx{"err": err})
+		}
+	}
+
+	// Expose the cloud-init data with the SSH keys from cloud-init.ssh-keys merged in.
+	templateConfig := d.expandedConfig
+	if d.expandedConfig["cloud-init.ssh-keys"] != "" {
+		userData, vendorData, err := internalInstance.CloudInitConfig(d.expandedConfig)
+		if err != nil {
+			return err
+		}
+
+		// Only update the data that changed, using whichever key the user set.
+		templateConfig = maps.Clone(d.expandedConfig)
+		userDataKey := internalInstance.CloudInitConfigKey(d.expandedConfig, "user-data")
+		if userData != d.expandedConfig[userDataKey] {
+			templateConfig[userDataKey] = userData
+		}
+
+		vendorDataKey := internalInstance.CloudInitConfigKey(d.expandedConfig, "vendor-data")
+		if vendorData != d.expandedConfig[vendorDataKey] {
+			templateConfig[vendorDataKey] = vendorData
+		}
+	}
+
 	// Go through the templates
 	for tplPath, tpl := range metadata.Templates {
 		err = func(tplPath string, tpl *api.ImageMetadataTemplate) error {
@@ -6951,7 +7208,7 @@ func (d *lxc) templateApplyNow(trigger instance.TemplateTrigger) error {
 			}
 
 			configGet := func(confKey, confDefault *pongo2.Value) *pongo2.Value {
-				val, ok := d.expandedConfig[confKey.String()]
+				val, ok := templateConfig[confKey.String()]
 				if !ok {
 					return confDefault
 				}
@@ -6964,7 +7221,7 @@ func (d *lxc) templateApplyNow(trigger instance.TemplateTrigger) error {
 				"path":       tplPath,
 				"container":  containerMeta,
 				"instance":   containerMeta,
-				"config":     d.expandedConfig,
+				"config":     templateConfig,
 				"devices":    d.expandedDevices,
 				"properties": tpl.Properties,
 				"config_get": configGet}, w)
@@ -7976,6 +8233,42 @@ func (d *lxc) insertMount(source, target, fstype string, flags int, idmapType id
 	return d.insertMountGo(source, target, fstype, flags, -1, idmapType)
 }
 
+// remountMount changes the read-only state of an existing mount inside the container.
+func (d *lxc) remountMount(mount string, readonly bool) error {
+	// Get the init PID
+	pid := d.InitPID()
+	if pid == -1 {
+		// Container isn't running
+		return fmt.Errorf("Can't remount in stopped container")
+	}
+
+	if !strings.HasPrefix(mount, "/") {
+		mount = "/" + mount
+	}
+
+	pidFdNr, pidFd := d.inheritInitPidFd()
+	if pidFdNr >= 0 {
+		defer func() { _ = pidFd.Close() }()
+	}
+
+	_, err := subprocess.RunCommandInheritFds(
+		context.TODO(),
+		[]*os.File{pidFd},
+		d.state.OS.ExecPath,
+		"forkmount",
+		"go-remount",
+		"--",
+		fmt.Sprintf("%d", pid),
+		fmt.Sprintf("%d", pidFdNr),
+		mount,
+		fmt.Sprintf("%t", readonly))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (d *lxc) removeMount(mount string) error {
 	// Get the init PID
 	pid := d.InitPID()
@@ -8192,15 +8485,25 @@ func (d *lxc) FillNetworkDevice(name string, m deviceConfig.Device) (deviceConfi
 		return nil, err
 	}
 
+	// Disabled NICs don't have an interface to fill in.
+	if nicType == "none" {
+		return newDevice, nil
+	}
+
 	// Fill in the MAC address.
-	if !slices.Contains([]string{"physical", "ipvlan"}, nicType) && m["hwaddr"] == "" {
+	if instance.DeviceNeedsGeneratedHWAddr(nicType, m) {
 		configKey := fmt.Sprintf("volatile.%s.hwaddr", name)
 		volatileHwaddr := d.localConfig[configKey]
 		if volatileHwaddr == "" {
-			// Generate a new MAC address.
-			volatileHwaddr, err = instance.DeviceNextInterfaceHWAddr()
-			if err != nil || volatileHwaddr == "" {
-				return nil, fmt.Errorf("Failed generating %q: %w", configKey, err)
+			if util.IsTrue(m["hwaddr.stable"]) && d.localConfig["volatile.uuid"] != "" {
+				// Derive the MAC address from the instance UUID so that it can be reproduced.
+				volatileHwaddr = instance.DeviceStableInterfaceHWAddr(d.localConfig["volatile.uuid"], name, m["hwaddr.oui"])
+			} else {
+				// Generate a new MAC address.
+				volatileHwaddr, err = instance.DeviceNextInterfaceHWAddr(m["hwaddr.oui"])
+				if err != nil || volatileHwaddr == "" {
+					return nil, fmt.Errorf("Failed generating %q: %w", configKey, err)
+				}
 			}
 
 			// Update the database and update volatileHwaddr with stored value.
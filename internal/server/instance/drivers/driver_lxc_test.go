@@ -0,0 +1,69 @@
+package drivers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/v6/internal/server/cgroup"
+)
+
+func TestLxcCgroupConfigKey(t *testing.T) {
+	assert.Equal(t, "lxc.cgroup2.devices.allow", lxcCgroupConfigKey(cgroup.CgroupsUnified, "devices.allow"))
+	assert.Equal(t, "lxc.cgroup.devices.allow", lxcCgroupConfigKey(cgroup.CgroupsHybrid, "devices.allow"))
+	assert.Equal(t, "lxc.cgroup.devices.deny", lxcCgroupConfigKey(cgroup.CgroupsLegacy, "devices.deny"))
+}
+
+func TestLxcHighestID(t *testing.T) {
+	dir := t.TempDir()
+
+	passwdPath := filepath.Join(dir, "passwd")
+	err := os.WriteFile(passwdPath, []byte("root:x:0:0:root:/root:/bin/bash\nnobody:x:65534:65534::/:/bin/false\nsvc:x:100000:100000::/:/bin/false\nbad:x:invalid:0::/:/bin/false\noverflow:x:4294967294:0::/:/bin/false\n"), 0644)
+	assert.NoError(t, err)
+
+	id, err := lxcHighestID(passwdPath)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100000), id)
+
+	id, err = lxcHighestID(filepath.Join(dir, "group"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), id)
+
+	// Symlinks aren't followed.
+	err = os.Symlink(passwdPath, filepath.Join(dir, "link"))
+	assert.NoError(t, err)
+
+	id, err = lxcHighestID(filepath.Join(dir, "link"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), id)
+}
+
+func TestParseMemoryStrWithTotal(t *testing.T) {
+	tests := []struct {
+		memory  string
+		want    int64
+		wantErr bool
+	}{
+		{memory: "1GiB", want: 1024 * 1024 * 1024},
+		{memory: "512MB", want: 512 * 1000 * 1000},
+		{memory: "50%", want: 2 * 1024 * 1024 * 1024},
+		{memory: "100%", want: 4 * 1024 * 1024 * 1024},
+		{memory: "half%", wantErr: true},
+		{memory: "lots", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.memory, func(t *testing.T) {
+			value, err := parseMemoryStrWithTotal(tt.memory, 4*1024*1024*1024)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, value)
+		})
+	}
+}
@@ -259,6 +259,9 @@ func qemuCreate(s *state.State, args db.InstanceArgs, p api.Project, op *operati
 		return nil, nil, fmt.Errorf("Invalid config: %w", err)
 	}
 
+	// The config may come from another host (copy, migration, backup), so only log host related issues.
+	_ = d.validateHostConfig(false)
+
 	err = instance.ValidDevices(s, d.project, d.Type(), d.localDevices, d.expandedDevices)
 	if err != nil {
 		return nil, nil, fmt.Errorf("Invalid devices: %w", err)
@@ -1190,7 +1193,13 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 
 	defer op.Done(err)
 
-	// Assign a NUMA node if needed.
+	// Check that the NUMA nodes still exist, otherwise assign one if needed.
+	err = instance.ValidHostNUMANodes(d.expandedConfig)
+	if err != nil {
+		op.Done(err)
+		return err
+	}
+
 	if d.expandedConfig["limits.cpu.nodes"] == "balanced" {
 		err := d.setNUMANode()
 		if err != nil {
@@ -1199,6 +1208,27 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		}
 	}
 
+	// Check that the memory can be backed by huge pages.
+	if util.IsTrue(d.expandedConfig["limits.memory.hugepages"]) {
+		hostMemory, err := resources.GetMemory()
+		if err != nil {
+			op.Done(err)
+			return fmt.Errorf("Failed getting host memory: %w", err)
+		}
+
+		numaNodes, err := d.memoryNUMANodes()
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+
+		err = internalInstance.ValidateVMHugepages(d.expandedConfig, *hostMemory, numaNodes)
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+	}
+
 	// Ensure the correct vhost_vsock kernel module is loaded before establishing the vsock.
 	err = linux.LoadModule("vhost_vsock")
 	if err != nil {
@@ -1210,7 +1240,7 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 	defer revert.Fail()
 
 	// Rotate the log files.
-	for _, logfile := range []string{d.LogFilePath(), d.common.ConsoleBufferLogPath(), d.QMPLogFilePath()} {
+	for _, logfile := range []string{d.LogFilePath(), d.common.ConsoleBufferLogPath(), d.QMPLogFilePath(), d.edk2LogFilePath()} {
 		if util.PathExists(logfile) {
 			_ = os.Remove(logfile + ".old")
 			err := os.Rename(logfile, logfile+".old")
@@ -1454,7 +1484,7 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 	}
 
 	if snapName != "" && expiry != nil {
-		err := d.snapshot(snapName, *expiry, false)
+		err := d.snapshot(snapName, *expiry, false, true)
 		if err != nil {
 			err = fmt.Errorf("Failed taking startup snapshot: %w", err)
 			op.Done(err)
@@ -1839,6 +1869,16 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		}
 	}
 
+	// Apply emulator thread pinning.
+	if d.expandedConfig["limits.cpu.emulator"] != "" {
+		err = d.setEmulatorAffinity(monitor)
+		if err != nil {
+			err = fmt.Errorf("Failed pinning emulator threads: %w", err)
+			op.Done(err)
+			return err
+		}
+	}
+
 	// Run monitor hooks from devices.
 	for _, monHook := range monHooks {
 		err = monHook(monitor)
@@ -2680,8 +2720,8 @@ func (d *qemu) deviceStop(dev device.Device, instanceRunning bool, _ string) err
 	}
 
 	if instanceRunning {
-		// Detach NIC from running instance.
-		if configCopy["type"] == "nic" {
+		// Detach NIC from running instance (disabled NICs have no run config as nothing was attached).
+		if configCopy["type"] == "nic" && runConf != nil {
 			for _, usbDev := range runConf.USBDevice {
 				err = d.deviceDetachUSB(usbDev)
 				if err != nil {
@@ -3093,8 +3133,8 @@ func (d *qemu) generateConfigShare() error {
 	if util.IsTrue(d.expandedConfig["agent.nic_config"]) {
 		sortedDevices := d.expandedDevices.Sorted()
 		for _, entry := range sortedDevices {
-			if entry.Config["type"] != "nic" {
-				continue // Only keep NIC devices.
+			if entry.Config["type"] != "nic" || entry.Config["nictype"] == "none" {
+				continue // Only keep enabled NIC devices.
 			}
 
 			dev, err := d.FillNetworkDevice(entry.Name, entry.Config)
@@ -3260,7 +3300,7 @@ func (d *qemu) deviceBootPriorities(base int) (map[string]int, error) {
 
 		bootPrio := uint32(0) // Default to lowest priority.
 		if dev.Config["boot.priority"] != "" {
-			prio, err := strconv.ParseInt(dev.Config["boot.priority"], 10, 32)
+			prio, err := strconv.ParseUint(dev.Config["boot.priority"], 10, 32)
 			if err != nil {
 				return nil, fmt.Errorf("Invalid boot.priority for device %q: %w", dev.Name, err)
 			}
@@ -3342,6 +3382,24 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 			return "", nil, fmt.Errorf("Unable to locate matching firmware: %+v", firmwares)
 		}
 
+		// Use the debug build of the firmware and capture its output if requested.
+		if util.IsTrue(d.expandedConfig["boot.debug.edk2"]) {
+			if d.architecture != osarch.ARCH_64BIT_INTEL_X86 {
+				return "", nil, fmt.Errorf("Firmware debugging is only supported on x86_64")
+			}
+
+			if util.IsTrue(d.expandedConfig["security.csm"]) {
+				return "", nil, fmt.Errorf("Firmware debugging can't be used with security.csm")
+			}
+
+			efiCode = filepath.Join(filepath.Dir(efiCode), edk2.OVMFDebugFirmware)
+			if !util.PathExists(efiCode) {
+				return "", nil, fmt.Errorf("Debug firmware %q not found", efiCode)
+			}
+
+			cfg = append(cfg, qemuDebugcon(d.edk2LogFilePath())...)
+		}
+
 		driveFirmwareOpts := qemuDriveFirmwareOpts{
 			roPath:    efiCode,
 			nvramPath: fmt.Sprintf("/dev/fd/%d", d.addFileDescriptor(fdFiles, nvRAMFile)),
@@ -3754,20 +3812,12 @@ func (d *qemu) addCPUMemoryConfig(cfg *[]cfgSection, cpuInfo *cpuTopology) error
 		hostNodes = []uint64{0}
 
 		// Handle NUMA restrictions.
-		numaNodes := d.expandedConfig["limits.cpu.nodes"]
-		if numaNodes != "" {
-			if numaNodes == "balanced" {
-				numaNodes = d.expandedConfig["volatile.cpu.nodes"]
-			}
-
-			// Parse the NUMA restriction.
-			numaNodeSet, err := resources.ParseNumaNodeSet(numaNodes)
-			if err != nil {
-				return err
-			}
-
-			cpuOpts.memoryHostNodes = numaNodeSet
+		memoryHostNodes, err := d.memoryNUMANodes()
+		if err != nil {
+			return err
 		}
+
+		cpuOpts.memoryHostNodes = memoryHostNodes
 	} else {
 		cpuPinning = true
 
@@ -3991,6 +4041,29 @@ func (d *qemu) addDriveDirConfig(cfg *[]cfgSection, bus *qemuBus, fdFiles *[]*os
 	return nil
 }
 
+// qemuDriveAIOMode returns the asynchronous I/O mode of a drive, applying any "aio=" override from its options.
+// The native mode requires direct I/O and the io_uring mode requires support from both QEMU and the kernel.
+func qemuDriveAIOMode(opts []string, aioMode string, directCache bool, ioUring bool) (string, error) {
+	for _, opt := range opts {
+		mode, ok := strings.CutPrefix(opt, "aio=")
+		if !ok {
+			continue
+		}
+
+		if mode == "native" && !directCache {
+			return "", fmt.Errorf("The native I/O mode requires the device to bypass the host cache (io.cache=none)")
+		}
+
+		if mode == "io_uring" && !ioUring {
+			return "", fmt.Errorf("The io_uring I/O mode isn't supported on this host")
+		}
+
+		return mode, nil
+	}
+
+	return aioMode, nil
+}
+
 // addDriveConfig adds the qemu config required for adding a supplementary drive.
 func (d *qemu) addDriveConfig(qemuDev map[string]any, bootIndexes map[string]int, driveConf deviceConfig.MountEntryItem) (monitorHook, error) {
 	aioMode := "native" // Use native kernel async IO and O_DIRECT by default.
@@ -4004,7 +4077,8 @@ func (d *qemu) addDriveConfig(qemuDev map[string]any, bootIndexes map[string]int
 	info := DriverStatuses()[instancetype.VM].Info
 	minVer, _ := version.NewDottedVersion("5.13.0")
 	_, ioUring := info.Features["io_uring"]
-	if slices.Contains(driveConf.Opts, device.DiskIOUring) && ioUring && d.state.OS.KernelVersion.Compare(minVer) >= 0 {
+	ioUring = ioUring && d.state.OS.KernelVersion.Compare(minVer) >= 0
+	if slices.Contains(driveConf.Opts, device.DiskIOUring) && ioUring {
 		aioMode = "io_uring"
 	}
 
@@ -4125,6 +4199,16 @@ func (d *qemu) addDriveConfig(qemuDev map[string]any, bootIndexes map[string]int
 		directCache = false
 	}
 
+	// Check if the user has overridden the I/O mode.
+	// This is skipped for RBD images as the rbd driver doesn't support the aio option.
+	if !isRBDImage {
+		var err error
+		aioMode, err = qemuDriveAIOMode(driveConf.Opts, aioMode, directCache, ioUring)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid I/O mode for device %q: %w", driveConf.DevName, err)
+		}
+	}
+
 	escapedDeviceName := linux.PathNameEncode(driveConf.DevName)
 
 	blockDev := map[string]any{
@@ -5119,7 +5203,7 @@ func (d *qemu) IsPrivileged() bool {
 }
 
 // snapshot creates a snapshot of the instance.
-func (d *qemu) snapshot(name string, expiry time.Time, stateful bool) error {
+func (d *qemu) snapshot(name string, expiry time.Time, stateful bool, scheduled bool) error {
 	var err error
 	var monitor *qmp.Monitor
 
@@ -5155,7 +5239,7 @@ func (d *qemu) snapshot(name string, expiry time.Time, stateful bool) error {
 	}
 
 	// Create the snapshot.
-	err = d.snapshotCommon(d, name, expiry, stateful)
+	err = d.snapshotCommon(d, name, expiry, stateful, scheduled)
 	if err != nil {
 		return err
 	}
@@ -5178,8 +5262,8 @@ func (d *qemu) snapshot(name string, expiry time.Time, stateful bool) error {
 }
 
 // Snapshot takes a new snapshot.
-func (d *qemu) Snapshot(name string, expiry time.Time, stateful bool) error {
-	return d.snapshot(name, expiry, stateful)
+func (d *qemu) Snapshot(name string, expiry time.Time, stateful bool, scheduled bool) error {
+	return d.snapshot(name, expiry, stateful, scheduled)
 }
 
 // Restore restores an instance snapshot.
@@ -5711,6 +5795,13 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 			return fmt.Errorf("Invalid expanded config: %w", err)
 		}
 
+		if slices.Contains(changedConfig, "limits.cpu.nodes") {
+			err = d.validateHostConfig(true)
+			if err != nil {
+				return fmt.Errorf("Invalid expanded config: %w", err)
+			}
+		}
+
 		// Do full expanded validation of the devices diff.
 		err = instance.ValidDevices(d.state, d.project, d.Type(), d.localDevices, d.expandedDevices)
 		if err != nil {
@@ -5759,6 +5850,7 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 			"security.agent.metrics",
 			"security.csm",
 			"security.protection.delete",
+			"security.protection.start",
 			"security.guestapi",
 			"security.secureboot",
 		}
@@ -5798,6 +5890,10 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 		// Check only keys that support live update have changed.
 		for _, key := range changedConfig {
 			if !isLiveUpdatable(key) {
+				if key == "limits.cpu" {
+					return fmt.Errorf("Key %q cannot be updated when VM is running as CPU hotplug isn't supported on this architecture, restart the VM to apply it", key)
+				}
+
 				return fmt.Errorf("Key %q cannot be updated when VM is running", key)
 			}
 		}
@@ -8440,6 +8536,11 @@ func (d *qemu) QMPLogFilePath() string {
 	return filepath.Join(d.LogPath(), "qemu.qmp.log")
 }
 
+// edk2LogFilePath returns the firmware debug log path, used when boot.debug.edk2 is enabled.
+func (d *qemu) edk2LogFilePath() string {
+	return filepath.Join(d.LogPath(), "edk2.log")
+}
+
 // FillNetworkDevice takes a nic or infiniband device type and enriches it with automatically
 // generated name and hwaddr properties if these are missing from the device.
 func (d *qemu) FillNetworkDevice(name string, m deviceConfig.Device) (deviceConfig.Device, error) {
@@ -8452,15 +8553,25 @@ func (d *qemu) FillNetworkDevice(name string, m deviceConfig.Device) (deviceConf
 		return nil, err
 	}
 
+	// Disabled NICs don't have an interface to fill in.
+	if nicType == "none" {
+		return newDevice, nil
+	}
+
 	// Fill in the MAC address.
-	if !slices.Contains([]string{"physical", "ipvlan"}, nicType) && m["hwaddr"] == "" {
+	if instance.DeviceNeedsGeneratedHWAddr(nicType, m) {
 		configKey := fmt.Sprintf("volatile.%s.hwaddr", name)
 		volatileHwaddr := d.localConfig[configKey]
 		if volatileHwaddr == "" {
-			// Generate a new MAC address.
-			volatileHwaddr, err = instance.DeviceNextInterfaceHWAddr()
-			if err != nil || volatileHwaddr == "" {
-				return nil, fmt.Errorf("Failed generating %q: %w", configKey, err)
+			if util.IsTrue(m["hwaddr.stable"]) && d.localConfig["volatile.uuid"] != "" {
+				// Derive the MAC address from the instance UUID so that it can be reproduced.
+				volatileHwaddr = instance.DeviceStableInterfaceHWAddr(d.localConfig["volatile.uuid"], name, m["hwaddr.oui"])
+			} else {
+				// Generate a new MAC address.
+				volatileHwaddr, err = instance.DeviceNextInterfaceHWAddr(m["hwaddr.oui"])
+				if err != nil || volatileHwaddr == "" {
+					return nil, fmt.Errorf("Failed generating %q: %w", configKey, err)
+				}
 			}
 
 			// Update the database and update volatileHwaddr with stored value.
@@ -9126,6 +9237,31 @@ func (d *qemu) blockNodeName(name string) string {
 	return fmt.Sprintf("%s%s", qemuBlockDevIDPrefix, name)
 }
 
+// qemuCPUHotplugDelta returns the number of vCPUs to hotplug (when positive) or unplug (when negative) to reach
+// count vCPUs. It takes the total number of possible vCPUs along with the number of free and hotplugged ones,
+// the boot vCPU being the only one that isn't hotplugged.
+func qemuCPUHotplugDelta(count int, totalCPUs int, availableCPUs int, hotpluggedCPUs int) (int, error) {
+	// The reserved CPUs includes both the hotplugged CPUs as well as the fixed one.
+	totalReservedCPUs := hotpluggedCPUs + 1
+	delta := count - totalReservedCPUs
+
+	// Cannot allocate more CPUs than the system provides.
+	if count > totalCPUs {
+		return 0, fmt.Errorf("Cannot allocate more than %d CPUs without restarting the instance", totalCPUs)
+	}
+
+	// These shouldn't trigger, but if they do, don't panic.
+	if delta > availableCPUs {
+		return 0, fmt.Errorf("Unable to allocate more CPUs, not enough hotpluggable CPUs available")
+	}
+
+	if -delta > hotpluggedCPUs {
+		return 0, fmt.Errorf("Unable to remove CPUs, not enough hotpluggable CPUs available")
+	}
+
+	return delta, nil
+}
+
 func (d *qemu) setCPUs(monitor *qmp.Monitor, count int) error {
 	if count == 0 {
 		return nil
@@ -9159,11 +9295,13 @@ func (d *qemu) setCPUs(monitor *qmp.Monitor, count int) error {
 		}
 	}
 
-	// The reserved CPUs includes both the hotplugged CPUs as well as the fixed one.
-	totalReservedCPUs := len(hotpluggedCPUs) + 1
+	delta, err := qemuCPUHotplugDelta(count, len(cpus), len(availableCPUs), len(hotpluggedCPUs))
+	if err != nil {
+		return err
+	}
 
 	// Nothing to do as the count matches the already reserved CPUs.
-	if count == totalReservedCPUs {
+	if delta == 0 {
 		return nil
 	}
 
@@ -9171,19 +9309,9 @@ func (d *qemu) setCPUs(monitor *qmp.Monitor, count int) error {
 	defer revert.Fail()
 
 	// More CPUs requested.
-	if count > totalReservedCPUs {
-		// Cannot allocate more CPUs than the system provides.
-		if count > len(cpus) {
-			return fmt.Errorf("Cannot allocate more CPUs than available")
-		}
-
-		// This shouldn't trigger, but if it does, don't panic.
-		if count-totalReservedCPUs > len(availableCPUs) {
-			return fmt.Errorf("Unable to allocate more CPUs, not enough hotpluggable CPUs available")
-		}
-
+	if delta > 0 {
 		// Only allocate the difference in CPUs.
-		for i := 0; i < count-totalReservedCPUs; i++ {
+		for i := 0; i < delta; i++ {
 			cpu := availableCPUs[i]
 
 			devID := fmt.Sprintf("cpu%d%d%d", cpu.Props.SocketID, cpu.Props.CoreID, cpu.Props.ThreadID)
@@ -9211,13 +9339,8 @@ func (d *qemu) setCPUs(monitor *qmp.Monitor, count int) error {
 			})
 		}
 	} else {
-		if totalReservedCPUs-count > len(hotpluggedCPUs) {
-			// This shouldn't trigger, but if it does, don't panic.
-			return fmt.Errorf("Unable to remove CPUs, not enough hotpluggable CPUs available")
-		}
-
 		// Less CPUs requested.
-		for i := 0; i < totalReservedCPUs-count; i++ {
+		for i := 0; i < -delta; i++ {
 			cpu := hotpluggedCPUs[i]
 
 			fields := strings.Split(cpu.QOMPath, "/")
@@ -9256,6 +9379,61 @@ func (d *qemu) setCPUs(monitor *qmp.Monitor, count int) error {
 	return nil
 }
 
+// memoryNUMANodes returns the host NUMA nodes the memory of the instance is restricted to through limits.cpu.nodes.
+// Returns nil when the memory isn't restricted.
+func (d *qemu) memoryNUMANodes() ([]int64, error) {
+	numaNodes := d.expandedConfig["limits.cpu.nodes"]
+	if numaNodes == "balanced" {
+		numaNodes = d.expandedConfig["volatile.cpu.nodes"]
+	}
+
+	if numaNodes == "" {
+		return nil, nil
+	}
+
+	return resources.ParseNumaNodeSet(numaNodes)
+}
+
+// setEmulatorAffinity pins all the QEMU threads other than the vCPU threads to the CPUs from limits.cpu.emulator.
+// Threads created later on by QEMU inherit the affinity of the thread creating them.
+func (d *qemu) setEmulatorAffinity(monitor *qmp.Monitor) error {
+	cpus, err := resources.ParseCpuset(d.expandedConfig["limits.cpu.emulator"])
+	if err != nil {
+		return err
+	}
+
+	set := unix.CPUSet{}
+	for _, id := range cpus {
+		set.Set(int(id))
+	}
+
+	// Get the vCPU PID list.
+	vcpuPIDs, err := monitor.GetCPUs()
+	if err != nil {
+		return err
+	}
+
+	tasksPath := fmt.Sprintf("/proc/%d/task", d.InitPID())
+	entries, err := os.ReadDir(tasksPath)
+	if err != nil {
+		return fmt.Errorf("Failed listing QEMU threads: %w", err)
+	}
+
+	for _, entry := range entries {
+		tid, err := strconv.Atoi(entry.Name())
+		if err != nil || slices.Contains(vcpuPIDs, tid) {
+			continue
+		}
+
+		err = unix.SchedSetaffinity(tid, &set)
+		if err != nil && !errors.Is(err, unix.ESRCH) {
+			return fmt.Errorf("Failed pinning thread %d: %w", tid, err)
+		}
+	}
+
+	return nil
+}
+
 func (d *qemu) architectureSupportsCPUHotplug() bool {
 	// Check supported features.
 	info := DriverStatuses()[instancetype.VM].Info
@@ -9270,6 +9448,22 @@ func (d *qemu) postCPUHotplug(monitor *qmp.Monitor) error {
 		return err
 	}
 
+	// Hotplugged vCPU threads inherit the emulator thread pinning, so reset them to the CPUs usable by the server.
+	if d.expandedConfig["limits.cpu.emulator"] != "" {
+		set := unix.CPUSet{}
+		err = unix.SchedGetaffinity(0, &set)
+		if err != nil {
+			return err
+		}
+
+		for _, pid := range pids {
+			err := unix.SchedSetaffinity(pid, &set)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	// Handle NUMA node restrictions.
 	numaNodes := d.expandedConfig["limits.cpu.nodes"]
 	if numaNodes != "" {
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/shared/osarch"
 )
 
@@ -1581,3 +1582,24 @@ func TestQemuConfigTemplates(t *testing.T) {
 		}
 	})
 }
+
+func TestQemuDeviceBootPriorities(t *testing.T) {
+	d := &qemu{}
+	d.expandedDevices = deviceConfig.Devices{
+		"root":  {"type": "disk", "path": "/", "pool": "default"},
+		"data1": {"type": "disk", "source": "vol1", "pool": "default", "boot.priority": "5"},
+		"data2": {"type": "disk", "source": "vol2", "pool": "default", "boot.priority": "4294967295"},
+		"eth0":  {"type": "nic", "network": "incusbr0"},
+		"gpu0":  {"type": "gpu"},
+	}
+
+	bootIndexes, err := d.deviceBootPriorities(1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]int{"data2": 1, "data1": 2, "root": 3, "eth0": 4}
+	if !reflect.DeepEqual(bootIndexes, expected) {
+		t.Errorf("Unexpected boot indexes: %v (expected %v)", bootIndexes, expected)
+	}
+}
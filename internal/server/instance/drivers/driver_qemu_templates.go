@@ -645,6 +645,24 @@ func qemuDriveFirmware(opts *qemuDriveFirmwareOpts) []cfgSection {
 	}}
 }
 
+func qemuDebugcon(logPath string) []cfgSection {
+	return []cfgSection{{
+		name:    `chardev "edk2-debug"`,
+		comment: "Firmware debug log",
+		entries: []cfgEntry{
+			{key: "backend", value: "file"},
+			{key: "path", value: logPath},
+		},
+	}, {
+		name: `device "edk2-debug"`,
+		entries: []cfgEntry{
+			{key: "driver", value: "isa-debugcon"},
+			{key: "chardev", value: "edk2-debug"},
+			{key: "iobase", value: "0x402"},
+		},
+	}}
+}
+
 type qemuHostDriveOpts struct {
 	dev           qemuDevOpts
 	name          string
@@ -0,0 +1,73 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQemuCPUHotplugDelta(t *testing.T) {
+	tests := []struct {
+		name           string
+		count          int
+		totalCPUs      int
+		availableCPUs  int
+		hotpluggedCPUs int
+		want           int
+		wantErr        bool
+	}{
+		{name: "Unchanged", count: 4, totalCPUs: 8, availableCPUs: 4, hotpluggedCPUs: 3, want: 0},
+		{name: "Only boot CPU", count: 1, totalCPUs: 8, availableCPUs: 7, hotpluggedCPUs: 0, want: 0},
+		{name: "Grow", count: 6, totalCPUs: 8, availableCPUs: 4, hotpluggedCPUs: 3, want: 2},
+		{name: "Grow to maximum", count: 8, totalCPUs: 8, availableCPUs: 7, hotpluggedCPUs: 0, want: 7},
+		{name: "Shrink", count: 2, totalCPUs: 8, availableCPUs: 4, hotpluggedCPUs: 3, want: -2},
+		{name: "Shrink to boot CPU", count: 1, totalCPUs: 8, availableCPUs: 4, hotpluggedCPUs: 3, want: -3},
+		{name: "Over maximum", count: 9, totalCPUs: 8, availableCPUs: 7, hotpluggedCPUs: 0, wantErr: true},
+		{name: "Not enough available", count: 6, totalCPUs: 8, availableCPUs: 1, hotpluggedCPUs: 3, wantErr: true},
+		{name: "Below boot CPU", count: 0, totalCPUs: 8, availableCPUs: 4, hotpluggedCPUs: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delta, err := qemuCPUHotplugDelta(tt.count, tt.totalCPUs, tt.availableCPUs, tt.hotpluggedCPUs)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, delta)
+		})
+	}
+}
+
+func TestQemuDriveAIOMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []string
+		directCache bool
+		ioUring     bool
+		want        string
+		wantErr     bool
+	}{
+		{name: "Default", opts: []string{"bus=virtio-scsi"}, directCache: true, want: "native"},
+		{name: "io_uring", opts: []string{"aio=io_uring"}, directCache: true, ioUring: true, want: "io_uring"},
+		{name: "io_uring unsupported", opts: []string{"aio=io_uring"}, directCache: true, wantErr: true},
+		{name: "native", opts: []string{"aio=native"}, directCache: true, want: "native"},
+		{name: "native without direct I/O", opts: []string{"aio=native"}, wantErr: true},
+		{name: "threads", opts: []string{"cache=none", "aio=threads"}, directCache: true, want: "threads"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := qemuDriveAIOMode(tt.opts, "native", tt.directCache, tt.ioUring)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -34,6 +34,9 @@ const (
 	CSM
 )
 
+// OVMFDebugFirmware is the name of the debug build of the x86_64 firmware code, expected alongside the regular build.
+const OVMFDebugFirmware = "OVMF_CODE.4MB.debug.fd"
+
 var architectureInstallations = map[int][]Installation{
 	osarch.ARCH_64BIT_INTEL_X86: {{
 		Path: "/usr/share/OVMF",
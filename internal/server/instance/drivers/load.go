@@ -86,10 +86,10 @@ func validDevices(state *state.State, p api.Project, instanceType instancetype.T
 	var checkedDevices []string
 
 	checkDevices := func(devices deviceConfig.Devices, expanded bool) error {
-		// Check each device individually using the device package.
-		for deviceName, deviceConfig := range devices {
+		// Check each device individually using the device package, reporting all the invalid devices at once.
+		return internalInstance.ValidateDevices(devices, func(deviceName string, deviceConfig deviceConfig.Device) error {
 			if expanded && slices.Contains(checkedDevices, deviceName) {
-				continue // Don't check the device twice if present in both local and expanded.
+				return nil // Don't check the device twice if present in both local and expanded.
 			}
 
 			// Enforce a maximum name length of 64 characters.
@@ -104,16 +104,16 @@ func validDevices(state *state.State, p api.Project, instanceType instancetype.T
 					// Skip unsupported devices in expanded config.
 					// This allows mixed instance type profiles to be used where some devices
 					// are only supported with specific instance types.
-					continue
+					return nil
 				}
 
 				return fmt.Errorf("Device validation failed for %q: %w", deviceName, err)
 			}
 
 			checkedDevices = append(checkedDevices, deviceName)
-		}
 
-		return nil
+			return nil
+		}).Err()
 	}
 
 	// Check each local device individually using the device package.
@@ -106,24 +106,30 @@ func GetClusterCPUFlags(ctx context.Context, s *state.State, servers []string, a
 }
 
 // ParseMemoryStr parses a human representation of memory value as int64 type.
-func ParseMemoryStr(memory string) (valueInt int64, err error) {
+func ParseMemoryStr(memory string) (int64, error) {
+	var memoryTotal int64
 	if strings.HasSuffix(memory, "%") {
-		var percent, memoryTotal int64
-
-		percent, err = strconv.ParseInt(strings.TrimSuffix(memory, "%"), 10, 64)
-		if err != nil {
-			return 0, err
-		}
-
+		var err error
 		memoryTotal, err = linux.DeviceTotalMemory()
 		if err != nil {
 			return 0, err
 		}
+	}
+
+	return parseMemoryStrWithTotal(memory, memoryTotal)
+}
 
-		valueInt = (memoryTotal / 100) * percent
-	} else {
-		valueInt, err = units.ParseByteSizeString(memory)
+// parseMemoryStrWithTotal parses a memory value, either a size or a percentage of memoryTotal, into bytes.
+func parseMemoryStrWithTotal(memory string, memoryTotal int64) (int64, error) {
+	percentStr, isPercent := strings.CutSuffix(memory, "%")
+	if !isPercent {
+		return units.ParseByteSizeString(memory)
+	}
+
+	percent, err := strconv.ParseInt(percentStr, 10, 64)
+	if err != nil {
+		return 0, err
 	}
 
-	return valueInt, err
+	return (memoryTotal / 100) * percent, nil
 }
@@ -2,8 +2,10 @@ package instance
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
 	"fmt"
 	"math/big"
@@ -21,6 +23,7 @@ import (
 
 	incus "github.com/lxc/incus/v6/client"
 	"github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/linux"
 	"github.com/lxc/incus/v6/internal/migration"
 	"github.com/lxc/incus/v6/internal/server/backup"
 	"github.com/lxc/incus/v6/internal/server/db"
@@ -58,89 +61,115 @@ var Load func(s *state.State, args db.InstanceArgs, p api.Project) (Instance, er
 // Returns a revert fail function that can be used to undo this function if a subsequent step fails.
 var Create func(s *state.State, args db.InstanceArgs, p api.Project, op *operations.Operation) (Instance, revert.Hook, error)
 
-func exclusiveConfigKeys(key1 string, key2 string, config map[string]string) (val string, ok bool, err error) {
-	if config[key1] != "" && config[key2] != "" {
-		return "", false, fmt.Errorf("Mutually exclusive keys %s and %s are set", key1, key2)
-	}
-
-	val, ok = config[key1]
-	if ok {
-		return
-	}
-
-	val, ok = config[key2]
-	if ok {
-		return
-	}
-
-	return "", false, nil
-}
-
 // ValidConfig validates an instance's config.
 func ValidConfig(sysOS *sys.OS, config map[string]string, expanded bool, instanceType instancetype.Type) error {
 	if config == nil {
 		return nil
 	}
 
-	for k, v := range config {
-		if instanceType == instancetype.Any && !expanded && strings.HasPrefix(k, instance.ConfigVolatilePrefix) {
+	// Check each key individually, reporting all the invalid keys at once.
+	err := instance.ValidateConfig(config, func(k string, v string) error {
+		if instanceType == instancetype.Any && !expanded && instance.IsVolatileConfig(k) {
 			return fmt.Errorf("Volatile keys can only be set on instances")
 		}
 
-		if instanceType == instancetype.Any && !expanded && strings.HasPrefix(k, "image.") {
+		if instanceType == instancetype.Any && !expanded && instance.IsImageConfig(k) {
 			return fmt.Errorf("Image keys can only be set on instances")
 		}
 
-		err := validConfigKey(sysOS, k, v, instanceType)
-		if err != nil {
-			return err
-		}
-	}
-
-	_, rawSeccomp := config["raw.seccomp"]
-	_, isAllow, err := exclusiveConfigKeys("security.syscalls.allow", "security.syscalls.whitelist", config)
+		return validConfigKey(sysOS, k, v, instanceType)
+	}).Err()
 	if err != nil {
 		return err
 	}
 
-	_, isDeny, err := exclusiveConfigKeys("security.syscalls.deny", "security.syscalls.blacklist", config)
+	err = instance.ValidateSyscallConfig(config)
 	if err != nil {
 		return err
 	}
 
-	val, _, err := exclusiveConfigKeys("security.syscalls.deny_default", "security.syscalls.blacklist_default", config)
+	_, err = seccomp.SyscallInterceptMountFilter(config)
 	if err != nil {
 		return err
 	}
 
-	isDenyDefault := util.IsTrue(val)
+	if expanded && (util.IsFalseOrEmpty(config["security.privileged"])) && sysOS.IdmapSet == nil {
+		return fmt.Errorf("No uid/gid allocation configured. In this mode, only privileged containers are supported")
+	}
 
-	val, _, err = exclusiveConfigKeys("security.syscalls.deny_compat", "security.syscalls.blacklist_compat", config)
+	if util.IsTrue(config["security.privileged"]) && util.IsTrue(config["nvidia.runtime"]) {
+		return fmt.Errorf("nvidia.runtime is incompatible with privileged containers")
+	}
+
+	err = instance.ValidateSecurityIdmapConsistency(config)
 	if err != nil {
 		return err
 	}
 
-	isDenyCompat := util.IsTrue(val)
+	if !expanded {
+		err = instance.ValidateNofileLimit(config)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidHostConfig validates the expanded config of an instance against the local host.
+// As the host may change after the config is set, this is only enforced on user requests while other callers
+// merely log the error.
+func ValidHostConfig(config map[string]string, instanceType instancetype.Type) error {
+	if instanceType == instancetype.Container && (config["limits.memory.swap"] != "" || config["limits.memory.swap.priority"] != "") {
+		swapTotal, err := linux.GetMeminfo("SwapTotal")
+		if err != nil {
+			return fmt.Errorf("Failed getting host swap size: %w", err)
+		}
 
-	if rawSeccomp && (isAllow || isDeny || isDenyDefault || isDenyCompat) {
-		return fmt.Errorf("raw.seccomp is mutually exclusive with security.syscalls*")
+		_, err = instance.ValidateSwapConfig(config, swapTotal > 0)
+		if err != nil {
+			return err
+		}
 	}
 
-	if isAllow && (isDeny || isDenyDefault || isDenyCompat) {
-		return fmt.Errorf("security.syscalls.allow is mutually exclusive with security.syscalls.deny*")
+	return ValidHostNUMANodes(config)
+}
+
+// ValidHostNUMANodes checks that the NUMA nodes selected through limits.cpu.nodes exist on the local host.
+func ValidHostNUMANodes(config map[string]string) error {
+	if config["limits.cpu.nodes"] == "" || config["limits.cpu.nodes"] == "balanced" {
+		return nil
 	}
 
-	_, err = seccomp.SyscallInterceptMountFilter(config)
+	hostNodes, err := resources.GetOnlineNUMANodes()
 	if err != nil {
 		return err
 	}
 
-	if expanded && (util.IsFalseOrEmpty(config["security.privileged"])) && sysOS.IdmapSet == nil {
-		return fmt.Errorf("No uid/gid allocation configured. In this mode, only privileged containers are supported")
+	err = validateCPUNodes(config["limits.cpu.nodes"], hostNodes)
+	if err != nil {
+		return fmt.Errorf("Invalid limits.cpu.nodes: %w", err)
 	}
 
-	if util.IsTrue(config["security.privileged"]) && util.IsTrue(config["nvidia.runtime"]) {
-		return fmt.Errorf("nvidia.runtime is incompatible with privileged containers")
+	return nil
+}
+
+// validateCPUNodes checks that a limits.cpu.nodes value only references NUMA nodes from hostNodes.
+// This complements the syntactic check of instance.ConfigKeyChecker when the host topology is known.
+func validateCPUNodes(value string, hostNodes []int64) error {
+	if value == "" || value == "balanced" {
+		return nil
+	}
+
+	nodes, err := resources.ParseNumaNodeSet(value)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		if !slices.Contains(hostNodes, node) {
+			return fmt.Errorf("NUMA node %d doesn't exist on this host", node)
+		}
 	}
 
 	return nil
@@ -164,6 +193,16 @@ func validConfigKey(os *sys.OS, key string, value string, instanceType instancet
 		return lxcValidConfig(value)
 	}
 
+	if key == "limits.memory" && strings.HasSuffix(value, "%") {
+		memoryTotal, err := linux.DeviceTotalMemory()
+		if err == nil {
+			err = instance.ValidateMemoryLimit(value, memoryTotal)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	if key == "security.syscalls.deny_compat" || key == "security.syscalls.blacklist_compat" {
 		for _, arch := range os.Architectures {
 			if arch == osarch.ARCH_64BIT_INTEL_X86 ||
@@ -463,11 +502,19 @@ func LoadFromBackup(s *state.State, projectName string, instancePath string, app
 	return inst, nil
 }
 
-// DeviceNextInterfaceHWAddr generates a random MAC address.
-func DeviceNextInterfaceHWAddr() (string, error) {
-	// Generate a new random MAC address using the usual prefix
+// DeviceDefaultHWAddrOUI is the OUI used for generated MAC addresses when none is specified.
+const DeviceDefaultHWAddrOUI = "00:16:3e"
+
+// DeviceNextInterfaceHWAddr generates a random MAC address within the supplied OUI.
+// The default OUI is used if an empty OUI is supplied.
+func DeviceNextInterfaceHWAddr(oui string) (string, error) {
+	if oui == "" {
+		oui = DeviceDefaultHWAddrOUI
+	}
+
+	// Generate a new random MAC address using the prefix
 	ret := bytes.Buffer{}
-	for _, c := range "00:16:3e:xx:xx:xx" {
+	for _, c := range oui + ":xx:xx:xx" {
 		if c == 'x' {
 			c, err := rand.Int(rand.Reader, big.NewInt(16))
 			if err != nil {
@@ -483,6 +530,30 @@ func DeviceNextInterfaceHWAddr() (string, error) {
 	return ret.String(), nil
 }
 
+// DeviceNeedsGeneratedHWAddr returns whether a MAC address should be generated for a NIC device of the given type.
+// No MAC address is generated when one is set on the device, for NIC types using the MAC address of the host device
+// or when generation is disabled through hwaddr.generate.
+func DeviceNeedsGeneratedHWAddr(nicType string, config map[string]string) bool {
+	if slices.Contains([]string{"physical", "ipvlan"}, nicType) || config["hwaddr"] != "" {
+		return false
+	}
+
+	return !util.IsFalse(config["hwaddr.generate"])
+}
+
+// DeviceStableInterfaceHWAddr generates a MAC address derived from the instance UUID and device name.
+// The same UUID and device name always produce the same MAC address within the supplied OUI.
+// The default OUI is used if an empty OUI is supplied.
+func DeviceStableInterfaceHWAddr(instanceUUID string, deviceName string, oui string) string {
+	if oui == "" {
+		oui = DeviceDefaultHWAddrOUI
+	}
+
+	hash := sha256.Sum256([]byte(instanceUUID + "/" + deviceName))
+
+	return fmt.Sprintf("%s:%02x:%02x:%02x", oui, hash[0], hash[1], hash[2])
+}
+
 // BackupLoadByName load an instance backup from the database.
 func BackupLoadByName(s *state.State, project, name string) (*backup.InstanceBackup, error) {
 	var args db.InstanceBackup
@@ -1055,51 +1126,65 @@ func NextSnapshotName(s *state.State, inst Instance, defaultPattern string) (str
 		return "", err
 	}
 
-	count := strings.Count(pattern, "%d")
-	if count > 1 {
-		return "", fmt.Errorf("Snapshot pattern may contain '%%d' only once")
-	} else if count == 1 {
-		var i int
-
-		_ = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-			i = tx.GetNextInstanceSnapshotIndex(ctx, inst.Project().Name, inst.Name(), pattern)
-
-			return nil
-		})
-
-		return strings.Replace(pattern, "%d", strconv.Itoa(i), 1), nil
-	}
-
-	snapshotExists := false
-
 	snapshots, err := inst.Snapshots()
 	if err != nil {
 		return "", err
 	}
 
+	existing := make([]string, 0, len(snapshots))
 	for _, snap := range snapshots {
 		_, snapOnlyName, _ := api.GetParentAndSnapshotName(snap.Name())
-		if snapOnlyName == pattern {
-			snapshotExists = true
-			break
+		existing = append(existing, snapOnlyName)
+	}
+
+	return ResolveSnapshotName(pattern, existing)
+}
+
+// ResolveSnapshotName returns the name of the next snapshot from a rendered snapshot pattern and the names of
+// the existing snapshots.
+// When the pattern contains "%d", it is replaced with one more than the highest index used by the existing
+// snapshots matching the pattern. Otherwise the pattern is used as is, unless a snapshot with that name already
+// exists, in which case "-%d" is appended to it and the same sequence logic applies.
+func ResolveSnapshotName(pattern string, existing []string) (string, error) {
+	count := strings.Count(pattern, "%d")
+	if count > 1 {
+		return "", fmt.Errorf("Snapshot pattern may contain '%%d' only once")
+	}
+
+	if count == 0 {
+		if !slices.Contains(existing, pattern) {
+			return pattern, nil
 		}
+
+		// Append '-0', '-1', etc. if the actual pattern/snapshot name already exists.
+		pattern = pattern + "-%d"
 	}
 
-	// Append '-0', '-1', etc. if the actual pattern/snapshot name already exists
-	if snapshotExists {
-		pattern = fmt.Sprintf("%s-%%d", pattern)
+	prefix, suffix, _ := strings.Cut(pattern, "%d")
 
-		var i int
+	next := 0
+	for _, name := range existing {
+		numStr, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
 
-		_ = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-			i = tx.GetNextInstanceSnapshotIndex(ctx, inst.Project().Name, inst.Name(), pattern)
+		numStr, ok = strings.CutSuffix(numStr, suffix)
+		if !ok {
+			continue
+		}
 
-			return nil
-		})
-		return strings.Replace(pattern, "%d", strconv.Itoa(i), 1), nil
+		num, err := strconv.Atoi(numStr)
+		if err != nil || num < 0 {
+			continue
+		}
+
+		if num >= next {
+			next = num + 1
+		}
 	}
 
-	return pattern, nil
+	return prefix + strconv.Itoa(next) + suffix, nil
 }
 
 // temporaryName returns the temporary instance name using a stable random generator.
@@ -1291,17 +1376,13 @@ func ResourceUsage(instConfig map[string]string, instDevices map[string]map[stri
 
 	// Parse limits.cpu.
 	if limitsCPU != "" {
-		// Check if using shared CPU limits.
-		cpuUsage, err = strconv.ParseInt(limitsCPU, 10, 64)
+		// Get either the shared CPU limit or the count of pinned CPUs.
+		cpuCount, err := resources.EffectiveCPUCount(limitsCPU)
 		if err != nil {
-			// Or get count of pinned CPUs.
-			pinnedCPUs, err := resources.ParseCpuset(limitsCPU)
-			if err != nil {
-				return -1, -1, -1, fmt.Errorf("Failed parsing instance resources limits.cpu: %w", err)
-			}
-
-			cpuUsage = int64(len(pinnedCPUs))
+			return -1, -1, -1, fmt.Errorf("Failed parsing instance resources limits.cpu: %w", err)
 		}
+
+		cpuUsage = int64(cpuCount)
 	} else if instType == api.InstanceTypeVM {
 		// Apply VM CPU cores defaults if not specified.
 		cpuUsage = qemudefault.CPUCores
@@ -1346,3 +1427,99 @@ func ResourceUsage(instConfig map[string]string, instDevices map[string]map[stri
 
 	return cpuUsage, memoryUsage, diskUsage, nil
 }
+
+// AutostartRequires returns the names of the instances listed in boot.autostart.requires.
+func AutostartRequires(config map[string]string) []string {
+	requires := []string{}
+	for _, name := range strings.Split(config["boot.autostart.requires"], ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && !slices.Contains(requires, name) {
+			requires = append(requires, name)
+		}
+	}
+
+	return requires
+}
+
+// SortInstancesForStartup returns the instances in the order they should be started in.
+// Instances are ordered by descending boot.autostart.priority, then by project and by name, except that the
+// instances listed in boot.autostart.requires always come before the instances requiring them. Required instances
+// that aren't part of the supplied list are ignored.
+// An error is returned if the requirements form a cycle. The supplied slice isn't modified.
+func SortInstancesForStartup(instances []Instance) ([]Instance, error) {
+	pending := slices.Clone(instances)
+
+	slices.SortStableFunc(pending, func(a Instance, b Instance) int {
+		aPriority, _ := strconv.Atoi(a.ExpandedConfig()["boot.autostart.priority"])
+		bPriority, _ := strconv.Atoi(b.ExpandedConfig()["boot.autostart.priority"])
+		if aPriority != bPriority {
+			return cmp.Compare(bPriority, aPriority)
+		}
+
+		if a.Project().Name != b.Project().Name {
+			return strings.Compare(a.Project().Name, b.Project().Name)
+		}
+
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	// isPending returns whether an instance of the project is still waiting to be ordered.
+	isPending := func(projectName string, name string) bool {
+		return slices.ContainsFunc(pending, func(inst Instance) bool {
+			return inst.Project().Name == projectName && inst.Name() == name
+		})
+	}
+
+	sorted := make([]Instance, 0, len(instances))
+	for len(pending) > 0 {
+		// Pick the first instance whose required instances have all been ordered already.
+		next := slices.IndexFunc(pending, func(inst Instance) bool {
+			for _, name := range AutostartRequires(inst.ExpandedConfig()) {
+				if isPending(inst.Project().Name, name) {
+					return false
+				}
+			}
+
+			return true
+		})
+
+		if next < 0 {
+			names := make([]string, 0, len(pending))
+			for _, inst := range pending {
+				names = append(names, fmt.Sprintf("%s/%s", inst.Project().Name, inst.Name()))
+			}
+
+			return nil, fmt.Errorf("Dependency cycle in boot.autostart.requires, unable to order instances: %s", strings.Join(names, ", "))
+		}
+
+		sorted = append(sorted, pending[next])
+		pending = slices.Delete(pending, next, next+1)
+	}
+
+	return sorted, nil
+}
+
+// SortInstancesForShutdown returns the instances in the order they should be shut down in.
+// Instances are ordered by descending boot.stop.priority, so the instance with the highest value is shut down
+// first and the instances relying on it (such as a database) should be given a lower value.
+// Instances sharing the same priority are ordered by project and then by name.
+// The supplied slice isn't modified.
+func SortInstancesForShutdown(instances []Instance) []Instance {
+	sorted := slices.Clone(instances)
+
+	slices.SortStableFunc(sorted, func(a Instance, b Instance) int {
+		aPriority, _ := strconv.Atoi(a.ExpandedConfig()["boot.stop.priority"])
+		bPriority, _ := strconv.Atoi(b.ExpandedConfig()["boot.stop.priority"])
+		if aPriority != bPriority {
+			return cmp.Compare(bPriority, aPriority)
+		}
+
+		if a.Project().Name != b.Project().Name {
+			return strings.Compare(a.Project().Name, b.Project().Name)
+		}
+
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return sorted
+}
@@ -0,0 +1,193 @@
+package instance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// shutdownTestInstance is a minimal Instance exposing only what is needed to sort instances.
+type shutdownTestInstance struct {
+	Instance
+
+	project  string
+	name     string
+	priority string
+}
+
+func (i *shutdownTestInstance) Name() string {
+	return i.name
+}
+
+func (i *shutdownTestInstance) Project() api.Project {
+	return api.Project{Name: i.project}
+}
+
+func (i *shutdownTestInstance) ExpandedConfig() map[string]string {
+	return map[string]string{"boot.stop.priority": i.priority}
+}
+
+func TestSortInstancesForShutdown(t *testing.T) {
+	database := &shutdownTestInstance{project: "default", name: "db", priority: "0"}
+	web := &shutdownTestInstance{project: "default", name: "web", priority: "10"}
+	cacheA := &shutdownTestInstance{project: "default", name: "cache-a", priority: "5"}
+	cacheB := &shutdownTestInstance{project: "default", name: "cache-b", priority: "5"}
+	cacheOther := &shutdownTestInstance{project: "other", name: "cache-a", priority: "5"}
+	unset := &shutdownTestInstance{project: "default", name: "app", priority: ""}
+
+	instances := []Instance{database, cacheOther, cacheB, unset, web, cacheA}
+	sorted := SortInstancesForShutdown(instances)
+
+	assert.Equal(t, []Instance{web, cacheA, cacheB, cacheOther, unset, database}, sorted)
+
+	// The supplied slice is left untouched.
+	assert.Equal(t, []Instance{database, cacheOther, cacheB, unset, web, cacheA}, instances)
+}
+
+// Test that stable MAC addresses only depend on the instance UUID and device name.
+func TestDeviceStableInterfaceHWAddr(t *testing.T) {
+	uuid := "6c2a5e4a-5b3e-4a4f-9f2c-2b7d9c1e0f11"
+
+	hwaddr := DeviceStableInterfaceHWAddr(uuid, "eth0", "")
+	assert.Regexp(t, "^00:16:3e:[0-9a-f]{2}:[0-9a-f]{2}:[0-9a-f]{2}$", hwaddr)
+	assert.Equal(t, hwaddr, DeviceStableInterfaceHWAddr(uuid, "eth0", ""))
+	assert.NotEqual(t, hwaddr, DeviceStableInterfaceHWAddr(uuid, "eth1", ""))
+	assert.NotEqual(t, hwaddr, DeviceStableInterfaceHWAddr("0c7fa2e8-7d86-4c0e-8a1b-5f0a3b2d6e94", "eth0", ""))
+
+	// A custom OUI only replaces the prefix.
+	customHwaddr := DeviceStableInterfaceHWAddr(uuid, "eth0", "02:ab:cd")
+	assert.Equal(t, "02:ab:cd"+hwaddr[8:], customHwaddr)
+}
+
+// Test that random MAC addresses use the supplied OUI.
+func TestDeviceNextInterfaceHWAddr(t *testing.T) {
+	hwaddr, err := DeviceNextInterfaceHWAddr("")
+	assert.NoError(t, err)
+	assert.Regexp(t, "^00:16:3e:[0-9a-f]{2}:[0-9a-f]{2}:[0-9a-f]{2}$", hwaddr)
+
+	hwaddr, err = DeviceNextInterfaceHWAddr("02:ab:cd")
+	assert.NoError(t, err)
+	assert.Regexp(t, "^02:ab:cd:[0-9a-f]{2}:[0-9a-f]{2}:[0-9a-f]{2}$", hwaddr)
+}
+
+func TestResolveSnapshotName(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		existing []string
+		want     string
+		wantErr  bool
+	}{
+		{name: "Static pattern", pattern: "daily", existing: []string{"snap0"}, want: "daily"},
+		{name: "Colliding static pattern", pattern: "daily", existing: []string{"daily"}, want: "daily-0"},
+		{name: "Colliding static pattern with suffixes", pattern: "daily", existing: []string{"daily", "daily-0", "daily-3"}, want: "daily-4"},
+		{name: "Sequence without snapshots", pattern: "snap%d", want: "snap0"},
+		{name: "Sequence", pattern: "snap%d", existing: []string{"snap0", "snap2", "other5"}, want: "snap3"},
+		{name: "Sequence with suffix", pattern: "snap%d-daily", existing: []string{"snap1-daily", "snap7"}, want: "snap2-daily"},
+		{name: "Sequence ignoring non-numeric names", pattern: "snap%d", existing: []string{"snapshot"}, want: "snap0"},
+		{name: "Multiple sequences", pattern: "snap%d-%d", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := ResolveSnapshotName(tt.pattern, tt.existing)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, name)
+		})
+	}
+}
+
+// startupTestInstance is a minimal Instance exposing only what is needed to order instances for startup.
+type startupTestInstance struct {
+	Instance
+
+	name   string
+	config map[string]string
+}
+
+func (i *startupTestInstance) Name() string {
+	return i.name
+}
+
+func (i *startupTestInstance) Project() api.Project {
+	return api.Project{Name: "default"}
+}
+
+func (i *startupTestInstance) ExpandedConfig() map[string]string {
+	return i.config
+}
+
+func TestSortInstancesForStartup(t *testing.T) {
+	database := &startupTestInstance{name: "db", config: map[string]string{}}
+	app := &startupTestInstance{name: "app", config: map[string]string{"boot.autostart.priority": "10", "boot.autostart.requires": "cache"}}
+	cache := &startupTestInstance{name: "cache", config: map[string]string{"boot.autostart.requires": "db, external"}}
+	other := &startupTestInstance{name: "other", config: map[string]string{"boot.autostart.priority": "5"}}
+
+	sorted, err := SortInstancesForStartup([]Instance{app, cache, other, database})
+	assert.NoError(t, err)
+	assert.Equal(t, []Instance{other, database, cache, app}, sorted)
+}
+
+func TestSortInstancesForStartup_Cycle(t *testing.T) {
+	a := &startupTestInstance{name: "a", config: map[string]string{"boot.autostart.requires": "b"}}
+	b := &startupTestInstance{name: "b", config: map[string]string{"boot.autostart.requires": "c"}}
+	c := &startupTestInstance{name: "c", config: map[string]string{"boot.autostart.requires": "a"}}
+	d := &startupTestInstance{name: "d", config: map[string]string{}}
+
+	_, err := SortInstancesForStartup([]Instance{a, b, c, d})
+	assert.ErrorContains(t, err, "default/a, default/b, default/c")
+
+	self := &startupTestInstance{name: "self", config: map[string]string{"boot.autostart.requires": "self"}}
+	_, err = SortInstancesForStartup([]Instance{self})
+	assert.Error(t, err)
+}
+
+// Test when a MAC address gets generated for a NIC device.
+func TestDeviceNeedsGeneratedHWAddr(t *testing.T) {
+	tests := []struct {
+		name    string
+		nicType string
+		config  map[string]string
+		want    bool
+	}{
+		{name: "Default", nicType: "macvlan", config: map[string]string{}, want: true},
+		{name: "Generation enabled", nicType: "macvlan", config: map[string]string{"hwaddr.generate": "true"}, want: true},
+		{name: "Generation disabled", nicType: "macvlan", config: map[string]string{"hwaddr.generate": "false"}, want: false},
+		{name: "Explicit MAC address", nicType: "bridged", config: map[string]string{"hwaddr": "00:16:3e:00:00:01"}, want: false},
+		{name: "Physical", nicType: "physical", config: map[string]string{}, want: false},
+		{name: "IPVLAN", nicType: "ipvlan", config: map[string]string{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DeviceNeedsGeneratedHWAddr(tt.nicType, tt.config))
+		})
+	}
+}
+
+func TestValidateCPUNodes(t *testing.T) {
+	hostNodes := []int64{0, 1}
+
+	assert.NoError(t, validateCPUNodes("", hostNodes))
+	assert.NoError(t, validateCPUNodes("balanced", hostNodes))
+	assert.NoError(t, validateCPUNodes("0", hostNodes))
+	assert.NoError(t, validateCPUNodes("0-1", hostNodes))
+	assert.NoError(t, validateCPUNodes("1,0", hostNodes))
+	assert.Error(t, validateCPUNodes("2", hostNodes))
+	assert.Error(t, validateCPUNodes("0-7", hostNodes))
+	assert.Error(t, validateCPUNodes("0-7", []int64{0}))
+	assert.Error(t, validateCPUNodes("foo", hostNodes))
+
+	// The syntactic check is unaware of the host topology.
+	checker, err := instance.ConfigKeyChecker("limits.cpu.nodes", api.InstanceTypeAny)
+	assert.NoError(t, err)
+	assert.NoError(t, checker("0-7"))
+}
@@ -9,6 +9,7 @@ type Addr struct {
 	DevName string
 	Address string
 	Scope   string
+	Flags   string
 	Family  string
 }
 
@@ -34,6 +35,10 @@ func (a *Addr) Flush() error {
 		cmd = append(cmd, "scope", a.Scope)
 	}
 
+	if a.Flags != "" {
+		cmd = append(cmd, a.Flags)
+	}
+
 	_, err := subprocess.RunCommand("ip", cmd...)
 	if err != nil {
 		return err
@@ -0,0 +1,22 @@
+package ip
+
+// Bond represents arguments for link of type bond.
+type Bond struct {
+	Link
+	Mode string
+}
+
+// additionalArgs generates bond specific arguments.
+func (bond *Bond) additionalArgs() []string {
+	args := []string{}
+	if bond.Mode != "" {
+		args = append(args, "mode", bond.Mode)
+	}
+
+	return args
+}
+
+// Add adds new virtual link.
+func (bond *Bond) Add() error {
+	return bond.Link.add("bond", bond.additionalArgs())
+}
@@ -21,6 +21,7 @@ const (
 	InstanceConsole          = InstanceAction(api.EventLifecycleInstanceConsole)
 	InstanceConsoleReset     = InstanceAction(api.EventLifecycleInstanceConsoleReset)
 	InstanceConsoleRetrieved = InstanceAction(api.EventLifecycleInstanceConsoleRetrieved)
+	InstanceCPUPinned        = InstanceAction(api.EventLifecycleInstanceCPUPinned)
 	InstanceCreated          = InstanceAction(api.EventLifecycleInstanceCreated)
 	InstanceDeleted          = InstanceAction(api.EventLifecycleInstanceDeleted)
 	InstanceExec             = InstanceAction(api.EventLifecycleInstanceExec)
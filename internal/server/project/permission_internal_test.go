@@ -75,3 +75,19 @@ func TestParseHostIDMapRange(t *testing.T) {
 		assert.Equal(t, idmaps, expected)
 	}
 }
+
+func TestIsContainerLowLevelOptionForbidden(t *testing.T) {
+	assert.True(t, isContainerLowLevelOptionForbidden("raw.lxc", "lxc.apparmor.profile=unconfined"))
+	assert.False(t, isContainerLowLevelOptionForbidden("security.nesting", "true"))
+
+	// Inline syscall lists are allowed, references to host files aren't.
+	assert.False(t, isContainerLowLevelOptionForbidden("security.syscalls.deny", "mount\numount2"))
+	assert.True(t, isContainerLowLevelOptionForbidden("security.syscalls.deny", "file:///etc/passwd"))
+	assert.True(t, isContainerLowLevelOptionForbidden("security.syscalls.allow", "file:///etc/passwd"))
+	assert.True(t, isContainerLowLevelOptionForbidden("security.syscalls.whitelist", "file:///etc/passwd"))
+
+	// Only non-negative OOM score adjustments are allowed.
+	assert.False(t, isContainerLowLevelOptionForbidden("limits.memory.oom_score_adj", "500"))
+	assert.False(t, isContainerLowLevelOptionForbidden("limits.memory.oom_score_adj", "0"))
+	assert.True(t, isContainerLowLevelOptionForbidden("limits.memory.oom_score_adj", "-1000"))
+}
@@ -616,6 +616,11 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 
 		case "restricted.devices.nic":
 			devicesChecks["nic"] = func(device map[string]string) error {
+				// NICs of type "none" only disable an inherited NIC, so they're always allowed.
+				if device["nictype"] == "none" {
+					return nil
+				}
+
 				// Check if the NICs are allowed at all.
 				switch restrictionValue {
 				case "block":
@@ -723,7 +728,7 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 				continue
 			}
 
-			if isContainerOrProfile && !allowContainerLowLevel && isContainerLowLevelOptionForbidden(key) {
+			if isContainerOrProfile && !allowContainerLowLevel && isContainerLowLevelOptionForbidden(key, value) {
 				return fmt.Errorf("Use of low-level config %q on %s %q of project %q is forbidden", key, entityTypeLabel, entityName, project.Name)
 			}
 
@@ -871,15 +876,27 @@ var allowableIntercept = []string{
 }
 
 // Return true if a low-level container option is forbidden.
-func isContainerLowLevelOptionForbidden(key string) bool {
+func isContainerLowLevelOptionForbidden(key string, value string) bool {
 	if strings.HasPrefix(key, "security.syscalls.intercept") && !slices.Contains(allowableIntercept, key) {
 		return true
 	}
 
+	// Negative OOM score adjustments make the container's processes less likely to be killed than host ones.
+	if key == "limits.memory.oom_score_adj" {
+		score, err := strconv.Atoi(value)
+		return err == nil && score < 0
+	}
+
+	// Syscall lists referencing a file on the host.
+	if slices.Contains([]string{"security.syscalls.allow", "security.syscalls.deny", "security.syscalls.whitelist", "security.syscalls.blacklist"}, key) && strings.HasPrefix(value, instance.SyscallsFilePrefix) {
+		return true
+	}
+
 	if slices.Contains([]string{
 		"boot.host_shutdown_action",
 		"boot.host_shutdown_timeout",
 		"linux.kernel_modules",
+		"limits.cpu.isolated",
 		"limits.memory.swap",
 		"raw.apparmor",
 		"raw.idmap",
@@ -888,6 +905,7 @@ func isContainerLowLevelOptionForbidden(key string) bool {
 		"security.guestapi.images",
 		"security.idmap.base",
 		"security.idmap.size",
+		"security.syscalls.intercept.mknod.allowed",
 	},
 		key) {
 		return true
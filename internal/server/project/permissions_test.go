@@ -95,6 +95,41 @@ func TestAllowInstanceCreation_Above(t *testing.T) {
 	assert.EqualError(t, err, `Reached maximum number of instances of type "container" in project "p1"`)
 }
 
+// Listing the devices a container may create through mknod interception is a low-level option.
+func TestAllowInstanceCreation_MknodAllowed(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	id, err := cluster.CreateProject(ctx, tx.Tx(), cluster.Project{Name: "p1"})
+	require.NoError(t, err)
+
+	err = cluster.CreateProjectConfig(ctx, tx.Tx(), id, map[string]string{"restricted": "true", "restricted.containers.interception": "allow"})
+	require.NoError(t, err)
+
+	req := api.InstancesPost{
+		Name: "c1",
+		Type: api.InstanceTypeContainer,
+	}
+
+	req.Config = map[string]string{"security.syscalls.intercept.mknod": "true"}
+	err = project.AllowInstanceCreation(tx, "p1", req)
+	assert.NoError(t, err)
+
+	req.Config["security.syscalls.intercept.mknod.allowed"] = "10:200"
+	err = project.AllowInstanceCreation(tx, "p1", req)
+	assert.Error(t, err)
+
+	id, err = cluster.CreateProject(ctx, tx.Tx(), cluster.Project{Name: "p2"})
+	require.NoError(t, err)
+
+	err = cluster.CreateProjectConfig(ctx, tx.Tx(), id, map[string]string{"restricted": "true", "restricted.containers.interception": "allow", "restricted.containers.lowlevel": "allow"})
+	require.NoError(t, err)
+
+	err = project.AllowInstanceCreation(tx, "p2", req)
+	assert.NoError(t, err)
+}
+
 // If a limit is configured, but for a different instance type, the check
 // passes.
 func TestAllowInstanceCreation_DifferentType(t *testing.T) {
@@ -44,8 +44,8 @@ func GetCPUIsolated() []int64 {
 	return isolatedCpusInt
 }
 
-// parseRangedListToInt64Slice takes an `input` of the form "1,2,8-10,5-7" and returns a slice of int64s
-// containing the expanded list of numbers. In this example, the returned slice would be [1,2,8,9,10,5,6,7].
+// parseRangedListToInt64Slice takes an `input` of the form "1,2,8-10,5-7" and returns a sorted slice of int64s
+// containing the expanded list of unique numbers. In this example, the returned slice would be [1,2,5,6,7,8,9,10].
 // The elements in the output slice are meant to represent hardware entity identifiers (e.g, either CPU or NUMA node IDs).
 func parseRangedListToInt64Slice(input string) ([]int64, error) {
 	res := []int64{}
@@ -68,6 +68,10 @@ func parseRangedListToInt64Slice(input string) ([]int64, error) {
 				return nil, fmt.Errorf("Invalid CPU/NUMA set value: %w", err)
 			}
 
+			if low > high {
+				return nil, fmt.Errorf("Invalid CPU/NUMA set range %q: start is greater than end", chunk)
+			}
+
 			for i := low; i <= high; i++ {
 				res = append(res, i)
 			}
@@ -82,7 +86,9 @@ func parseRangedListToInt64Slice(input string) ([]int64, error) {
 		}
 	}
 
-	return res, nil
+	slices.Sort(res)
+
+	return slices.Compact(res), nil
 }
 
 // ParseCpuset parses a `limits.cpu` range into a list of CPU ids.
@@ -95,6 +101,26 @@ func ParseCpuset(cpu string) ([]int64, error) {
 	return cpus, nil
 }
 
+// EffectiveCPUCount returns the number of CPUs a `limits.cpu` value resolves to.
+// This is either the plain CPU count or the number of distinct CPUs in a pinned CPU set.
+func EffectiveCPUCount(cpu string) (int, error) {
+	count, err := strconv.Atoi(cpu)
+	if err == nil {
+		if count < 0 {
+			return -1, fmt.Errorf("Invalid CPU count %q", cpu)
+		}
+
+		return count, nil
+	}
+
+	cpus, err := ParseCpuset(cpu)
+	if err != nil {
+		return -1, err
+	}
+
+	return len(cpus), nil
+}
+
 // ParseNumaNodeSet parses a `limits.cpu.nodes` into a list of NUMA node ids.
 func ParseNumaNodeSet(numaNodeSet string) ([]int64, error) {
 	nodes, err := parseRangedListToInt64Slice(numaNodeSet)
@@ -105,6 +131,21 @@ func ParseNumaNodeSet(numaNodeSet string) ([]int64, error) {
 	return nodes, nil
 }
 
+// GetOnlineNUMANodes returns the IDs of the online NUMA nodes of the host.
+// Hosts without NUMA support are reported as having a single node 0.
+func GetOnlineNUMANodes() ([]int64, error) {
+	content, err := os.ReadFile(filepath.Join(sysDevicesNode, "online"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []int64{0}, nil
+		}
+
+		return nil, fmt.Errorf("Failed to read online NUMA nodes: %w", err)
+	}
+
+	return ParseNumaNodeSet(strings.TrimSpace(string(content)))
+}
+
 func getCPUCache(path string) ([]api.ResourcesCPUCache, error) {
 	caches := []api.ResourcesCPUCache{}
 
@@ -0,0 +1,86 @@
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCpuset(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []int64
+		wantErr bool
+	}{
+		{value: "0", want: []int64{0}},
+		{value: "0-3,5", want: []int64{0, 1, 2, 3, 5}},
+		{value: "5,0-3", want: []int64{0, 1, 2, 3, 5}},
+		{value: "1,0-2,2", want: []int64{0, 1, 2}},
+		{value: "3-3", want: []int64{3}},
+		{value: "3-0", wantErr: true},
+		{value: "", wantErr: true},
+		{value: "0-", wantErr: true},
+		{value: "a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			cpus, err := ParseCpuset(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, cpus)
+		})
+	}
+}
+
+func TestEffectiveCPUCount(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "4", want: 4},
+		{value: "0-3", want: 4},
+		{value: "0,2,4-6", want: 5},
+		{value: "1,0-2,2", want: 3},
+		{value: "-1", wantErr: true},
+		{value: "", wantErr: true},
+		{value: "a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			count, err := EffectiveCPUCount(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, count)
+		})
+	}
+}
+
+func TestGetOnlineNUMANodes(t *testing.T) {
+	original := sysDevicesNode
+	t.Cleanup(func() { sysDevicesNode = original })
+
+	sysDevicesNode = t.TempDir()
+
+	// No NUMA support.
+	nodes, err := GetOnlineNUMANodes()
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{0}, nodes)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(sysDevicesNode, "online"), []byte("0-1,3\n"), 0o644))
+	nodes, err = GetOnlineNUMANodes()
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{0, 1, 3}, nodes)
+}
@@ -459,6 +459,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -467,6 +468,7 @@ import (
 	liblxc "github.com/lxc/go-lxc"
 	"golang.org/x/sys/unix"
 
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
 	"github.com/lxc/incus/v6/internal/linux"
 	"github.com/lxc/incus/v6/internal/netutils"
 	"github.com/lxc/incus/v6/internal/server/cgroup"
@@ -740,6 +742,21 @@ func MakePidFd(pid int, s *state.State) (int, *os.File) {
 	return -1, nil
 }
 
+// seccompResolveSyscallList returns the syscall list, loading it from a file when a file reference is used.
+func seccompResolveSyscallList(value string) (string, error) {
+	path, isFile := strings.CutPrefix(value, internalInstance.SyscallsFilePrefix)
+	if !isFile {
+		return value, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Failed reading syscall list file %q: %w", path, err)
+	}
+
+	return string(content), nil
+}
+
 func seccompGetPolicyContent(s *state.State, c Instance) (string, error) {
 	config := c.ExpandedConfig()
 
@@ -756,6 +773,11 @@ func seccompGetPolicyContent(s *state.State, c Instance) (string, error) {
 		allowlist = config["security.syscalls.whitelist"]
 	}
 
+	allowlist, err := seccompResolveSyscallList(allowlist)
+	if err != nil {
+		return "", err
+	}
+
 	if allowlist != "" {
 		if s.OS.LXCFeatures["seccomp_allow_deny_syntax"] {
 			policy += "allowlist\n[all]\n"
@@ -846,6 +868,11 @@ func seccompGetPolicyContent(s *state.State, c Instance) (string, error) {
 		denylist = config["security.syscalls.blacklist"]
 	}
 
+	denylist, err = seccompResolveSyscallList(denylist)
+	if err != nil {
+		return "", err
+	}
+
 	if denylist != "" {
 		policy += denylist
 	}
@@ -853,6 +880,144 @@ func seccompGetPolicyContent(s *state.State, c Instance) (string, error) {
 	return policy, nil
 }
 
+// seccompIntercepts maps the security.syscalls.intercept.* keys to the policy rules they add.
+var seccompIntercepts = map[string]string{
+	"security.syscalls.intercept.bpf":                seccompNotifyBpf,
+	"security.syscalls.intercept.mknod":              seccompNotifyMknod,
+	"security.syscalls.intercept.mount":              seccompNotifyMount,
+	"security.syscalls.intercept.sched_setscheduler": seccompNotifySchedSetscheduler,
+	"security.syscalls.intercept.setxattr":           seccompNotifySetxattr,
+	"security.syscalls.intercept.sysinfo":            seccompNotifySysinfo,
+}
+
+// SeccompProfile represents the effective seccomp configuration of a container.
+type SeccompProfile struct {
+	// Raw is set when raw.seccomp replaces the generated policy.
+	Raw bool
+
+	// Allow lists the allowed syscall rules when the policy is an allow list.
+	Allow []string
+
+	// Deny lists the denied syscall rules, including the default ones and those added by syscall interception.
+	Deny []string
+
+	// DenyCompat is set when the compat syscalls of the architecture are denied.
+	DenyCompat bool
+
+	// RejectForceUmount is set when forced unmounts are rejected.
+	RejectForceUmount bool
+
+	// Intercept lists the syscalls intercepted by the server, regardless of the host supporting interception.
+	Intercept []string
+}
+
+// seccompPolicyRules splits a policy snippet into its rules, skipping comments and section headers.
+func seccompPolicyRules(policy string) []string {
+	rules := []string{}
+	for _, line := range strings.Split(policy, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "[") {
+			continue
+		}
+
+		rules = append(rules, line)
+	}
+
+	return rules
+}
+
+// ComputeSeccompProfile returns the effective seccomp profile of a container from its configuration.
+// It returns an error when mutually exclusive keys are combined.
+func ComputeSeccompProfile(config map[string]string) (SeccompProfile, error) {
+	err := internalInstance.ValidateSyscallConfig(config)
+	if err != nil {
+		return SeccompProfile{}, err
+	}
+
+	profile := SeccompProfile{}
+
+	// Full policy override.
+	if config["raw.seccomp"] != "" {
+		profile.Raw = true
+		return profile, nil
+	}
+
+	allowlist := config["security.syscalls.allow"]
+	if allowlist == "" {
+		allowlist = config["security.syscalls.whitelist"]
+	}
+
+	allowlist, err = seccompResolveSyscallList(allowlist)
+	if err != nil {
+		return SeccompProfile{}, err
+	}
+
+	profile.Deny = []string{}
+	if allowlist != "" {
+		profile.Allow = seccompPolicyRules(allowlist)
+	} else {
+		defaultFlag, ok := config["security.syscalls.deny_default"]
+		if !ok {
+			defaultFlag, ok = config["security.syscalls.blacklist_default"]
+		}
+
+		if !ok || util.IsTrue(defaultFlag) {
+			for _, rule := range seccompPolicyRules(defaultSeccompPolicy) {
+				if rule == "reject_force_umount" {
+					profile.RejectForceUmount = true
+					continue
+				}
+
+				profile.Deny = append(profile.Deny, rule)
+			}
+		}
+
+		compat, ok := config["security.syscalls.deny_compat"]
+		if !ok {
+			compat = config["security.syscalls.blacklist_compat"]
+		}
+
+		profile.DenyCompat = util.IsTrue(compat)
+
+		denylist, ok := config["security.syscalls.deny"]
+		if !ok {
+			denylist = config["security.syscalls.blacklist"]
+		}
+
+		denylist, err = seccompResolveSyscallList(denylist)
+		if err != nil {
+			return SeccompProfile{}, err
+		}
+
+		profile.Deny = append(profile.Deny, seccompPolicyRules(denylist)...)
+	}
+
+	// Syscall interception.
+	profile.Intercept = []string{}
+	for key, policy := range seccompIntercepts {
+		if !util.IsTrue(config[key]) {
+			continue
+		}
+
+		for _, rule := range seccompPolicyRules(policy) {
+			syscall, _, _ := strings.Cut(rule, " ")
+			if !slices.Contains(profile.Intercept, syscall) {
+				profile.Intercept = append(profile.Intercept, syscall)
+			}
+		}
+	}
+
+	slices.Sort(profile.Intercept)
+
+	// The new mount API is blocked when intercepting mount.
+	if util.IsTrue(config["security.syscalls.intercept.mount"]) {
+		profile.Deny = append(profile.Deny, seccompPolicyRules(seccompBlockNewMountAPI)...)
+	}
+
+	return profile, nil
+}
+
 // CreateProfile creates a seccomp profile.
 func CreateProfile(s *state.State, c Instance) error {
 	/* Unlike apparmor, there is no way to "cache" profiles, and profiles
@@ -1318,6 +1483,33 @@ func (s *Server) doDeviceSyscall(c Instance, args *MknodArgs, siov *Iovec) int {
 	return 0
 }
 
+// mknodDeviceAllowed returns 0 if the container may create the device, -EPERM otherwise.
+// Without a security.syscalls.intercept.mknod.allowed list, only the default set of standard devices is allowed.
+func mknodDeviceAllowed(c Instance, dev C.dev_t, mode C.mode_t) C.int {
+	allowed := c.ExpandedConfig()["security.syscalls.intercept.mknod.allowed"]
+	if allowed == "" {
+		return C.device_allowed(dev, mode)
+	}
+
+	if uint32(mode)&unix.S_IFMT != unix.S_IFCHR {
+		return -C.EPERM
+	}
+
+	device := internalInstance.MknodDevice{Major: unix.Major(uint64(dev)), Minor: unix.Minor(uint64(dev))}
+
+	// Overlay whiteouts are always allowed.
+	if device.Major == 0 && device.Minor == 0 {
+		return 0
+	}
+
+	devices, err := internalInstance.ParseMknodAllowed(allowed)
+	if err != nil || !slices.Contains(devices, device) {
+		return -C.EPERM
+	}
+
+	return 0
+}
+
 // HandleMknodSyscall handles a mknod syscall.
 func (s *Server) HandleMknodSyscall(c Instance, siov *Iovec) int {
 	ctx := logger.Ctx{"container": c.Name(),
@@ -1333,7 +1525,7 @@ func (s *Server) HandleMknodSyscall(c Instance, siov *Iovec) int {
 
 	defer logger.Debug("Handling mknod syscall", ctx)
 
-	if C.device_allowed(C.dev_t(siov.req.data.args[2]), C.mode_t(siov.req.data.args[1])) < 0 {
+	if mknodDeviceAllowed(c, C.dev_t(siov.req.data.args[2]), C.mode_t(siov.req.data.args[1])) < 0 {
 		ctx["err"] = "Device not allowed"
 		if s.s.OS.SeccompListenerContinue {
 			ctx["syscall_continue"] = "true"
@@ -1397,7 +1589,7 @@ func (s *Server) HandleMknodatSyscall(c Instance, siov *Iovec) int {
 		return int(-C.EINVAL)
 	}
 
-	siov.resp.error = C.device_allowed(C.dev_t(siov.req.data.args[3]), C.mode_t(siov.req.data.args[2]))
+	siov.resp.error = mknodDeviceAllowed(c, C.dev_t(siov.req.data.args[3]), C.mode_t(siov.req.data.args[2]))
 	if siov.resp.error != 0 {
 		ctx["err"] = "Device not allowed"
 		if s.s.OS.SeccompListenerContinue {
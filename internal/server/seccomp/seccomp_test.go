@@ -4,7 +4,13 @@ package seccomp
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
 )
 
 func TestMountFlagsToOpts(t *testing.T) {
@@ -18,3 +24,104 @@ func TestMountFlagsToOpts(t *testing.T) {
 		t.Fatal(fmt.Errorf("Mount options parsing failed with invalid option string: %s", opts))
 	}
 }
+
+func TestComputeSeccompProfile(t *testing.T) {
+	listPath := filepath.Join(t.TempDir(), "syscalls")
+	err := os.WriteFile(listPath, []byte("read\nwrite\n"), 0600)
+	assert.NoError(t, err)
+
+	defaultDeny := []string{
+		"kexec_load errno 38",
+		"open_by_handle_at errno 38",
+		"init_module errno 38",
+		"finit_module errno 38",
+		"delete_module errno 38",
+	}
+
+	newMountAPIDeny := []string{
+		"fsopen errno 38",
+		"fsconfig errno 38",
+		"fsinfo errno 38",
+		"fsmount errno 38",
+		"fspick errno 38",
+		"open_tree errno 38",
+		"move_mount errno 38",
+		"openat2 errno 38",
+	}
+
+	tests := []struct {
+		name    string
+		config  map[string]string
+		want    SeccompProfile
+		wantErr string
+	}{
+		{
+			name:   "Default",
+			config: map[string]string{},
+			want:   SeccompProfile{Deny: defaultDeny, RejectForceUmount: true, Intercept: []string{}},
+		},
+		{
+			name:   "Default deny with extra entries",
+			config: map[string]string{"security.syscalls.deny": "mount\nptrace errno 1\n", "security.syscalls.intercept.mknod": "true"},
+			want:   SeccompProfile{Deny: append(append([]string{}, defaultDeny...), "mount", "ptrace errno 1"), RejectForceUmount: true, Intercept: []string{"mknod", "mknodat"}},
+		},
+		{
+			name:   "Default deny disabled",
+			config: map[string]string{"security.syscalls.deny_default": "false", "security.syscalls.deny_compat": "true"},
+			want:   SeccompProfile{Deny: []string{}, DenyCompat: true, Intercept: []string{}},
+		},
+		{
+			name:   "Deprecated deny list",
+			config: map[string]string{"security.syscalls.blacklist": "mount"},
+			want:   SeccompProfile{Deny: append(append([]string{}, defaultDeny...), "mount"), RejectForceUmount: true, Intercept: []string{}},
+		},
+		{
+			name:   "Mount interception",
+			config: map[string]string{"security.syscalls.deny_default": "false", "security.syscalls.intercept.mount": "true"},
+			want:   SeccompProfile{Deny: newMountAPIDeny, Intercept: []string{"mount"}},
+		},
+		{
+			name:   "Allow list from file",
+			config: map[string]string{"security.syscalls.allow": internalInstance.SyscallsFilePrefix + listPath, "security.syscalls.intercept.sysinfo": "true"},
+			want:   SeccompProfile{Allow: []string{"read", "write"}, Deny: []string{}, Intercept: []string{"sysinfo"}},
+		},
+		{
+			name:   "Allow list with mount interception",
+			config: map[string]string{"security.syscalls.allow": "read\nwrite", "security.syscalls.intercept.mount": "true"},
+			want:   SeccompProfile{Allow: []string{"read", "write"}, Deny: newMountAPIDeny, Intercept: []string{"mount"}},
+		},
+		{
+			name:   "Raw policy",
+			config: map[string]string{"raw.seccomp": "2\ndenylist\n"},
+			want:   SeccompProfile{Raw: true},
+		},
+		{
+			name:    "Allow and deny",
+			config:  map[string]string{"security.syscalls.allow": "read", "security.syscalls.deny": "mount"},
+			wantErr: "security.syscalls.allow is mutually exclusive with security.syscalls.deny*",
+		},
+		{
+			name:    "Allow and explicit default deny",
+			config:  map[string]string{"security.syscalls.allow": "read", "security.syscalls.deny_default": "true"},
+			wantErr: "security.syscalls.allow is mutually exclusive with security.syscalls.deny*",
+		},
+		{
+			name:    "Raw policy and deny",
+			config:  map[string]string{"raw.seccomp": "2\ndenylist\n", "security.syscalls.deny": "mount"},
+			wantErr: "raw.seccomp is mutually exclusive with security.syscalls*",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := ComputeSeccompProfile(tt.config)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, profile)
+		})
+	}
+}
@@ -275,6 +275,20 @@ func SetProject(path string, id uint32) error {
 	return err
 }
 
+// SetDirProject sets the project quota ID and project inherit flag on the given directory only.
+// Files later created within the directory inherit the project ID.
+func SetDirProject(path string, id uint32) error {
+	// Call ioctl through CGo.
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	if C.quota_set_path(cPath, C.uint32_t(id), C.bool(true)) != 0 {
+		return fmt.Errorf(`Failed to set project ID "%d" on %q (inherit %t)`, id, path, true)
+	}
+
+	return nil
+}
+
 // DeleteProject unsets the project id from the path and clears the quota for the project ID.
 func DeleteProject(path string, id uint32) error {
 	// Unset the project from the path.
@@ -304,8 +318,13 @@ func GetProjectUsage(path string, id uint32) (int64, error) {
 	cDevPath := C.CString(devPath)
 	defer C.free(unsafe.Pointer(cDevPath))
 
-	size := C.quota_get_usage(cDevPath, C.uint32_t(id))
+	size, errno := C.quota_get_usage(cDevPath, C.uint32_t(id))
 	if size < 0 {
+		// Some filesystems (like XFS) report ENOENT for project IDs without any quota or usage.
+		if errno != nil {
+			return -1, fmt.Errorf(`Failed to get project consumption for ID "%d" on %q: %w`, id, devPath, errno)
+		}
+
 		return -1, fmt.Errorf(`Failed to get project consumption for ID "%d" on %q`, id, devPath)
 	}
 
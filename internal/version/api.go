@@ -457,6 +457,33 @@ var APIExtensions = []string{
 	"authorization_scriptlet",
 	"console_force",
 	"network_ovn_state_addresses",
+	"disk_overlay",
+	"instance_syscalls_file",
+	"nic_physical_multi_parent",
+	"disk_source_quota",
+	"instance_cloud_init_ssh_keys",
+	"disk_limits_priority",
+	"nic_hwaddr_stable",
+	"instance_limits_cpu_policy",
+	"instance_protection_start",
+	"instance_limits_memory_oom_score_adj",
+	"instance_syscalls_intercept_mknod_allowed",
+	"instance_boot_debug_edk2",
+	"instance_idmap_size_auto",
+	"instance_limits_cpu_isolated",
+	"instance_boot_autostart_requires",
+	"device_nic_none",
+	"nic_hwaddr_oui",
+	"instance_limits_cpu_emulator",
+	"instance_limits_cpu_weight",
+	"instance_snapshots_schedule_maximum",
+	"disk_readonly_recursive",
+	"nic_bridged_ipv6_accept_ra",
+	"instance_oci_command",
+	"disk_io_mode",
+	"instance_limits_nofile",
+	"event_lifecycle_instance_cpu_pinned",
+	"nic_hwaddr_generate",
 }
 
 // APIExtensionsCount returns the number of available API extensions.
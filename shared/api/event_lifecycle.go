@@ -38,6 +38,7 @@ const (
 	EventLifecycleInstanceConsole                   = "instance-console"
 	EventLifecycleInstanceConsoleReset              = "instance-console-reset"
 	EventLifecycleInstanceConsoleRetrieved          = "instance-console-retrieved"
+	EventLifecycleInstanceCPUPinned                 = "instance-cpu-pinned"
 	EventLifecycleInstanceCreated                   = "instance-created"
 	EventLifecycleInstanceDeleted                   = "instance-deleted"
 	EventLifecycleInstanceExec                      = "instance-exec"
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationToClusterJoinToken(t *testing.T) {
+	op := Operation{
+		Metadata: map[string]any{
+			"serverName":  "node2",
+			"secret":      "secret",
+			"fingerprint": "fingerprint",
+			"addresses":   []any{"10.0.0.1:8443"},
+			"expiresAt":   "2026-01-02T03:04:05Z",
+		},
+	}
+
+	token, err := op.ToClusterJoinToken()
+	assert.NoError(t, err)
+	assert.Equal(t, "node2", token.ServerName)
+	assert.Equal(t, []string{"10.0.0.1:8443"}, token.Addresses)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), token.ExpiresAt)
+
+	// Tokens which never expire don't have an expiry date.
+	delete(op.Metadata, "expiresAt")
+
+	token, err = op.ToClusterJoinToken()
+	assert.NoError(t, err)
+	assert.True(t, token.ExpiresAt.IsZero())
+
+	op.Metadata["expiresAt"] = "invalid"
+
+	_, err = op.ToClusterJoinToken()
+	assert.Error(t, err)
+}
@@ -70,12 +70,12 @@ func NewSetFromIncusIDMap(value string) (*Set, error) {
 	ret := &Set{}
 
 	for _, line := range strings.Split(value, "\n") {
-		if line == "" {
+		if strings.TrimSpace(line) == "" {
 			continue
 		}
 
-		entries := strings.Split(line, " ")
-		if len(entries) != 3 {
+		entries := strings.Fields(line)
+		if len(entries) != 3 && len(entries) != 4 {
 			return nil, fmt.Errorf("Invalid ID map line: %s", line)
 		}
 
@@ -93,6 +93,18 @@ func NewSetFromIncusIDMap(value string) (*Set, error) {
 			return nil, fmt.Errorf("The ID map ranges are of different sizes: %s", line)
 		}
 
+		// An optional count maps that many consecutive IDs from single host and namespace IDs.
+		if len(entries) == 4 {
+			if insideSize != 1 {
+				return nil, fmt.Errorf("An ID map count can't be combined with ID ranges: %s", line)
+			}
+
+			insideSize, err = strconv.ParseInt(entries[3], 10, 64)
+			if err != nil || insideSize < 1 {
+				return nil, fmt.Errorf("Invalid ID map count: %s", line)
+			}
+		}
+
 		entry := Entry{
 			HostID:   outsideBase,
 			NSID:     insideBase,
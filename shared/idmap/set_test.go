@@ -200,3 +200,18 @@ func TestIdmapHostIDMapRange(t *testing.T) {
 	assert.Equal(t, false, combinedEntry.HostIDsCoveredBy(nil, allowedCombinedMaps))
 	assert.Equal(t, true, combinedEntry.HostIDsCoveredBy(allowedCombinedMaps, allowedCombinedMaps))
 }
+
+func TestNewSetFromIncusIDMap_count(t *testing.T) {
+	set, err := NewSetFromIncusIDMap("uid 100000 0 65536\n\ngid 1000-1999 0-999\n")
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{IsUID: true, HostID: 100000, NSID: 0, MapRange: 65536},
+		{IsGID: true, HostID: 1000, NSID: 0, MapRange: 1000},
+	}, set.Entries)
+
+	_, err = NewSetFromIncusIDMap("both 1000")
+	assert.Error(t, err)
+
+	_, err = NewSetFromIncusIDMap("uid 1000-1999 0-999 1000")
+	assert.Error(t, err)
+}
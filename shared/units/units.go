@@ -3,6 +3,7 @@ package units
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 func handleOverflow(val int64, mult int64) (int64, error) {
@@ -20,7 +21,12 @@ func handleOverflow(val int64, mult int64) (int64, error) {
 
 // ParseByteSizeString parses a human representation of an amount of
 // data into a number of bytes.
+// Surrounding whitespace is ignored and the unit suffix is case insensitive, with decimal (MB) and
+// binary (MiB) units kept distinct. A bare number is a number of bytes.
 func ParseByteSizeString(input string) (int64, error) {
+	// Ignore surrounding whitespace.
+	input = strings.TrimSpace(input)
+
 	// Empty input
 	if input == "" {
 		return 0, nil
@@ -50,34 +56,34 @@ func ParseByteSizeString(input string) (int64, error) {
 		return -1, fmt.Errorf("Invalid integer: %s", input)
 	}
 
-	// Figure out the multiplicator
+	// Figure out the multiplicator (units are case insensitive and may be separated by spaces)
 	multiplicator := int64(0)
-	switch suffix {
-	case "", "B", " bytes":
+	switch strings.ToLower(strings.TrimSpace(suffix)) {
+	case "", "b", "bytes":
 		multiplicator = 1
-	case "kB":
+	case "kb":
 		multiplicator = 1000
-	case "MB":
+	case "mb":
 		multiplicator = 1000 * 1000
-	case "GB":
+	case "gb":
 		multiplicator = 1000 * 1000 * 1000
-	case "TB":
+	case "tb":
 		multiplicator = 1000 * 1000 * 1000 * 1000
-	case "PB":
+	case "pb":
 		multiplicator = 1000 * 1000 * 1000 * 1000 * 1000
-	case "EB":
+	case "eb":
 		multiplicator = 1000 * 1000 * 1000 * 1000 * 1000 * 1000
-	case "KiB":
+	case "kib":
 		multiplicator = 1024
-	case "MiB":
+	case "mib":
 		multiplicator = 1024 * 1024
-	case "GiB":
+	case "gib":
 		multiplicator = 1024 * 1024 * 1024
-	case "TiB":
+	case "tib":
 		multiplicator = 1024 * 1024 * 1024 * 1024
-	case "PiB":
+	case "pib":
 		multiplicator = 1024 * 1024 * 1024 * 1024 * 1024
-	case "EiB":
+	case "eib":
 		multiplicator = 1024 * 1024 * 1024 * 1024 * 1024 * 1024
 	default:
 		return -1, fmt.Errorf("Invalid value: %s", input)
@@ -0,0 +1,39 @@
+package units
+
+import (
+	"testing"
+)
+
+func TestParseByteSizeString(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{input: "2 MiB", want: 2 * 1024 * 1024},
+		{input: "2mib", want: 2 * 1024 * 1024},
+		{input: "2 mb", want: 2 * 1000 * 1000},
+		{input: "2MB", want: 2 * 1000 * 1000},
+		{input: " 4KiB ", want: 4096},
+		{input: "2", want: 2},
+		{input: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseByteSizeString(tt.input)
+		if err != nil {
+			t.Errorf("Unexpected error parsing %q: %v", tt.input, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("Parsing %q returned %d, expected %d", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"MiB", "2 mibs", "2 Mbit", "-2MiB"} {
+		_, err := ParseByteSizeString(input)
+		if err == nil {
+			t.Errorf("Expected an error parsing %q", input)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package validate
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"net"
 	"net/url"
@@ -157,6 +158,16 @@ func IsAny(value string) error {
 	return nil
 }
 
+// IsBase64 validates whether the string is valid standard base64 encoded data.
+func IsBase64(value string) error {
+	_, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return fmt.Errorf("Invalid base64 encoded value: %w", err)
+	}
+
+	return nil
+}
+
 // IsListOf returns a validator for a comma separated list of values.
 func IsListOf(validator func(value string) error) func(value string) error {
 	return func(value string) error {
@@ -192,6 +203,16 @@ func IsSize(value string) error {
 	return nil
 }
 
+// IsBitSize checks if string is valid size according to units.ParseBitSizeString.
+func IsBitSize(value string) error {
+	_, err := units.ParseBitSizeString(value)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // IsDeviceID validates string is four lowercase hex characters suitable as Vendor or Device ID.
 func IsDeviceID(value string) error {
 	match, _ := regexp.MatchString(`^[0-9a-f]{4}$`, value)
@@ -253,6 +274,23 @@ func IsNetworkMAC(value string) error {
 	return nil
 }
 
+// IsNetworkOUI validates an Ethernet OUI, the first 3 bytes of a unicast MAC address. e.g. "00:00:5e".
+func IsNetworkOUI(value string) error {
+	mac, err := net.ParseMAC(value + ":00:00:00")
+
+	// Check is valid OUI length and delimiter.
+	if err != nil || len(value) != 8 || strings.ContainsAny(value, "-.") {
+		return fmt.Errorf("Invalid OUI, must be 3 bytes of hex separated by colons")
+	}
+
+	// Check the multicast bit isn't set.
+	if mac[0]&0x01 != 0 {
+		return fmt.Errorf("Invalid OUI, must not be a multicast prefix")
+	}
+
+	return nil
+}
+
 // IsNetworkAddress validates an IP (v4 or v6) address string.
 func IsNetworkAddress(value string) error {
 	ip := net.ParseIP(value)
@@ -287,6 +325,15 @@ func IsNetworkAddressCIDR(value string) error {
 	return nil
 }
 
+// IsNetworkAddressOrCIDR validates an IP (v4 or v6) address string, either as a single address or in CIDR format.
+func IsNetworkAddressOrCIDR(value string) error {
+	if strings.Contains(value, "/") {
+		return IsNetworkAddressCIDR(value)
+	}
+
+	return IsNetworkAddress(value)
+}
+
 // IsNetworkRange validates an IP range in the format "start-end".
 func IsNetworkRange(value string) error {
 	ips := strings.SplitN(value, "-", 2)
@@ -569,36 +616,60 @@ func IsArchitecture(value string) error {
 	return IsOneOf(osarch.SupportedArchitectures()...)(value)
 }
 
+// cronTriggerAliases are the aliases which don't run at a given time and so can be combined with cron patterns.
+var cronTriggerAliases = []string{"@startup", "@never"}
+
 // IsCron checks that it's a valid cron pattern or alias.
+// Either a comma-separated list of aliases or comma-and-space-separated cron patterns are accepted, but not a mix of both.
+// The exception is the @startup and @never aliases, which can be combined with cron patterns.
 func IsCron(aliases []string) func(value string) error {
 	return func(value string) error {
-		isValid := func(value string) error {
-			// Accept valid aliases.
-			for _, alias := range aliases {
-				if alias == value {
-					return nil
-				}
-			}
+		value = strings.ToLower(value)
 
-			if len(strings.Split(value, " ")) != 5 {
-				return fmt.Errorf("Schedule must be of the form: <minute> <hour> <day-of-month> <month> <day-of-week>")
+		// Aliases can be separated by commas, with or without spaces.
+		entries := strings.Split(value, ",")
+		isAliases := true
+		for _, entry := range entries {
+			if !strings.HasPrefix(strings.TrimSpace(entry), "@") {
+				isAliases = false
+				break
 			}
+		}
 
-			_, err := cron.ParseStandard(value)
-			if err != nil {
-				return fmt.Errorf("Error parsing schedule: %w", err)
+		if isAliases {
+			for _, alias := range entries {
+				alias = strings.TrimSpace(alias)
+				if !slices.Contains(aliases, alias) {
+					return fmt.Errorf("Unknown schedule alias %q", alias)
+				}
 			}
 
 			return nil
 		}
 
-		// Can be comma+space separated (just commas are valid cron pattern).
-		value = strings.ToLower(value)
+		// Cron patterns must be comma+space separated (just commas are valid cron pattern).
 		triggers := strings.Split(value, ", ")
 		for _, trigger := range triggers {
-			err := isValid(trigger)
+			if strings.HasPrefix(strings.TrimSpace(trigger), "@") {
+				trigger = strings.TrimSpace(trigger)
+				if !slices.Contains(cronTriggerAliases, trigger) {
+					return fmt.Errorf("Schedule cannot mix aliases and cron patterns")
+				}
+
+				if !slices.Contains(aliases, trigger) {
+					return fmt.Errorf("Unknown schedule alias %q", trigger)
+				}
+
+				continue
+			}
+
+			if len(strings.Split(trigger, " ")) != 5 {
+				return fmt.Errorf("Schedule must be of the form: <minute> <hour> <day-of-month> <month> <day-of-week>")
+			}
+
+			_, err := cron.ParseStandard(trigger)
 			if err != nil {
-				return err
+				return fmt.Errorf("Error parsing schedule: %w", err)
 			}
 		}
 
@@ -729,6 +800,20 @@ func IsHostname(name string) error {
 	return nil
 }
 
+// IsKernelModuleName checks the value is a valid kernel module name (alphanumeric, underscore and hyphen).
+func IsKernelModuleName(value string) error {
+	if value == "" {
+		return fmt.Errorf("Kernel module name cannot be empty")
+	}
+
+	match, _ := regexp.MatchString(`^[a-zA-Z0-9_-]+$`, value)
+	if !match {
+		return fmt.Errorf("Invalid kernel module name %q", value)
+	}
+
+	return nil
+}
+
 // IsDeviceName checks name is 1-63 characters long, doesn't start with a full stop and contains only alphanumeric,
 // forward slash, hyphen, colon, underscore and full stop characters.
 func IsDeviceName(name string) error {
@@ -29,6 +29,31 @@ func ExampleIsNetworkMAC() {
 	// , false
 }
 
+func ExampleIsNetworkOUI() {
+	tests := []string{
+		"00:00:5e",
+		"02:16:3e",    // locally administered
+		"01:00:5e",    // multicast
+		"00:00:5e:00", // too long
+		"00-00-5e",    // invalid delimiter
+		"invalid",
+		"",
+	}
+
+	for _, v := range tests {
+		err := validate.IsNetworkOUI(v)
+		fmt.Printf("%s, %t\n", v, err == nil)
+	}
+
+	// Output: 00:00:5e, true
+	// 02:16:3e, true
+	// 01:00:5e, false
+	// 00:00:5e:00, false
+	// 00-00-5e, false
+	// invalid, false
+	// , false
+}
+
 func ExampleIsPCIAddress() {
 	tests := []string{
 		"0000:12:ab.0", // valid
@@ -64,6 +89,33 @@ func ExampleIsPCIAddress() {
 	// , false
 }
 
+func ExampleIsNetworkMTU() {
+	tests := []string{
+		"1500",  // valid
+		"1280",  // valid: minimum
+		"16384", // valid: maximum
+		"68",    // invalid: below IPv6 minimum
+		"1279",  // invalid: below minimum
+		"65535", // invalid: above maximum
+		"-1500", // invalid: negative
+		"jumbo", // invalid: not a number
+	}
+
+	for _, v := range tests {
+		err := validate.IsNetworkMTU(v)
+		fmt.Printf("%s, %t\n", v, err == nil)
+	}
+
+	// Output: 1500, true
+	// 1280, true
+	// 16384, true
+	// 68, false
+	// 1279, false
+	// 65535, false
+	// -1500, false
+	// jumbo, false
+}
+
 func ExampleOptional() {
 	tests := []string{
 		"",
@@ -140,3 +192,159 @@ func ExampleIsValidCPUSet() {
 	// Cannot define CPU multiple times
 	// Cannot define CPU multiple times
 }
+
+func ExampleIsKernelModuleName() {
+	tests := []string{
+		"overlay,br_netfilter",  // valid
+		"overlay, br_netfilter", // valid
+		"overlay,",              // invalid: trailing comma
+		"kernel/overlay",        // invalid: slash
+		"",                      // invalid: empty
+	}
+
+	for _, v := range tests {
+		err := validate.IsListOf(validate.IsKernelModuleName)(v)
+		fmt.Printf("%s, %t\n", v, err == nil)
+	}
+
+	// Output: overlay,br_netfilter, true
+	// overlay, br_netfilter, true
+	// overlay,, false
+	// kernel/overlay, false
+	// , false
+}
+
+func ExampleIsBase64() {
+	tests := []string{
+		"aW5jdXM=", // valid
+		"",         // valid: empty
+		"aW5jdXM",  // invalid: missing padding
+		"aW5j=XM=", // invalid: padding in the middle
+		"incus!",   // invalid: not base64
+	}
+
+	for _, v := range tests {
+		err := validate.IsBase64(v)
+		fmt.Printf("%s, %t\n", v, err == nil)
+	}
+
+	// Output: aW5jdXM=, true
+	// , true
+	// aW5jdXM, false
+	// aW5j=XM=, false
+	// incus!, false
+}
+
+func ExampleIsNetworkAddressOrCIDR() {
+	tests := []string{
+		"10.0.0.5",       // valid
+		"10.0.0.5/24",    // valid
+		"2001:db8::1",    // valid
+		"2001:db8::1/64", // valid
+		"10.0.0.256",     // invalid: out of range octet
+		"10.0.0.5/33",    // invalid: prefix too long
+		"10.0.0.5/",      // invalid: missing prefix
+	}
+
+	for _, v := range tests {
+		err := validate.IsNetworkAddressOrCIDR(v)
+		fmt.Printf("%s, %t\n", v, err == nil)
+	}
+
+	// Mixed IPv4 and IPv6 lists.
+	fmt.Println(validate.IsListOf(validate.IsNetworkAddressOrCIDR)("10.0.0.5,2001:db8::1/64") == nil)
+	fmt.Println(validate.IsListOf(validate.IsNetworkAddressOrCIDR)("2001:db8::1/64,10.0.0.256") == nil)
+
+	// Output: 10.0.0.5, true
+	// 10.0.0.5/24, true
+	// 2001:db8::1, true
+	// 2001:db8::1/64, true
+	// 10.0.0.256, false
+	// 10.0.0.5/33, false
+	// 10.0.0.5/, false
+	// true
+	// false
+}
+
+func ExampleIsBitSize() {
+	tests := []string{
+		"100Mbit", // valid
+		"1Gibit",  // valid
+		"1000",    // valid: bits
+		"100MB",   // invalid: bytes rather than bits
+		"fast",    // invalid: not a number
+	}
+
+	for _, v := range tests {
+		err := validate.IsBitSize(v)
+		fmt.Printf("%s, %t\n", v, err == nil)
+	}
+
+	// Output: 100Mbit, true
+	// 1Gibit, true
+	// 1000, true
+	// 100MB, false
+	// fast, false
+}
+
+func ExampleIsSize() {
+	tests := []string{
+		"2MiB",    // valid
+		"2 MiB",   // valid: spaced unit
+		"2mib",    // valid: lowercase unit
+		"2 mb",    // valid: decimal unit
+		" 2GB ",   // valid: surrounding whitespace
+		"2",       // valid: bytes
+		"2 bytes", // valid
+		"2 Mbit",  // invalid: bits rather than bytes
+		"MiB",     // invalid: not a number
+	}
+
+	for _, v := range tests {
+		err := validate.IsSize(v)
+		fmt.Printf("%q, %t\n", v, err == nil)
+	}
+
+	// Output: "2MiB", true
+	// "2 MiB", true
+	// "2mib", true
+	// "2 mb", true
+	// " 2GB ", true
+	// "2", true
+	// "2 bytes", true
+	// "2 Mbit", false
+	// "MiB", false
+}
+
+func ExampleIsCron() {
+	tests := []string{
+		"@daily",                   // valid
+		"@daily,@weekly",           // valid: list of aliases
+		"@daily, @weekly",          // valid: comma-and-space-separated aliases
+		"0 0 * * *",                // valid
+		"0 0,12 * * *, 30 6 * * *", // valid: list of cron patterns
+		"@daily,0 0 * * *",         // invalid: mixed aliases and cron pattern
+		"0 0 * * *, @daily",        // invalid: mixed cron pattern and alias
+		"@startup, 0 6 * * *",      // valid: @startup combined with a cron pattern
+		"0 6 * * *, @never",        // valid: @never combined with a cron pattern
+		"@fortnightly",             // invalid: unknown alias
+		"0 0 * *",                  // invalid: missing field
+	}
+
+	for _, v := range tests {
+		err := validate.IsCron([]string{"@daily", "@weekly", "@startup", "@never"})(v)
+		fmt.Printf("%q, %t\n", v, err == nil)
+	}
+
+	// Output: "@daily", true
+	// "@daily,@weekly", true
+	// "@daily, @weekly", true
+	// "0 0 * * *", true
+	// "0 0,12 * * *, 30 6 * * *", true
+	// "@daily,0 0 * * *", false
+	// "0 0 * * *, @daily", false
+	// "@startup, 0 6 * * *", true
+	// "0 6 * * *, @never", true
+	// "@fortnightly", false
+	// "0 0 * *", false
+}